@@ -259,8 +259,17 @@ type Client struct {
 	//
 	// Array of URLs supplied by the RP to which it MAY request that the End-User's User Agent be redirected using the
 	// post_logout_redirect_uri parameter after a logout has been performed.
+	//
+	// An entry may contain `*` wildcards in the host name (e.g. `https://*.example.com/logout`) and in the path
+	// (e.g. `https://example.com/logout/*`). A wildcard matches a single host label or path segment.
 	PostLogoutRedirectURIs sqlxx.StringSliceJSONFormat `json:"post_logout_redirect_uris,omitempty" db:"post_logout_redirect_uris"`
 
+	// Pre-Logout Hook URL
+	//
+	// If set, Hydra sends a POST request to this URL before a logout involving this client is completed.
+	// Responding with HTTP 403 aborts the logout.
+	PreLogoutHookURL string `json:"pre_logout_hook_url,omitempty" db:"pre_logout_hook_url" faker:"-"`
+
 	// OpenID Connect Back-Channel Logout URI
 	//
 	// RP URL that will cause the RP to log itself out when sent a Logout Token by the OP.
@@ -273,6 +282,14 @@ type Client struct {
 	// If omitted, the default value is false.
 	BackChannelLogoutSessionRequired bool `json:"backchannel_logout_session_required,omitempty" db:"backchannel_logout_session_required"`
 
+	// OpenID Connect Back-Channel Logout Subject Wide
+	//
+	// Boolean value specifying whether the RP wants to receive subject-wide Logout Tokens. If true, the Logout
+	// Token contains the `sub` Claim but no `sid` Claim, and the RP receives a single Logout Token even if several
+	// sessions of the subject are logged out at once. Can not be combined with backchannel_logout_session_required.
+	// If omitted, the default value is false.
+	BackChannelLogoutSubjectWide bool `json:"backchannel_logout_subject_wide,omitempty" db:"backchannel_logout_subject_wide" faker:"-"`
+
 	// OAuth 2.0 Client Metadata
 	//
 	// Use this field to story arbitrary data about the OAuth 2.0 Client. Can not be modified using OpenID Connect Dynamic Client Registration protocol.
@@ -305,6 +322,28 @@ type Client struct {
 	// be set from the admin API.
 	SkipConsent bool `json:"skip_consent" db:"skip_consent" faker:"-"`
 
+	// Required Authentication Method References
+	//
+	// RequiredAMR lists the authentication method references (e.g. `mfa`) which must all be
+	// present in the `amr` of an accepted login request before Hydra continues with the
+	// consent flow for this client. What happens if they are missing is controlled
+	// by `oauth2.amr_policy.on_violation`.
+	RequiredAMR sqlxx.StringSliceJSONFormat `json:"required_amr,omitempty" db:"required_amr" faker:"-"`
+
+	// Required Authentication Context Class References
+	//
+	// RequiredACR lists the authentication context class references (e.g. `urn:mace:incommon:iap:silver`)
+	// of which one must be the `acr` of an accepted login request before Hydra continues with the
+	// consent flow for this client. What happens otherwise is controlled by `oauth2.amr_policy.on_violation`.
+	RequiredACR sqlxx.StringSliceJSONFormat `json:"required_acr,omitempty" db:"required_acr" faker:"-"`
+
+	// Supported Authentication Methods
+	//
+	// SupportedAuthenticationMethods lists the authentication methods (e.g. `passkey`, `webauthn`,
+	// `otp`) which this client's users are expected to have available. They are passed to the login
+	// app in the login request's `oidc_context` so that it can pre-select a suitable authenticator.
+	SupportedAuthenticationMethods sqlxx.StringSliceJSONFormat `json:"supported_authentication_methods,omitempty" db:"supported_authentication_methods" faker:"-"`
+
 	Lifespans
 }
 
@@ -793,6 +793,20 @@ func (h *Handler) deleteOidcDynamicClient(w http.ResponseWriter, r *http.Request
 }
 
 func (h *Handler) ValidDynamicAuth(r *http.Request, ps httprouter.Params) (fosite.Client, error) {
+	c, err := h.validDynamicAuth(r, ps)
+	if err != nil {
+		h.r.SecurityEvents().Emit(r, x.SecurityEvent{
+			Type:     x.SecurityEventRegistrationAuthFailed,
+			Severity: x.SecuritySeverityMedium,
+			ClientID: ps.ByName("id"),
+			Reason:   "The registration access token is invalid.",
+		})
+		return nil, err
+	}
+	return c, nil
+}
+
+func (h *Handler) validDynamicAuth(r *http.Request, ps httprouter.Params) (fosite.Client, error) {
 	c, err := h.r.ClientManager().GetConcreteClient(r.Context(), ps.ByName("id"))
 	if err != nil {
 		return nil, herodot.ErrUnauthorized.
@@ -14,6 +14,7 @@ import (
 
 type InternalRegistry interface {
 	x.RegistryWriter
+	x.SecurityEventsProvider
 	Registry
 }
 
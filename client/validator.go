@@ -69,6 +69,7 @@ func (v *Validator) Validate(ctx context.Context, c *Client) error {
 		values := map[string]string{
 			"jwks_uri":               c.JSONWebKeysURI,
 			"backchannel_logout_uri": c.BackChannelLogoutURI,
+			"pre_logout_hook_url":    c.PreLogoutHookURL,
 		}
 
 		for k, v := range c.RequestURIs {
@@ -158,9 +159,15 @@ func (v *Validator) Validate(ctx context.Context, c *Client) error {
 			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to parse post_logout_redirect_uri: %s", l))
 		}
 
+		if !validPostLogoutRedirectURIPattern(u) {
+			return errorsx.WithStack(ErrInvalidClientMetadata.
+				WithHintf(`post_logout_redirect_uri "%s" may only use "*" as the leftmost label of a host name with at least two literal labels, or as a whole path segment.`, l),
+			)
+		}
+
 		var found bool
 		for _, r := range redirs {
-			if r.Hostname() == u.Hostname() &&
+			if hostMatches(u.Hostname(), r.Hostname()) &&
 				r.Port() == u.Port() &&
 				r.Scheme == u.Scheme {
 				found = true
@@ -174,6 +181,16 @@ func (v *Validator) Validate(ctx context.Context, c *Client) error {
 		}
 	}
 
+	if c.BackChannelLogoutSubjectWide && c.BackChannelLogoutSessionRequired {
+		return errorsx.WithStack(ErrInvalidClientMetadata.WithHint("Fields backchannel_logout_subject_wide and backchannel_logout_session_required can not both be set."))
+	}
+
+	if c.PreLogoutHookURL != "" {
+		if _, err := url.ParseRequestURI(c.PreLogoutHookURL); err != nil {
+			return errorsx.WithStack(ErrInvalidClientMetadata.WithHintf("Unable to parse pre_logout_hook_url: %s", c.PreLogoutHookURL))
+		}
+	}
+
 	if c.AccessTokenStrategy != "" {
 		s, err := config.ToAccessTokenStrategyType(c.AccessTokenStrategy)
 		if err != nil {
@@ -245,3 +262,95 @@ func isSupportedAuthTokenSigningAlg(alg string) bool {
 	}
 	return false
 }
+
+// MatchPostLogoutRedirectURI reports whether the requested post_logout_redirect_uri is
+// allowed by pattern, one of the client's registered post_logout_redirect_uris.
+//
+// Patterns without wildcards must match exactly. Otherwise the scheme, port and
+// query must be equal while a leading `*.` in the host name matches a single host
+// label and a `*` path segment matches a single path segment.
+func MatchPostLogoutRedirectURI(pattern, requested string) bool {
+	if pattern == requested {
+		return true
+	} else if !strings.Contains(pattern, "*") {
+		return false
+	}
+
+	p, err := url.Parse(pattern)
+	if err != nil {
+		return false
+	}
+
+	r, err := url.Parse(requested)
+	if err != nil {
+		return false
+	}
+
+	if p.Scheme != r.Scheme || p.Port() != r.Port() || p.RawQuery != r.RawQuery || r.User != nil || r.Fragment != "" {
+		return false
+	}
+
+	return hostMatches(p.Hostname(), r.Hostname()) && pathMatches(p.Path, r.Path)
+}
+
+// hostMatches reports whether host matches pattern. A pattern starting with `*.`
+// matches any host which has exactly one additional, non-empty label in front of
+// the rest of the pattern. All other patterns must match exactly.
+func hostMatches(pattern, host string) bool {
+	suffix := strings.TrimPrefix(pattern, "*.")
+	if suffix == pattern {
+		return pattern == host
+	}
+
+	label, rest, found := strings.Cut(host, ".")
+	return found && label != "" && rest == suffix
+}
+
+// pathMatches reports whether path matches pattern segment by segment. A pattern
+// segment consisting of `*` matches any single, non-empty segment.
+func pathMatches(pattern, path string) bool {
+	ps, rs := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(ps) != len(rs) {
+		return false
+	}
+
+	for k := range ps {
+		if ps[k] == "*" {
+			if rs[k] == "" {
+				return false
+			}
+		} else if ps[k] != rs[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// validPostLogoutRedirectURIPattern reports whether the wildcards of a registered
+// post_logout_redirect_uri are limited to what MatchPostLogoutRedirectURI supports:
+// a leading `*.` label followed by at least two literal host labels, and whole `*`
+// path segments.
+func validPostLogoutRedirectURIPattern(u *url.URL) bool {
+	if strings.Contains(u.Scheme+u.Port()+u.RawQuery, "*") {
+		return false
+	}
+
+	if host := u.Hostname(); strings.Contains(host, "*") {
+		suffix := strings.TrimPrefix(host, "*.")
+		if suffix == host || strings.Contains(suffix, "*") || !strings.Contains(suffix, ".") {
+			return false
+		}
+		for _, label := range strings.Split(suffix, ".") {
+			if label == "" {
+				return false
+			}
+		}
+	}
+
+	for _, segment := range strings.Split(u.Path, "/") {
+		if segment != "*" && strings.Contains(segment, "*") {
+			return false
+		}
+	}
+	return true
+}
@@ -98,6 +98,40 @@ func TestValidate(t *testing.T) {
 				assert.Equal(t, []string{"https://foo/"}, []string(c.PostLogoutRedirectURIs))
 			},
 		},
+		{
+			in: &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://*.example.com/logout/*"}, RedirectURIs: []string{"https://app.example.com/cb"}},
+			check: func(t *testing.T, c *Client) {
+				assert.Equal(t, []string{"https://*.example.com/logout/*"}, []string(c.PostLogoutRedirectURIs))
+			},
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://*.example.org/"}, RedirectURIs: []string{"https://app.example.com/cb"}},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://*/"}, RedirectURIs: []string{"https://app/cb"}},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://*.com/"}, RedirectURIs: []string{"https://example.com/cb"}},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://app*.example.com/"}, RedirectURIs: []string{"https://app1.example.com/cb"}},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PostLogoutRedirectURIs: []string{"https://app.example.com/logout*"}, RedirectURIs: []string{"https://app.example.com/cb"}},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", PreLogoutHookURL: "not a url"},
+			expectErr: true,
+		},
+		{
+			in:        &Client{LegacyClientID: "foo", BackChannelLogoutSubjectWide: true, BackChannelLogoutSessionRequired: true},
+			expectErr: true,
+		},
 		{
 			in: &Client{LegacyClientID: "foo"},
 			check: func(t *testing.T, c *Client) {
@@ -205,6 +239,36 @@ func TestValidateSectorIdentifierURL(t *testing.T) {
 	}
 }
 
+func TestMatchPostLogoutRedirectURI(t *testing.T) {
+	for k, tc := range []struct {
+		pattern   string
+		requested string
+		expected  bool
+	}{
+		{pattern: "https://foo/logout", requested: "https://foo/logout", expected: true},
+		{pattern: "https://foo/logout", requested: "https://foo/logout/", expected: false},
+		{pattern: "https://*.example.com/logout", requested: "https://app.example.com/logout", expected: true},
+		{pattern: "https://*.example.com/logout", requested: "https://a.b.example.com/logout", expected: false},
+		{pattern: "https://*.example.com/logout", requested: "https://example.com/logout", expected: false},
+		{pattern: "https://*.example.com/logout", requested: "http://app.example.com/logout", expected: false},
+		{pattern: "https://*.example.com:8443/logout", requested: "https://app.example.com/logout", expected: false},
+		{pattern: "https://example.com/logout/*", requested: "https://example.com/logout/done", expected: true},
+		{pattern: "https://example.com/logout/*", requested: "https://example.com/logout/done/again", expected: false},
+		{pattern: "https://example.com/logout/*", requested: "https://example.com/logout/done?foo=bar", expected: false},
+		{pattern: "https://example.com/logout/*", requested: "https://user@example.com/logout/done", expected: false},
+		{pattern: "https://example.com/logout/*", requested: "https://example.com/logout/", expected: false},
+		{pattern: "https://*/logout", requested: "https://evil/logout", expected: false},
+		{pattern: "https://*.example.com/log?ut", requested: "https://app.example.com/logout", expected: false},
+		{pattern: "https://*.example.com/[a-z]ogout", requested: "https://app.example.com/logout", expected: false},
+		{pattern: "https://*.example.com/logout*", requested: "https://app.example.com/logout-done", expected: false},
+		{pattern: "https://ap?.example.com/*", requested: "https://app.example.com/logout", expected: false},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expected, MatchPostLogoutRedirectURI(tc.pattern, tc.requested))
+		})
+	}
+}
+
 func TestValidateIPRanges(t *testing.T) {
 	ctx := context.Background()
 	c := internal.NewConfigurationWithDefaults()
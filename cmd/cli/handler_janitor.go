@@ -14,6 +14,7 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/ory/x/cmdx"
 	"github.com/ory/x/flagx"
 
 	"github.com/spf13/cobra"
@@ -154,7 +155,11 @@ func purge(cmd *cobra.Command, args []string, sl *servicelocatorx.Options, dOpts
 		routineFlags = append(routineFlags, OnlyGrants)
 	}
 
-	return cleanupRun(cmd.Context(), notAfter, limit, batchSize, addRoutine(p, routineFlags...)...)
+	results, err := cleanupRun(cmd.Context(), notAfter, limit, batchSize, addRoutine(p, routineFlags...)...)
+	if len(results) > 0 {
+		cmdx.PrintTable(cmd, results)
+	}
+	return err
 }
 
 func addRoutine(p persistence.Persister, names ...string) []cleanupRoutine {
@@ -162,38 +167,80 @@ func addRoutine(p persistence.Persister, names ...string) []cleanupRoutine {
 	for _, n := range names {
 		switch n {
 		case OnlyTokens:
-			routines = append(routines, cleanup(p.FlushInactiveAccessTokens, "access tokens"))
-			routines = append(routines, cleanup(p.FlushInactiveRefreshTokens, "refresh tokens"))
+			routines = append(routines, cleanupRoutine{name: "access tokens", run: p.FlushInactiveAccessTokens})
+			routines = append(routines, cleanupRoutine{name: "refresh tokens", run: p.FlushInactiveRefreshTokens})
 		case OnlyRequests:
-			routines = append(routines, cleanup(p.FlushInactiveLoginConsentRequests, "login-consent requests"))
+			routines = append(routines, cleanupRoutine{name: "login-consent requests", run: p.FlushInactiveLoginConsentRequests})
 		case OnlyGrants:
-			routines = append(routines, cleanup(p.FlushInactiveGrants, "grants"))
+			routines = append(routines, cleanupRoutine{name: "grants", run: p.FlushInactiveGrants})
 		}
 	}
 	return routines
 }
 
-type cleanupRoutine func(ctx context.Context, notAfter time.Time, limit int, batchSize int) error
+type cleanupRoutine struct {
+	name string
+	run  func(ctx context.Context, notAfter time.Time, limit int, batchSize int) error
+}
 
-func cleanup(cr cleanupRoutine, routineName string) cleanupRoutine {
-	return func(ctx context.Context, notAfter time.Time, limit int, batchSize int) error {
-		if err := cr(ctx, notAfter, limit, batchSize); err != nil {
-			return errors.Wrap(errorsx.WithStack(err), fmt.Sprintf("Could not cleanup inactive %s", routineName))
-		}
-		fmt.Printf("Successfully completed Janitor run on %s\n", routineName)
-		return nil
+const (
+	janitorStatusCompleted = "completed"
+	janitorStatusFailed    = "failed"
+)
+
+type (
+	janitorResult struct {
+		Routine string `json:"routine"`
+		Status  string `json:"status"`
+		Error   string `json:"error,omitempty"`
+	}
+	janitorResultCollection []janitorResult
+)
+
+var _ cmdx.Table = janitorResultCollection(nil)
+
+func (c janitorResultCollection) Header() []string {
+	return []string{"ROUTINE", "STATUS", "ERROR"}
+}
+
+func (c janitorResultCollection) Table() [][]string {
+	rows := make([][]string, len(c))
+	for k, r := range c {
+		rows[k] = []string{r.Routine, r.Status, r.Error}
+	}
+	return rows
+}
+
+func (c janitorResultCollection) Interface() interface{} {
+	return c
+}
+
+func (c janitorResultCollection) Len() int {
+	return len(c)
+}
+
+func (c janitorResultCollection) IDs() []string {
+	ids := make([]string, len(c))
+	for k, r := range c {
+		ids[k] = r.Routine
 	}
+	return ids
 }
 
-func cleanupRun(ctx context.Context, notAfter time.Time, limit int, batchSize int, routines ...cleanupRoutine) error {
+// cleanupRun executes the routines in order and stops at the first one that fails. The returned
+// results contain every routine that was executed, including the failed one.
+func cleanupRun(ctx context.Context, notAfter time.Time, limit int, batchSize int, routines ...cleanupRoutine) (janitorResultCollection, error) {
 	if len(routines) == 0 {
-		return errors.New("clean up run received 0 routines")
+		return nil, errors.New("clean up run received 0 routines")
 	}
 
+	results := make(janitorResultCollection, 0, len(routines))
 	for _, r := range routines {
-		if err := r(ctx, notAfter, limit, batchSize); err != nil {
-			return err
+		if err := r.run(ctx, notAfter, limit, batchSize); err != nil {
+			results = append(results, janitorResult{Routine: r.name, Status: janitorStatusFailed, Error: err.Error()})
+			return results, errors.Wrap(errorsx.WithStack(err), fmt.Sprintf("Could not cleanup inactive %s", r.name))
 		}
+		results = append(results, janitorResult{Routine: r.name, Status: janitorStatusCompleted})
 	}
-	return nil
+	return results, nil
 }
@@ -6,6 +6,7 @@ package cli_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ory/hydra/v2/cmd/cli"
@@ -297,3 +299,15 @@ func TestJanitorHandler_PurgeGrantNotAfter(t *testing.T) {
 		})
 	}
 }
+
+func TestJanitorHandler_Format(t *testing.T) {
+	out := cmdx.ExecNoErr(t, cmd.NewRootCmd(nil, nil, nil),
+		"janitor",
+		fmt.Sprintf("--%s", cli.OnlyTokens),
+		fmt.Sprintf("--%s", cli.OnlyGrants),
+		"--format", "json",
+		"memory",
+	)
+
+	assert.Equal(t, `[{"routine":"access tokens","status":"completed"},{"routine":"refresh tokens","status":"completed"},{"routine":"grants","status":"completed"}]`, strings.TrimSpace(out))
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/ory/hydra/v2/driver"
 	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/hydra/v2/persistence"
 	"github.com/ory/x/flagx"
 )
 
@@ -259,7 +260,9 @@ func (h *MigrateHandler) MigrateGen(cmd *cobra.Command, args []string) {
 	os.Exit(0)
 }
 
-func (h *MigrateHandler) MigrateSQL(cmd *cobra.Command, args []string) (err error) {
+// openPersister creates the SQL persister for the database URL given as argument or, with flag -e,
+// read from the environment and opens its connection.
+func (h *MigrateHandler) openPersister(cmd *cobra.Command, args []string) (p persistence.Persister, err error) {
 	var d driver.Registry
 
 	if flagx.MustGetBool(cmd, "read-from-env") {
@@ -275,16 +278,16 @@ func (h *MigrateHandler) MigrateSQL(cmd *cobra.Command, args []string) (err erro
 				driver.SkipNetworkInit(),
 			})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(d.Config().DSN()) == 0 {
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "When using flag -e, environment variable DSN must be set.")
-			return cmdx.FailSilently(cmd)
+			return nil, cmdx.FailSilently(cmd)
 		}
 	} else {
 		if len(args) != 1 {
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Please provide the database URL.")
-			return cmdx.FailSilently(cmd)
+			return nil, cmdx.FailSilently(cmd)
 		}
 		d, err = driver.New(
 			cmd.Context(),
@@ -300,20 +303,29 @@ func (h *MigrateHandler) MigrateSQL(cmd *cobra.Command, args []string) (err erro
 				driver.SkipNetworkInit(),
 			})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	p := d.Persister()
+	p = d.Persister()
 	conn := p.Connection(context.Background())
 	if conn == nil {
 		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Migrations can only be executed against a SQL-compatible driver but DSN is not a SQL source.")
-		return cmdx.FailSilently(cmd)
+		return nil, cmdx.FailSilently(cmd)
 	}
 
 	if err := conn.Open(); err != nil {
 		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not open the database connection:\n%+v\n", err)
-		return cmdx.FailSilently(cmd)
+		return nil, cmdx.FailSilently(cmd)
+	}
+
+	return p, nil
+}
+
+func (h *MigrateHandler) MigrateSQL(cmd *cobra.Command, args []string) error {
+	p, err := h.openPersister(cmd, args)
+	if err != nil {
+		return err
 	}
 
 	// convert migration tables
@@ -349,3 +361,79 @@ func (h *MigrateHandler) MigrateSQL(cmd *cobra.Command, args []string) (err erro
 	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Successfully applied migrations!")
 	return nil
 }
+
+type (
+	migrationStatus struct {
+		Version     string `json:"version"`
+		Description string `json:"description"`
+		Checksum    string `json:"checksum"`
+		State       string `json:"state"`
+	}
+	migrationStatusCollection []migrationStatus
+)
+
+var _ cmdx.Table = migrationStatusCollection(nil)
+
+func (c migrationStatusCollection) Header() []string {
+	return []string{"VERSION", "DESCRIPTION", "CHECKSUM", "STATE"}
+}
+
+func (c migrationStatusCollection) Table() [][]string {
+	rows := make([][]string, len(c))
+	for k, s := range c {
+		rows[k] = []string{s.Version, s.Description, s.Checksum, s.State}
+	}
+	return rows
+}
+
+func (c migrationStatusCollection) Interface() interface{} {
+	return c
+}
+
+func (c migrationStatusCollection) Len() int {
+	return len(c)
+}
+
+func (c migrationStatusCollection) IDs() []string {
+	ids := make([]string, len(c))
+	for k, s := range c {
+		ids[k] = s.Version
+	}
+	return ids
+}
+
+func (h *MigrateHandler) MigrateStatus(cmd *cobra.Command, args []string) error {
+	p, err := h.openPersister(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	status, err := p.MigrationStatus(cmd.Context())
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not get the migration status:\n%+v\n", errorsx.WithStack(err))
+		return cmdx.FailSilently(cmd)
+	}
+
+	checksums, err := p.MigrationChecksums(cmd.Context())
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not compute the migration checksums:\n%+v\n", err)
+		return cmdx.FailSilently(cmd)
+	}
+
+	collection := make(migrationStatusCollection, len(status))
+	for k, s := range status {
+		collection[k] = migrationStatus{
+			Version:     s.Version,
+			Description: s.Name,
+			Checksum:    checksums[s.Version],
+			State:       s.State,
+		}
+	}
+	cmdx.PrintTable(cmd, collection)
+
+	if flagx.MustGetBool(cmd, "fail-on-pending") && status.HasPending() {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "There are pending migrations.")
+		return cmdx.FailSilently(cmd)
+	}
+	return nil
+}
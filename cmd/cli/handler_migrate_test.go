@@ -0,0 +1,47 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cli_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+)
+
+func TestMigrateHandler_MigrateStatus(t *testing.T) {
+	dsn := "sqlite://file:" + filepath.Join(t.TempDir(), "db.sqlite") + "?_fk=true"
+
+	status := func(t *testing.T) gjson.Result {
+		out := gjson.Parse(cmdx.ExecNoErr(t, cmd.NewRootCmd(nil, nil, nil), "migrate", "status", "--format", "json", dsn))
+		require.NotEmpty(t, out.Array())
+		return out
+	}
+
+	t.Run("case=all migrations are pending on a fresh database", func(t *testing.T) {
+		for _, m := range status(t).Array() {
+			assert.Equal(t, "Pending", m.Get("state").String(), "%s", m.Raw)
+			assert.Len(t, m.Get("checksum").String(), 64, "%s", m.Raw)
+			assert.NotEmpty(t, m.Get("version").String(), "%s", m.Raw)
+		}
+
+		_, stderr, err := cmdx.Exec(t, cmd.NewRootCmd(nil, nil, nil), nil, "migrate", "status", "--fail-on-pending", dsn)
+		require.Error(t, err)
+		assert.Contains(t, stderr, "There are pending migrations.")
+	})
+
+	t.Run("case=all migrations are applied after migrating", func(t *testing.T) {
+		cmdx.ExecNoErr(t, cmd.NewRootCmd(nil, nil, nil), "migrate", "sql", "--yes", dsn)
+
+		for _, m := range status(t).Array() {
+			assert.Equal(t, "Applied", m.Get("state").String(), "%s", m.Raw)
+		}
+		cmdx.ExecNoErr(t, cmd.NewRootCmd(nil, nil, nil), "migrate", "status", "--fail-on-pending", dsn)
+	})
+}
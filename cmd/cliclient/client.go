@@ -4,11 +4,15 @@
 package cliclient
 
 import (
+	"context"
+	"net"
+	"net/http"
 	"net/url"
 
 	"github.com/pkg/errors"
 
 	"github.com/ory/x/cmdx"
+	"github.com/ory/x/httpx"
 
 	"github.com/spf13/cobra"
 
@@ -39,8 +43,46 @@ func NewClient(cmd *cobra.Command) (*hydra.APIClient, *url.URL, error) {
 		return nil, nil, errors.WithStack(err)
 	}
 
+	switch target.Scheme {
+	case "unix":
+		if target, err = dialUnixSocket(hc, target); err != nil {
+			return nil, nil, err
+		}
+	case "ziti":
+		// There is no Ziti SDK in this build, so Ziti services have to be reached through a tunneler.
+		return nil, nil, errors.Errorf(`connecting to the Ziti service "%s" directly is not supported: run a Ziti tunneler which exposes the service as a local address or unix socket and use that as the endpoint instead`, target.Host)
+	}
+
 	conf := hydra.NewConfiguration()
 	conf.HTTPClient = hc
 	conf.Servers = hydra.ServerConfigurations{{URL: target.String()}}
 	return hydra.NewAPIClient(conf), target, nil
 }
+
+// dialUnixSocket makes the client connect to the unix socket given as endpoint (e.g.
+// `unix:///var/run/hydra/admin.sock`) and returns the URL requests have to be sent to.
+func dialUnixSocket(hc *http.Client, endpoint *url.URL) (*url.URL, error) {
+	socket := endpoint.Path
+	if socket == "" {
+		socket = endpoint.Opaque
+	}
+	if socket == "" {
+		return nil, errors.Errorf(`the unix socket endpoint "%s" does not contain a path`, endpoint)
+	}
+
+	rt, ok := hc.Transport.(*httpx.TransportWithHeader)
+	if !ok {
+		return nil, errors.Errorf("unable to configure the HTTP client for unix sockets: unexpected transport %T", hc.Transport)
+	}
+	transport, ok := rt.RoundTripper.(*http.Transport)
+	if !ok {
+		return nil, errors.Errorf("unable to configure the HTTP client for unix sockets: unexpected transport %T", rt.RoundTripper)
+	}
+
+	transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", socket)
+	}
+
+	return &url.URL{Scheme: "http", Host: "localhost"}, nil
+}
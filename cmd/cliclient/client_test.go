@@ -0,0 +1,65 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cliclient_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+)
+
+func newCommand(t *testing.T, endpoint string) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmdx.RegisterHTTPClientFlags(cmd.Flags())
+	require.NoError(t, cmd.Flags().Set(cmdx.FlagEndpoint, endpoint))
+	return cmd
+}
+
+func TestNewClient(t *testing.T) {
+	t.Run("case=dials a unix socket", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "admin.sock")
+		l, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+
+		ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.URL.Path))
+		}))
+		ts.Listener = l
+		ts.Start()
+		t.Cleanup(ts.Close)
+
+		client, target, err := cliclient.NewClient(newCommand(t, "unix://"+socket))
+		require.NoError(t, err)
+
+		res, err := client.GetConfig().HTTPClient.Get(target.String() + "/admin/health/ready")
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "/admin/health/ready", string(body))
+	})
+
+	t.Run("case=rejects a unix socket without path", func(t *testing.T) {
+		_, _, err := cliclient.NewClient(newCommand(t, "unix://"))
+		assert.ErrorContains(t, err, "does not contain a path")
+	})
+
+	t.Run("case=rejects ziti services", func(t *testing.T) {
+		_, _, err := cliclient.NewClient(newCommand(t, "ziti://hydra-admin"))
+		assert.ErrorContains(t, err, "Ziti tunneler")
+	})
+}
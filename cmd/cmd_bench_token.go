@@ -0,0 +1,268 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/urlx"
+)
+
+const (
+	benchFlowClientCredentials = "client_credentials"
+	benchFlowRefreshToken      = "refresh_token"
+)
+
+// benchRecorder collects the latency of every token request made during a benchmark.
+type benchRecorder struct {
+	sync.Mutex
+	latencies []time.Duration
+	errors    int
+	lastError string
+}
+
+func (r *benchRecorder) record(latency time.Duration, err error) {
+	r.Lock()
+	defer r.Unlock()
+	r.latencies = append(r.latencies, latency)
+	if err != nil {
+		r.errors++
+		r.lastError = err.Error()
+	}
+}
+
+func (r *benchRecorder) result(flow string, elapsed time.Duration) outputBenchResult {
+	r.Lock()
+	defer r.Unlock()
+
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	percentile := func(p float64) float64 {
+		if len(r.latencies) == 0 {
+			return 0
+		}
+		idx := int(p*float64(len(r.latencies))+0.5) - 1
+		if idx < 0 {
+			idx = 0
+		} else if idx >= len(r.latencies) {
+			idx = len(r.latencies) - 1
+		}
+		return float64(r.latencies[idx]) / float64(time.Millisecond)
+	}
+
+	res := outputBenchResult{
+		Flow:     flow,
+		Requests: len(r.latencies),
+		Errors:   r.errors,
+		P50:      percentile(0.5),
+		P90:      percentile(0.9),
+		P99:      percentile(0.99),
+		Max:      percentile(1),
+	}
+	if res.Requests > 0 {
+		res.ErrorRate = float64(res.Errors) / float64(res.Requests)
+	}
+	if elapsed > 0 {
+		res.Throughput = float64(res.Requests) / elapsed.Seconds()
+	}
+	return res
+}
+
+func NewBenchTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Args:  cobra.NoArgs,
+		Short: "Benchmark the OAuth 2.0 Token Endpoint",
+		Example: `{{ .CommandPath }} --endpoint https://hydra.example.com --client-id ... --client-secret ... --rate 200 --duration 1m
+{{ .CommandPath }} --flow refresh_token --refresh-token ... --refresh-token ... --client-id ... --client-secret ...`,
+		Long: `Sends token requests to the OAuth 2.0 Token Endpoint of the instance given by --endpoint, which is usually the public URL, and reports latency percentiles and the error rate.
+
+With --flow client_credentials (the default), --concurrency workers request access tokens with the OAuth 2.0 Client Credentials Grant.
+
+With --flow refresh_token, every refresh token given with --refresh-token starts a chain in which each response's refresh token is used for the next request. Because refresh tokens are single-use, there is one worker per refresh token and a chain stops at its first error.
+
+Use --rate to limit the total number of requests per second. A rate of 0 sends requests as fast as the workers allow.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, target, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			clientID := flagx.MustGetString(cmd, "client-id")
+			clientSecret := flagx.MustGetString(cmd, "client-secret")
+			if clientID == "" || clientSecret == "" {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Please provide a Client ID and Client Secret using flags --client-id and --client-secret, or environment variables OAUTH2_CLIENT_ID and OAUTH2_CLIENT_SECRET.")
+				return cmdx.FailSilently(cmd)
+			}
+
+			flow := flagx.MustGetString(cmd, "flow")
+			workers := flagx.MustGetInt(cmd, "concurrency")
+			refreshTokens := flagx.MustGetStringSlice(cmd, "refresh-token")
+			switch flow {
+			case benchFlowClientCredentials:
+			case benchFlowRefreshToken:
+				if len(refreshTokens) == 0 {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Please provide at least one refresh token using flag --refresh-token.")
+					return cmdx.FailSilently(cmd)
+				}
+				workers = len(refreshTokens)
+			default:
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Flag --flow must be one of %s and %s.\n", benchFlowClientCredentials, benchFlowRefreshToken)
+				return cmdx.FailSilently(cmd)
+			}
+			if workers < 1 {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Flag --concurrency must be at least 1.")
+				return cmdx.FailSilently(cmd)
+			}
+
+			b := &tokenBench{
+				hc:           client.GetConfig().HTTPClient,
+				tokenURL:     urlx.AppendPaths(target, "/oauth2/token").String(),
+				clientID:     clientID,
+				clientSecret: clientSecret,
+				scope:        strings.Join(flagx.MustGetStringSlice(cmd, "scope"), " "),
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), flagx.MustGetDuration(cmd, "duration"))
+			defer cancel()
+
+			tick := make(chan struct{})
+			go func() {
+				defer close(tick)
+				var ticker <-chan time.Time
+				if rate := flagx.MustGetInt(cmd, "rate"); rate > 0 {
+					t := time.NewTicker(time.Second / time.Duration(rate))
+					defer t.Stop()
+					ticker = t.C
+				}
+				for {
+					if ticker != nil {
+						select {
+						case <-ctx.Done():
+							return
+						case <-ticker:
+						}
+					}
+					select {
+					case <-ctx.Done():
+						return
+					case tick <- struct{}{}:
+					}
+				}
+			}()
+
+			rec := new(benchRecorder)
+			start := time.Now()
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					var refreshToken string
+					if flow == benchFlowRefreshToken {
+						refreshToken = refreshTokens[i]
+					}
+					for range tick {
+						next, latency, err := b.request(ctx, refreshToken)
+						if ctx.Err() != nil {
+							// Requests cancelled by the end of the benchmark are not counted.
+							return
+						}
+						rec.record(latency, err)
+						if flow == benchFlowRefreshToken {
+							if err != nil {
+								return
+							}
+							refreshToken = next
+						}
+					}
+				}(i)
+			}
+			wg.Wait()
+
+			res := rec.result(flow, time.Since(start))
+			if rec.lastError != "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%d of %d requests failed, the last error was: %s\n", res.Errors, res.Requests, rec.lastError)
+			}
+			cmdx.PrintRow(cmd, &res)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("client-id", os.Getenv("OAUTH2_CLIENT_ID"), "Use the provided OAuth 2.0 Client ID, defaults to environment variable OAUTH2_CLIENT_ID.")
+	cmd.Flags().String("client-secret", os.Getenv("OAUTH2_CLIENT_SECRET"), "Use the provided OAuth 2.0 Client Secret, defaults to environment variable OAUTH2_CLIENT_SECRET.")
+	cmd.Flags().StringSlice("scope", []string{}, "OAuth2 scope to request.")
+	cmd.Flags().String("flow", benchFlowClientCredentials, "The grant to benchmark, either client_credentials or refresh_token.")
+	cmd.Flags().StringSlice("refresh-token", []string{}, "A refresh token to start a refresh_token chain with. Can be repeated.")
+	cmd.Flags().Int("rate", 0, "The maximum total number of requests per second. 0 means unlimited.")
+	cmd.Flags().Int("concurrency", 10, "The number of concurrent workers for the client_credentials flow.")
+	cmd.Flags().Duration("duration", 10*time.Second, "How long to run the benchmark.")
+
+	return cmd
+}
+
+type tokenBench struct {
+	hc           *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+}
+
+// request performs a single token request. If refreshToken is set, it is exchanged and the new
+// refresh token is returned.
+func (b *tokenBench) request(ctx context.Context, refreshToken string) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {benchFlowClientCredentials}}
+	if refreshToken != "" {
+		form = url.Values{"grant_type": {benchFlowRefreshToken}, "refresh_token": {refreshToken}}
+	}
+	if b.scope != "" {
+		form.Set("scope", b.scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(b.clientID), url.QueryEscape(b.clientSecret))
+
+	start := time.Now()
+	res, err := b.hc.Do(req)
+	if err != nil {
+		return "", time.Since(start), err
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		RefreshToken     string `json:"refresh_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	payload, err := io.ReadAll(res.Body)
+	latency := time.Since(start)
+	if err != nil {
+		return "", latency, err
+	}
+	_ = json.Unmarshal(payload, &body)
+
+	if res.StatusCode != http.StatusOK {
+		return "", latency, fmt.Errorf("received status code %d: %s %s", res.StatusCode, body.Error, body.ErrorDescription)
+	}
+	return body.RefreshToken, latency, nil
+}
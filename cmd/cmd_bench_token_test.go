@@ -0,0 +1,52 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+)
+
+func TestBenchToken(t *testing.T) {
+	c := cmd.NewBenchTokenCmd()
+	public, _, reg := setupRoutes(t, c)
+	require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, public.URL))
+
+	expected := createClientCredentialsClient(t, reg)
+
+	t.Run("case=benchmarks the client credentials flow", func(t *testing.T) {
+		actual := gjson.Parse(cmdx.ExecNoErr(t, c,
+			"--client-id", expected.GetID(), "--client-secret", expected.Secret,
+			"--duration", "1s", "--rate", "20", "--concurrency", "2"))
+		assert.Equal(t, "client_credentials", actual.Get("flow").String(), "%s", actual.Raw)
+		assert.Greater(t, actual.Get("requests").Int(), int64(0), "%s", actual.Raw)
+		assert.Equal(t, int64(0), actual.Get("errors").Int(), "%s", actual.Raw)
+		assert.Greater(t, actual.Get("p50_ms").Float(), float64(0), "%s", actual.Raw)
+	})
+
+	t.Run("case=reports errors", func(t *testing.T) {
+		stdout, stderr, err := cmdx.Exec(t, c, nil,
+			"--client-id", expected.GetID(), "--client-secret", "wrong",
+			"--duration", "1s", "--rate", "10", "--concurrency", "1")
+		require.NoError(t, err, stderr)
+		actual := gjson.Parse(stdout)
+		assert.Equal(t, actual.Get("requests").Int(), actual.Get("errors").Int(), "%s", actual.Raw)
+		assert.Equal(t, float64(1), actual.Get("error_rate").Float(), "%s", actual.Raw)
+		assert.Contains(t, stderr, "401")
+	})
+
+	t.Run("case=requires refresh tokens for the refresh token flow", func(t *testing.T) {
+		_, stderr, err := cmdx.Exec(t, c, nil,
+			"--client-id", expected.GetID(), "--client-secret", expected.Secret,
+			"--flow", "refresh_token", "--duration", "1s")
+		require.Error(t, err)
+		assert.Contains(t, stderr, "--refresh-token")
+	})
+}
@@ -0,0 +1,151 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	hydra "github.com/ory/hydra-client-go/v2"
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/pointerx"
+)
+
+const (
+	flagSampleAppPort        = "port"
+	flagSampleAppRedirectURI = "redirect-uri"
+	flagSampleAppPublicURL   = "public-url"
+	flagSampleAppModule      = "module"
+
+	// sampleAppClientVersion is the version of the Ory Hydra Go SDK the sample app depends on.
+	sampleAppClientVersion = "v2.0.3"
+)
+
+//go:embed sample_app/*.tmpl
+var sampleAppTemplates embed.FS
+
+type sampleAppValues struct {
+	Module        string
+	ClientVersion string
+	AdminURL      string
+	PublicURL     string
+	Port          int
+	ClientID      string
+	RedirectURL   string
+}
+
+func NewCreateSampleAppCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sample-app <directory>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Generate a minimal login and consent app for this Ory Hydra instance",
+		Example: `{{ .CommandPath }} ./login-consent --endpoint http://localhost:4445
+{{ .CommandPath }} ./login-consent --port 3000 --redirect-uri http://127.0.0.1:4446/callback`,
+		Long: `This command writes a minimal, runnable Go application implementing the login and consent screens to the
+given directory and registers an OAuth 2.0 Client which can be used to try it out with "hydra perform authorization-code".
+
+The generated README explains how to point Ory Hydra to the app. The registered OAuth 2.0 Client, including its
+secret, is printed once and not written to the directory.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := args[0]
+			if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Directory %s is not empty, please choose another one.\n", dir)
+				return cmdx.FailSilently(cmd)
+			}
+
+			m, endpoint, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			redirectURL := flagx.MustGetString(cmd, flagSampleAppRedirectURI)
+			client, _, err := m.OAuth2Api.CreateOAuth2Client(cmd.Context()).OAuth2Client(hydra.OAuth2Client{
+				ClientName:    pointerx.String("Sample App"),
+				GrantTypes:    []string{"authorization_code", "refresh_token"},
+				ResponseTypes: []string{"code", "id_token"},
+				Scope:         pointerx.String("openid offline"),
+				RedirectUris:  []string{redirectURL},
+			}).Execute() //nolint:bodyclose
+			if err != nil {
+				return cmdx.PrintOpenAPIError(cmd, err)
+			}
+
+			module := flagx.MustGetString(cmd, flagSampleAppModule)
+			if module == "" {
+				module = strings.ReplaceAll(filepath.Base(filepath.Clean(dir)), " ", "-")
+			}
+
+			values := sampleAppValues{
+				Module:        module,
+				ClientVersion: sampleAppClientVersion,
+				AdminURL:      endpoint.String(),
+				PublicURL:     flagx.MustGetString(cmd, flagSampleAppPublicURL),
+				Port:          flagx.MustGetInt(cmd, flagSampleAppPort),
+				ClientID:      pointerx.StringR(client.ClientId),
+				RedirectURL:   redirectURL,
+			}
+			if err := writeSampleApp(dir, values); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not write the sample app: %s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), `The sample app was written to %s. To try it out:
+
+  1. Set urls.login to http://127.0.0.1:%[2]d/login and urls.consent to http://127.0.0.1:%[2]d/consent in the Ory Hydra configuration.
+  2. Run "go mod tidy && go run ." in %[1]s.
+  3. Run "hydra perform authorization-code --endpoint %[3]s --client-id %[4]s --client-secret <secret>".
+
+`, dir, values.Port, values.PublicURL, values.ClientID)
+
+			cmdx.PrintRow(cmd, (*outputOAuth2Client)(client))
+			return nil
+		},
+	}
+	cmd.Flags().Int(flagSampleAppPort, 3000, "The port the sample app listens on.")
+	cmd.Flags().String(flagSampleAppRedirectURI, "http://127.0.0.1:4446/callback", "The redirect URL of the registered OAuth 2.0 Client. The default matches \"hydra perform authorization-code\".")
+	cmd.Flags().String(flagSampleAppPublicURL, "http://127.0.0.1:4444", "The public URL of Ory Hydra, used in the instructions.")
+	cmd.Flags().String(flagSampleAppModule, "", "The Go module path of the sample app. Defaults to the name of the directory.")
+	return cmd
+}
+
+// writeSampleApp renders the sample app templates into dir.
+func writeSampleApp(dir string, values sampleAppValues) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	return fs.WalkDir(sampleAppTemplates, "sample_app", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		contents, err := fs.ReadFile(sampleAppTemplates, name)
+		if err != nil {
+			return err
+		}
+
+		t, err := template.New(name).Delims("[[", "]]").Parse(string(contents))
+		if err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(filepath.Join(dir, strings.TrimSuffix(path.Base(name), ".tmpl")), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		return t.Execute(f, values)
+	})
+}
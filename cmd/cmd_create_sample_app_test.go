@@ -0,0 +1,57 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+)
+
+func TestCreateSampleApp(t *testing.T) {
+	ctx := context.Background()
+	c := cmd.NewCreateSampleAppCmd()
+	reg := setup(t, c)
+
+	t.Run("case=writes the app and registers a client", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "login-consent")
+		actual := gjson.Parse(cmdx.ExecNoErr(t, c, dir, "--port", "3001"))
+
+		expected, err := reg.ClientManager().GetConcreteClient(ctx, actual.Get("client_id").String())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"http://127.0.0.1:4446/callback"}, []string(expected.RedirectURIs))
+		assert.NotEmpty(t, actual.Get("client_secret").String())
+
+		_, err = parser.ParseFile(token.NewFileSet(), filepath.Join(dir, "main.go"), nil, parser.AllErrors)
+		require.NoError(t, err)
+
+		mod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+		require.NoError(t, err)
+		assert.Contains(t, string(mod), "module login-consent")
+
+		readme, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(readme), "http://127.0.0.1:3001/login")
+		assert.Contains(t, string(readme), expected.GetID())
+		assert.NotContains(t, string(readme), actual.Get("client_secret").String())
+	})
+
+	t.Run("case=refuses to write to a directory which is not empty", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main"), 0600))
+
+		stderr := cmdx.ExecExpectedErr(t, c, dir)
+		assert.Contains(t, stderr, "is not empty")
+	})
+}
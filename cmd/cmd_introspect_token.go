@@ -15,10 +15,11 @@ import (
 
 func NewIntrospectTokenCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:     "token the-token",
-		Args:    cobra.ExactArgs(1),
-		Example: `{{ .CommandPath }} AYjcyMzY3ZDhiNmJkNTY --project 32197be3-8e57-4009-becd-9d38dbde129c`,
-		Short:   "Introspect an OAuth 2.0 Access or Refresh Token",
+		Use:  "token the-token",
+		Args: cobra.ExactArgs(1),
+		Example: `{{ .CommandPath }} AYjcyMzY3ZDhiNmJkNTY --project 32197be3-8e57-4009-becd-9d38dbde129c
+{{ .CommandPath }} AYjcyMzY3ZDhiNmJkNTY --endpoint unix:///var/run/hydra/admin.sock`,
+		Short: "Introspect an OAuth 2.0 Access or Refresh Token",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, _, err := cliclient.NewClient(cmd)
 			if err != nil {
@@ -0,0 +1,127 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+)
+
+const (
+	flagRevokeSubject = "subject"
+	flagRevokeClient  = "client"
+	flagRevokeYes     = "yes"
+
+	revokedLoginSessions   = "login sessions"
+	revokedConsentSessions = "consent sessions"
+	revokedAccessTokens    = "access tokens"
+)
+
+func NewRevokeSessionsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sessions",
+		Aliases: []string{"session"},
+		Args:    cobra.NoArgs,
+		Short:   "Revoke the login and consent sessions and tokens of a subject or OAuth 2.0 Client",
+		Example: `{{ .CommandPath }} --subject foo@bar.com
+{{ .CommandPath }} --subject foo@bar.com --client a0184d6c-b313-4e70-a0b9-905b581e9218 --yes`,
+		Long: `This command revokes everything Ory Hydra knows about a subject or an OAuth 2.0 Client in one go:
+
+- With --subject, the login sessions and all consent sessions of the subject are revoked. Revoking the consent sessions also revokes the tokens issued to the subject.
+- With --subject and --client, only the consent sessions and tokens the subject granted to the OAuth 2.0 Client are revoked. The login sessions are kept so that the subject stays logged in for other clients.
+- With --client, all access tokens issued to the OAuth 2.0 Client are revoked.
+
+The command asks for confirmation before revoking anything. Use --yes to skip the question, for example in scripts.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			subject, clientID := flagx.MustGetString(cmd, flagRevokeSubject), flagx.MustGetString(cmd, flagRevokeClient)
+			if subject == "" && clientID == "" {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n\nPlease provide at least one of --subject and --client.\n", cmd.UsageString())
+				return cmdx.FailSilently(cmd)
+			}
+
+			plan := planSessionRevocation(subject, clientID)
+			if !flagx.MustGetBool(cmd, flagRevokeYes) {
+				types := make([]string, len(plan))
+				for i, s := range plan {
+					types[i] = s.Type
+				}
+
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "To skip the next question use flag --yes (at your own risk).")
+				if !cmdx.AskForConfirmation(fmt.Sprintf("Do you wish to revoke the %s of %s?", strings.Join(types, " and "), describeRevocationTarget(subject, clientID)), cmd.InOrStdin(), cmd.ErrOrStderr()) {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Revocation aborted.")
+					return nil
+				}
+			}
+
+			m, _, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			revoked := make([]outputRevokedSession, 0, len(plan))
+			for _, s := range plan {
+				switch s.Type {
+				case revokedLoginSessions:
+					_, err = m.OAuth2Api.RevokeOAuth2LoginSessions(cmd.Context()).Subject(s.Subject).Execute() //nolint:bodyclose
+				case revokedConsentSessions:
+					req := m.OAuth2Api.RevokeOAuth2ConsentSessions(cmd.Context()).Subject(s.Subject)
+					if s.Client != "" {
+						req = req.Client(s.Client)
+					} else {
+						req = req.All(true)
+					}
+					_, err = req.Execute() //nolint:bodyclose
+				case revokedAccessTokens:
+					_, err = m.OAuth2Api.DeleteOAuth2Token(cmd.Context()).ClientId(s.Client).Execute() //nolint:bodyclose
+				}
+				if err != nil {
+					if len(revoked) > 0 {
+						cmdx.PrintTable(cmd, &outputRevokedSessionCollection{sessions: revoked})
+					}
+					return cmdx.PrintOpenAPIError(cmd, err)
+				}
+				revoked = append(revoked, s)
+			}
+
+			cmdx.PrintTable(cmd, &outputRevokedSessionCollection{sessions: revoked})
+			return nil
+		},
+	}
+	cmd.Flags().String(flagRevokeSubject, "", "Revoke the sessions and tokens of this subject.")
+	cmd.Flags().String(flagRevokeClient, "", "Revoke the sessions and tokens of this OAuth 2.0 Client ID.")
+	cmd.Flags().BoolP(flagRevokeYes, "y", false, "If set all confirmation requests are accepted without user interaction.")
+	return cmd
+}
+
+// planSessionRevocation returns what has to be revoked for the given subject and client, in order.
+func planSessionRevocation(subject, clientID string) []outputRevokedSession {
+	switch {
+	case subject != "" && clientID != "":
+		return []outputRevokedSession{{Type: revokedConsentSessions, Subject: subject, Client: clientID}}
+	case subject != "":
+		return []outputRevokedSession{
+			{Type: revokedLoginSessions, Subject: subject},
+			{Type: revokedConsentSessions, Subject: subject},
+		}
+	default:
+		return []outputRevokedSession{{Type: revokedAccessTokens, Client: clientID}}
+	}
+}
+
+func describeRevocationTarget(subject, clientID string) string {
+	switch {
+	case subject != "" && clientID != "":
+		return fmt.Sprintf("subject %q for OAuth 2.0 Client %q", subject, clientID)
+	case subject != "":
+		return fmt.Sprintf("subject %q", subject)
+	default:
+		return fmt.Sprintf("OAuth 2.0 Client %q", clientID)
+	}
+}
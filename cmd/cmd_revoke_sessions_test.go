@@ -0,0 +1,81 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/hydra/v2/oauth2"
+	"github.com/ory/x/cmdx"
+)
+
+func TestRevokeSessions(t *testing.T) {
+	ctx := context.Background()
+	public, admin, reg := setupRoutes(t, cmd.NewRevokeSessionsCmd())
+
+	// Flags keep their values between executions, so every case uses a new command.
+	newCmd := func() *cobra.Command {
+		c := cmd.NewRevokeSessionsCmd()
+		cmdx.RegisterHTTPClientFlags(c.Flags())
+		cmdx.RegisterFormatFlags(c.Flags())
+		require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, admin.URL))
+		require.NoError(t, c.Flags().Set(cmdx.FlagFormat, string(cmdx.FormatJSON)))
+		return c
+	}
+
+	t.Run("case=requires subject or client", func(t *testing.T) {
+		stderr := cmdx.ExecExpectedErr(t, newCmd())
+		assert.Contains(t, stderr, "Please provide at least one of --subject and --client.")
+	})
+
+	t.Run("case=aborts without confirmation", func(t *testing.T) {
+		stdout, stderr, err := cmdx.Exec(t, newCmd(), strings.NewReader("n\n"), "--subject", "foo@bar.com")
+		require.NoError(t, err)
+		assert.Empty(t, stdout)
+		assert.Contains(t, stderr, "Do you wish to revoke the login sessions and consent sessions of subject \"foo@bar.com\"?")
+		assert.Contains(t, stderr, "Revocation aborted.")
+	})
+
+	t.Run("case=revokes sessions of subject", func(t *testing.T) {
+		actual := gjson.Parse(cmdx.ExecNoErr(t, newCmd(), "--subject", "foo@bar.com", "--yes"))
+		assert.Len(t, actual.Array(), 2)
+		assert.Equal(t, "login sessions", actual.Get("0.type").String())
+		assert.Equal(t, "consent sessions", actual.Get("1.type").String())
+		assert.Equal(t, "foo@bar.com", actual.Get("1.subject").String())
+	})
+
+	t.Run("case=revokes sessions of subject for client", func(t *testing.T) {
+		actual := gjson.Parse(cmdx.ExecNoErr(t, newCmd(), "--subject", "foo@bar.com", "--client", "some-client", "--yes"))
+		assert.Len(t, actual.Array(), 1)
+		assert.Equal(t, "consent sessions", actual.Get("0.type").String())
+		assert.Equal(t, "some-client", actual.Get("0.client_id").String())
+	})
+
+	t.Run("case=revokes tokens of client", func(t *testing.T) {
+		expected := createClientCredentialsClient(t, reg)
+		cc := clientcredentials.Config{
+			ClientID: expected.GetID(), ClientSecret: expected.Secret,
+			TokenURL: public.URL + "/oauth2/token",
+		}
+		token, err := cc.Token(ctx)
+		require.NoError(t, err)
+
+		stdout, _, err := cmdx.Exec(t, newCmd(), strings.NewReader("y\n"), "--client", expected.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "access tokens", gjson.Get(stdout, "0.type").String())
+		assert.Equal(t, expected.GetID(), gjson.Get(stdout, "0.client_id").String())
+
+		_, err = reg.OAuth2Storage().GetAccessTokenSession(ctx, reg.OAuth2HMACStrategy().AccessTokenSignature(token.AccessToken), oauth2.NewSession(""))
+		require.Error(t, err)
+	})
+}
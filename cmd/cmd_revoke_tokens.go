@@ -0,0 +1,127 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/urlx"
+)
+
+const (
+	flagRevokeAudience     = "audience"
+	flagRevokeIssuedBefore = "issued-before"
+)
+
+func NewRevokeTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "tokens",
+		Aliases: []string{"all-tokens"},
+		Args:    cobra.NoArgs,
+		Short:   "Revoke all access and refresh tokens matching the given filters",
+		Example: `{{ .CommandPath }} --client a0184d6c-b313-4e70-a0b9-905b581e9218
+{{ .CommandPath }} --subject foo@bar.com --audience https://api.example.com --yes
+{{ .CommandPath }} --issued-before 2023-04-01T00:00:00Z`,
+		Long: `This command revokes all currently active access and refresh tokens matching the filters, for example after an
+OAuth 2.0 Client secret or a signing key has been compromised. Filters are combined, so a token has to match all of them.
+At least one filter is required.
+
+Use --issued-before now to revoke every token issued so far. The command asks for confirmation before revoking
+anything. Use --yes to skip the question, for example in scripts.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			body := map[string]interface{}{}
+			var description []string
+			for _, filter := range []struct{ flag, key string }{
+				{flag: flagRevokeClient, key: "client_id"},
+				{flag: flagRevokeSubject, key: "subject"},
+				{flag: flagRevokeAudience, key: "audience"},
+			} {
+				if v := flagx.MustGetString(cmd, filter.flag); v != "" {
+					body[filter.key] = v
+					description = append(description, fmt.Sprintf("%s %q", filter.key, v))
+				}
+			}
+
+			if v := flagx.MustGetString(cmd, flagRevokeIssuedBefore); v != "" {
+				issuedBefore := time.Now()
+				if v != "now" {
+					var err error
+					if issuedBefore, err = time.Parse(time.RFC3339, v); err != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Flag --%s must be \"now\" or a RFC 3339 timestamp like 2023-04-01T00:00:00Z: %s\n", flagRevokeIssuedBefore, err)
+						return cmdx.FailSilently(cmd)
+					}
+				}
+				body["issued_before"] = issuedBefore.UTC()
+				description = append(description, fmt.Sprintf("issued before %s", issuedBefore.UTC().Format(time.RFC3339)))
+			}
+
+			if len(body) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n\nPlease provide at least one of --client, --subject, --audience and --%s.\n", cmd.UsageString(), flagRevokeIssuedBefore)
+				return cmdx.FailSilently(cmd)
+			}
+
+			if !flagx.MustGetBool(cmd, flagRevokeYes) {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "To skip the next question use flag --yes (at your own risk).")
+				if !cmdx.AskForConfirmation(fmt.Sprintf("Do you wish to revoke all tokens with %s?", strings.Join(description, " and ")), cmd.InOrStdin(), cmd.ErrOrStderr()) {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Revocation aborted.")
+					return nil
+				}
+			}
+
+			client, target, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			encoded, err := json.Marshal(body)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, urlx.AppendPaths(target, "/admin/oauth2/tokens/revoke").String(), bytes.NewReader(encoded))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := client.GetConfig().HTTPClient.Do(req)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(res.Body)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unable to revoke the tokens, received status code %d: %s\n", res.StatusCode, msg)
+				return cmdx.FailSilently(cmd)
+			}
+
+			var revoked outputRevokedTokens
+			if err := json.NewDecoder(res.Body).Decode(&revoked); err != nil {
+				return errors.WithStack(err)
+			}
+
+			cmdx.PrintRow(cmd, revoked)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagRevokeClient, "", "Only revoke tokens issued to this OAuth 2.0 Client ID.")
+	cmd.Flags().String(flagRevokeSubject, "", "Only revoke tokens issued to this subject.")
+	cmd.Flags().String(flagRevokeAudience, "", "Only revoke tokens which were granted this audience.")
+	cmd.Flags().String(flagRevokeIssuedBefore, "", "Only revoke tokens issued before this RFC 3339 timestamp, or \"now\".")
+	cmd.Flags().BoolP(flagRevokeYes, "y", false, "If set all confirmation requests are accepted without user interaction.")
+	return cmd
+}
@@ -0,0 +1,51 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/hydra/v2/oauth2"
+	"github.com/ory/x/cmdx"
+)
+
+func TestRevokeTokens(t *testing.T) {
+	ctx := context.Background()
+	c := cmd.NewRevokeTokensCmd()
+	public, admin, reg := setupRoutes(t, c)
+	require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, admin.URL))
+
+	t.Run("case=requires a filter", func(t *testing.T) {
+		stderr := cmdx.ExecExpectedErr(t, c)
+		assert.Contains(t, stderr, "Please provide at least one of --client, --subject, --audience and --issued-before.")
+	})
+
+	t.Run("case=rejects an invalid timestamp", func(t *testing.T) {
+		stderr := cmdx.ExecExpectedErr(t, cmd.NewRevokeTokensCmd(), "--issued-before", "yesterday")
+		assert.Contains(t, stderr, "must be \"now\" or a RFC 3339 timestamp")
+	})
+
+	t.Run("case=revokes the tokens of a client", func(t *testing.T) {
+		expected := createClientCredentialsClient(t, reg)
+		cc := clientcredentials.Config{
+			ClientID: expected.GetID(), ClientSecret: expected.Secret,
+			TokenURL: public.URL + "/oauth2/token",
+		}
+		token, err := cc.Token(ctx)
+		require.NoError(t, err)
+
+		actual := gjson.Parse(cmdx.ExecNoErr(t, c, "--client", expected.GetID(), "--yes"))
+		assert.EqualValues(t, 1, actual.Get("access_tokens").Int(), actual.Raw)
+
+		_, err = reg.OAuth2Storage().GetAccessTokenSession(ctx, reg.OAuth2HMACStrategy().AccessTokenSignature(token.AccessToken), oauth2.NewSession(""))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	hydra "github.com/ory/hydra-client-go/v2"
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/urlx"
+)
+
+func NewRotateJWKSCmd() *cobra.Command {
+	const (
+		alg   = "alg"
+		use   = "use"
+		grace = "grace"
+	)
+
+	cmd := &cobra.Command{
+		Use:     "jwk <set-id> [<key-id>]",
+		Aliases: []string{"jwks"},
+		Args:    cobra.RangeArgs(1, 2),
+		Example: `{{ .CommandPath }} hydra.openid.id-token --alg RS256 --grace 24h`,
+		Short:   "Rotate the keys of a JSON Web Key Set",
+		Long: `Generates a new JSON Web Key in the set, which is used for signing from now on, and retires all other keys of the set once the grace period has passed.
+
+Until then, the previous keys remain published so that tokens signed with them can still be verified. Choose a grace period at least as long as the lifespan of the longest-living token signed with the set.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, target, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			var kid string
+			if len(args) == 2 {
+				kid = args[1]
+			}
+
+			body, err := json.Marshal(map[string]string{
+				"alg":   flagx.MustGetString(cmd, alg),
+				"use":   flagx.MustGetString(cmd, use),
+				"kid":   kid,
+				"grace": flagx.MustGetDuration(cmd, grace).String(),
+			})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, urlx.AppendPaths(target, "/admin/keys", args[0], "rotate").String(), bytes.NewReader(body))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := client.GetConfig().HTTPClient.Do(req)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusCreated {
+				msg, _ := io.ReadAll(res.Body)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unable to rotate the JSON Web Key Set, received status code %d: %s\n", res.StatusCode, msg)
+				return cmdx.FailSilently(cmd)
+			}
+
+			var jwks hydra.JsonWebKeySet
+			if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+				return errors.WithStack(err)
+			}
+
+			cmdx.PrintTable(cmd, &outputJSONWebKeyCollection{Keys: jwks.Keys, Set: args[0]})
+			return nil
+		},
+	}
+	cmd.Flags().String(alg, "RS256", "The algorithm to be used to generated the new key. Supports: RS256, RS512, ES256, ES512, EdDSA")
+	cmd.Flags().String(use, "sig", "The intended use of the new key. Supports: sig, enc")
+	cmd.Flags().Duration(grace, 24*time.Hour, "How long the previous keys remain published before they are retired.")
+	return cmd
+}
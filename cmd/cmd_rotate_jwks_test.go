@@ -0,0 +1,51 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gofrs/uuid"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+)
+
+func TestRotateJWKS(t *testing.T) {
+	ctx := context.Background()
+	c := cmd.NewRotateJWKSCmd()
+	reg := setup(t, c)
+
+	t.Run("case=rotates with a grace period", func(t *testing.T) {
+		set := uuid.Must(uuid.NewV4()).String()
+		_, err := reg.KeyManager().GenerateAndPersistKeySet(ctx, set, "previous", "ES256", "sig")
+		require.NoError(t, err)
+
+		actual := gjson.Parse(cmdx.ExecNoErr(t, c, set, "next", "--alg", "ES256", "--grace", "1h"))
+		assert.Equal(t, "next", actual.Get("keys.0.kid").String(), "%s", actual.Raw)
+
+		keys, err := reg.KeyManager().GetKeySet(ctx, set)
+		require.NoError(t, err)
+		assert.Len(t, keys.Key("previous"), 1)
+		assert.Equal(t, "next", keys.Keys[0].KeyID)
+	})
+
+	t.Run("case=rotates without a grace period", func(t *testing.T) {
+		set := uuid.Must(uuid.NewV4()).String()
+		_, err := reg.KeyManager().GenerateAndPersistKeySet(ctx, set, "previous", "ES256", "sig")
+		require.NoError(t, err)
+
+		cmdx.ExecNoErr(t, c, set, "next", "--alg", "ES256", "--grace", "0s")
+
+		keys, err := reg.KeyManager().GetKeySet(ctx, set)
+		require.NoError(t, err)
+		require.Len(t, keys.Keys, 1)
+		assert.Equal(t, "next", keys.Keys[0].KeyID)
+	})
+}
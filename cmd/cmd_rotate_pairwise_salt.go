@@ -0,0 +1,89 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/urlx"
+)
+
+type outputPairwiseSaltRotation struct {
+	Mappings  int       `json:"mappings"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (outputPairwiseSaltRotation) Header() []string {
+	return []string{"MAPPINGS", "EXPIRES AT"}
+}
+
+func (i outputPairwiseSaltRotation) Columns() []string {
+	return []string{fmt.Sprintf("%d", i.Mappings), i.ExpiresAt.Format(time.RFC3339)}
+}
+
+func (i outputPairwiseSaltRotation) Interface() interface{} {
+	return i
+}
+
+func NewRotatePairwiseSaltCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "pairwise-salt",
+		Args:    cobra.NoArgs,
+		Example: `{{ .CommandPath }} --window 720h`,
+		Short:   "Rotate the pairwise subject identifier salt",
+		Long: `Maps the pairwise subject identifiers computed with "oidc.subject_identifiers.pairwise.previous_salt" to the ones computed with "oidc.subject_identifiers.pairwise.salt".
+
+Set the new salt and move the old one to the previous salt before running this command. Until the window ends, OAuth2 Clients keep receiving the previous subject identifiers and may look up the new ones via the "/admin/oauth2/pairwise/mappings" endpoint.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, target, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			window, _ := cmd.Flags().GetDuration("window")
+			body, err := json.Marshal(map[string]string{"window": window.String()})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			req, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, urlx.AppendPaths(target, "/admin/oauth2/pairwise/rotate").String(), bytes.NewReader(body))
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+
+			res, err := client.GetConfig().HTTPClient.Do(req)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				msg, _ := io.ReadAll(res.Body)
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Unable to rotate the pairwise salt, received status code %d: %s\n", res.StatusCode, msg)
+				return cmdx.FailSilently(cmd)
+			}
+
+			var out outputPairwiseSaltRotation
+			if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+				return errors.WithStack(err)
+			}
+
+			cmdx.PrintRow(cmd, &out)
+			return nil
+		},
+	}
+	cmd.Flags().Duration("window", 30*24*time.Hour, "How long OAuth2 Clients keep receiving the previous pairwise subject identifiers.")
+	return cmd
+}
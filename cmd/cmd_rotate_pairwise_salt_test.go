@@ -0,0 +1,52 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/x/cmdx"
+)
+
+func TestRotatePairwiseSalt(t *testing.T) {
+	ctx := context.Background()
+	_, admin, reg := setupRoutes(t, cmd.NewRotatePairwiseSaltCmd())
+	reg.Config().MustSet(ctx, config.KeySubjectTypesSupported, []string{"public", "pairwise"})
+	reg.Config().MustSet(ctx, config.KeySubjectIdentifierAlgorithmSalt, "new-salt")
+
+	// Flags keep their values between executions, so every case uses a new command.
+	newCmd := func() *cobra.Command {
+		c := cmd.NewRotatePairwiseSaltCmd()
+		cmdx.RegisterHTTPClientFlags(c.Flags())
+		cmdx.RegisterFormatFlags(c.Flags())
+		require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, admin.URL))
+		require.NoError(t, c.Flags().Set(cmdx.FlagFormat, string(cmdx.FormatJSON)))
+		return c
+	}
+
+	t.Run("case=fails without previous salt", func(t *testing.T) {
+		stderr := cmdx.ExecExpectedErr(t, newCmd(), "--window", "1h")
+		assert.Contains(t, stderr, "Unable to rotate the pairwise salt, received status code 400")
+	})
+
+	t.Run("case=rotates the salt", func(t *testing.T) {
+		reg.Config().MustSet(ctx, config.KeySubjectIdentifierAlgorithmPreviousSalt, "old-salt")
+
+		actual := gjson.Parse(cmdx.ExecNoErr(t, newCmd(), "--window", "1h"))
+		assert.Equal(t, int64(0), actual.Get("mappings").Int())
+
+		expiresAt, err := time.Parse(time.RFC3339, actual.Get("expires_at").String())
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	})
+}
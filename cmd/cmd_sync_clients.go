@@ -0,0 +1,355 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	hydra "github.com/ory/hydra-client-go/v2"
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/pointerx"
+)
+
+const (
+	flagSyncPrune  = "prune"
+	flagSyncDryRun = "dry-run"
+
+	syncActionCreate = "create"
+	syncActionUpdate = "update"
+	syncActionDelete = "delete"
+)
+
+type clientDefinition struct {
+	source string
+	raw    map[string]interface{}
+	client hydra.OAuth2Client
+}
+
+func NewSyncClientsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "oauth2-clients <directory>",
+		Aliases: []string{"oauth2-client", "clients", "client"},
+		Args:    cobra.ExactArgs(1),
+		Short:   "Reconcile OAuth 2.0 Clients with a directory of client definitions",
+		Example: `{{ .CommandPath }} ./clients --dry-run
+{{ .CommandPath }} ./clients --prune`,
+		Long: `This command reads every JSON file in the directory as the definition of a single OAuth 2.0 Client and reconciles the definitions with the OAuth 2.0 Clients known to Ory Hydra:
+
+- Definitions without a "client_id" are created. Because Ory Hydra generates the client ID, the ID of the new client is written back to the definition file so that it can be committed together with it.
+- Definitions which differ from the existing client replace the existing client. Fields which are not part of the definition are reset to their defaults, so an existing client which sets them is updated as well. The client secret is only changed if the definition contains one.
+- With --prune, OAuth 2.0 Clients without a definition are deleted.
+
+The changes are printed before they are applied. Use --dry-run to only print them.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, _, err := cliclient.NewClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			definitions, err := readClientDefinitions(args[0])
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+
+			existing, err := listAllClients(cmd, m)
+			if err != nil {
+				return err
+			}
+
+			changes, err := planClientSync(definitions, existing, flagx.MustGetBool(cmd, flagSyncPrune))
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+
+			for _, change := range changes {
+				switch change.Action {
+				case syncActionCreate:
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "+ %s %s\n", change.Action, change.Source)
+				case syncActionUpdate:
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "~ %s %s (%s)\n", change.Action, change.ID, strings.Join(change.Fields, ", "))
+				case syncActionDelete:
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "- %s %s\n", change.Action, change.ID)
+				}
+			}
+
+			if flagx.MustGetBool(cmd, flagSyncDryRun) {
+				cmdx.PrintTable(cmd, &outputSyncChangeCollection{changes: changes})
+				return nil
+			}
+
+			bySource := make(map[string]clientDefinition, len(definitions))
+			for _, d := range definitions {
+				bySource[d.source] = d
+			}
+
+			var failed bool
+			for k := range changes {
+				change := &changes[k]
+				def := bySource[change.Source]
+
+				var err error
+				switch change.Action {
+				case syncActionCreate:
+					var created *hydra.OAuth2Client
+					created, _, err = m.OAuth2Api.CreateOAuth2Client(cmd.Context()).OAuth2Client(def.client).Execute() //nolint:bodyclose
+					if err == nil {
+						change.ID = pointerx.StringR(created.ClientId)
+						err = writeClientID(def, change.ID)
+					}
+				case syncActionUpdate:
+					_, _, err = m.OAuth2Api.SetOAuth2Client(cmd.Context(), change.ID).OAuth2Client(def.client).Execute() //nolint:bodyclose
+				case syncActionDelete:
+					_, err = m.OAuth2Api.DeleteOAuth2Client(cmd.Context(), change.ID).Execute() //nolint:bodyclose
+				}
+
+				if err != nil {
+					change.Error = err.Error()
+					failed = true
+					continue
+				}
+				change.Executed = true
+			}
+
+			cmdx.PrintTable(cmd, &outputSyncChangeCollection{changes: changes})
+			if failed {
+				return cmdx.FailSilently(cmd)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Bool(flagSyncPrune, false, "Delete OAuth 2.0 Clients which have no definition in the directory.")
+	cmd.Flags().Bool(flagSyncDryRun, false, "Only print the changes without applying them.")
+	return cmd
+}
+
+func readClientDefinitions(dir string) ([]clientDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("could not read directory %s: %w", dir, err)
+	}
+
+	var definitions []clientDefinition
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+
+		source := filepath.Join(dir, e.Name())
+		contents, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("could not open file %s: %w", source, err)
+		}
+
+		d := clientDefinition{source: source}
+		if err := json.Unmarshal(contents, &d.raw); err != nil {
+			return nil, fmt.Errorf("could not decode JSON in %s, expected a single OAuth 2.0 Client: %w", source, err)
+		}
+		if err := json.Unmarshal(contents, &d.client); err != nil {
+			return nil, fmt.Errorf("could not decode JSON in %s, expected a single OAuth 2.0 Client: %w", source, err)
+		}
+		definitions = append(definitions, d)
+	}
+
+	return definitions, nil
+}
+
+func listAllClients(cmd *cobra.Command, m *hydra.APIClient) ([]hydra.OAuth2Client, error) {
+	var all []hydra.OAuth2Client
+	var pageToken string
+	for {
+		req := m.OAuth2Api.ListOAuth2Clients(cmd.Context()).PageSize(500)
+		if pageToken != "" {
+			req = req.PageToken(pageToken)
+		}
+
+		list, resp, err := req.Execute()
+		if err != nil {
+			return nil, cmdx.PrintOpenAPIError(cmd, err)
+		}
+		all = append(all, list...)
+
+		if pageToken = getPageToken(resp); pageToken == "" {
+			return all, nil
+		}
+	}
+}
+
+// planClientSync computes the changes required to make the existing clients match the definitions.
+func planClientSync(definitions []clientDefinition, existing []hydra.OAuth2Client, prune bool) ([]outputSyncChange, error) {
+	byID := make(map[string]hydra.OAuth2Client, len(existing))
+	for _, c := range existing {
+		byID[pointerx.StringR(c.ClientId)] = c
+	}
+
+	changes := []outputSyncChange{}
+	defined := map[string]string{}
+	for _, d := range definitions {
+		id := pointerx.StringR(d.client.ClientId)
+		if id == "" {
+			changes = append(changes, outputSyncChange{Action: syncActionCreate, Source: d.source})
+			continue
+		}
+
+		if other, ok := defined[id]; ok {
+			return nil, fmt.Errorf("OAuth 2.0 Client %s is defined in both %s and %s", id, other, d.source)
+		}
+		defined[id] = d.source
+
+		current, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("OAuth 2.0 Client %s defined in %s does not exist, remove the client_id to create it", id, d.source)
+		}
+
+		fields, err := changedClientFields(d.client, current)
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) > 0 {
+			changes = append(changes, outputSyncChange{Action: syncActionUpdate, ID: id, Source: d.source, Fields: fields})
+		}
+	}
+
+	if prune {
+		for _, c := range existing {
+			if id := pointerx.StringR(c.ClientId); defined[id] == "" {
+				changes = append(changes, outputSyncChange{Action: syncActionDelete, ID: id})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+// serverManagedClientFields are set by the server and therefore never compared.
+var serverManagedClientFields = map[string]bool{
+	"client_id":                 true,
+	"client_secret":             true,
+	"client_secret_expires_at":  true,
+	"created_at":                true,
+	"updated_at":                true,
+	"registration_access_token": true,
+	"registration_client_uri":   true,
+}
+
+// clientFieldDefaults are the values the server assigns to fields which are missing in a definition.
+var clientFieldDefaults = map[string]interface{}{
+	"token_endpoint_auth_method":   "client_secret_basic",
+	"userinfo_signed_response_alg": "none",
+}
+
+// configDefaultedClientFields default to values from the server configuration if they are missing
+// in a definition. They are only compared if the definition sets them.
+var configDefaultedClientFields = map[string]bool{
+	"scope":        true,
+	"subject_type": true,
+}
+
+// changedClientFields returns the fields in which the existing client differs from the definition.
+// Because updating a client replaces it, fields which are missing in the definition are compared
+// against their defaults. The client secret is never returned by the API and therefore not compared.
+func changedClientFields(definition hydra.OAuth2Client, current hydra.OAuth2Client) ([]string, error) {
+	expected, err := clientToMap(definition)
+	if err != nil {
+		return nil, err
+	}
+
+	actual, err := clientToMap(current)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := map[string]bool{}
+	for key := range expected {
+		keys[key] = true
+	}
+	for key := range actual {
+		keys[key] = true
+	}
+
+	var fields []string
+	for key := range keys {
+		if serverManagedClientFields[key] {
+			continue
+		}
+
+		want, ok := expected[key]
+		if !ok || isEmptyJSONValue(want) {
+			if configDefaultedClientFields[key] || isEmptyJSONValue(actual[key]) {
+				continue
+			}
+			want = clientFieldDefaults[key]
+		}
+
+		if isEmptyJSONValue(want) && isEmptyJSONValue(actual[key]) {
+			continue
+		}
+		if !reflect.DeepEqual(want, actual[key]) {
+			fields = append(fields, key)
+		}
+	}
+
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// clientToMap normalizes the client to its JSON representation.
+func clientToMap(c hydra.OAuth2Client) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(encoded, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func isEmptyJSONValue(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case float64:
+		return t == 0
+	case []interface{}:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+// writeClientID stores the ID of a newly created client in its definition file.
+func writeClientID(d clientDefinition, id string) error {
+	d.raw["client_id"] = id
+	encoded, err := json.MarshalIndent(d.raw, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(d.source)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(d.source, append(encoded, '\n'), info.Mode().Perm())
+}
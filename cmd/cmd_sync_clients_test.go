@@ -0,0 +1,97 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/hydra/v2/client"
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/sqlcon"
+)
+
+func TestSyncClients(t *testing.T) {
+	ctx := context.Background()
+	c := cmd.NewSyncClientsCmd()
+	reg := setup(t, c)
+
+	writeDefinition := func(t *testing.T, dir, name string, definition map[string]interface{}) string {
+		raw, err := json.Marshal(definition)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, raw, 0600))
+		return path
+	}
+
+	t.Run("case=creates, updates and prunes clients", func(t *testing.T) {
+		dir := t.TempDir()
+		existing := createClient(t, reg, &client.Client{ClientName: "before", TokenEndpointAuthMethod: "client_secret_basic", Secret: "some-secret"})
+		stale := createClient(t, reg, nil)
+
+		newPath := writeDefinition(t, dir, "new.json", map[string]interface{}{"client_name": "new", "scope": "openid"})
+		writeDefinition(t, dir, "existing.json", map[string]interface{}{"client_id": existing.GetID(), "client_name": "after", "token_endpoint_auth_method": "client_secret_basic"})
+
+		planned := gjson.Parse(cmdx.ExecNoErr(t, c, dir, "--prune", "--dry-run"))
+		assert.Len(t, planned.Get(`#(action=="create")#`).Array(), 1)
+		assert.Equal(t, []interface{}{"client_name"}, planned.Get(`#(action=="update").fields`).Value())
+		assert.Equal(t, stale.GetID(), planned.Get(`#(id=="`+stale.GetID()+`").id`).String())
+
+		actual, err := reg.ClientManager().GetConcreteClient(ctx, existing.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "before", actual.Name)
+
+		result := gjson.Parse(cmdx.ExecNoErr(t, c, dir, "--prune"))
+		for _, change := range result.Array() {
+			assert.True(t, change.Get("executed").Bool(), "%s", change.Raw)
+		}
+
+		actual, err = reg.ClientManager().GetConcreteClient(ctx, existing.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, "after", actual.Name)
+
+		_, err = reg.ClientManager().GetConcreteClient(ctx, stale.GetID())
+		assert.ErrorIs(t, err, sqlcon.ErrNoRows)
+
+		raw, err := os.ReadFile(newPath)
+		require.NoError(t, err)
+		created, err := reg.ClientManager().GetConcreteClient(ctx, gjson.GetBytes(raw, "client_id").String())
+		require.NoError(t, err)
+		assert.Equal(t, "new", created.Name)
+
+		assert.Equal(t, "[]", strings.TrimSpace(cmdx.ExecNoErr(t, c, dir, "--prune")))
+	})
+
+	t.Run("case=compares fields missing in the definition with their defaults", func(t *testing.T) {
+		dir := t.TempDir()
+		unchanged := createClient(t, reg, &client.Client{ClientName: "unchanged", TokenEndpointAuthMethod: "client_secret_basic", Secret: "some-secret"})
+		extra := createClient(t, reg, &client.Client{ClientName: "extra", ClientURI: "https://client.example.com", TokenEndpointAuthMethod: "client_secret_post", Secret: "some-secret"})
+
+		writeDefinition(t, dir, "unchanged.json", map[string]interface{}{"client_id": unchanged.GetID(), "client_name": "unchanged"})
+		writeDefinition(t, dir, "extra.json", map[string]interface{}{"client_id": extra.GetID(), "client_name": "extra"})
+
+		planned := gjson.Parse(cmdx.ExecNoErr(t, c, dir, "--dry-run"))
+		require.Len(t, planned.Array(), 1, "%s", planned.Raw)
+		assert.Equal(t, extra.GetID(), planned.Get("0.id").String())
+		assert.Equal(t, []interface{}{"client_uri", "token_endpoint_auth_method"}, planned.Get("0.fields").Value())
+	})
+
+	t.Run("case=fails if a defined client does not exist", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDefinition(t, dir, "missing.json", map[string]interface{}{"client_id": "i-do-not-exist"})
+
+		_, stderr, err := cmdx.Exec(t, c, nil, dir)
+		require.Error(t, err)
+		assert.Contains(t, stderr, "i-do-not-exist")
+	})
+}
@@ -0,0 +1,185 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/ory/hydra/v2/cmd/cliclient"
+	"github.com/ory/x/cmdx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/urlx"
+)
+
+const (
+	flagVerifyJWKSURL  = "jwks-url"
+	flagVerifyJWKSFile = "jwks-file"
+	flagVerifyAudience = "audience"
+	flagVerifyIssuer   = "issuer"
+	flagVerifyLeeway   = "leeway"
+)
+
+func NewVerifyTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token <jwt>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Verify a JSON Web Token locally using the JSON Web Key Set of Ory Hydra",
+		Example: `{{ .CommandPath }} eyJhbGciOiJSUzI1NiIsImtpZCI6... --endpoint http://localhost:4444 --audience https://api.example.com
+{{ .CommandPath }} eyJhbGciOiJSUzI1NiIsImtpZCI6... --jwks-file ./jwks.json --issuer http://localhost:4444/`,
+		Long: `This command verifies the signature, expiry, audience and issuer of a JSON Web Token, such as a JWT Access Token
+or an OpenID Connect ID Token, without calling the introspection endpoint. It resolves the same problems a resource
+server runs into, which makes it useful to find out why a resource server rejects a token.
+
+The JSON Web Key Set is fetched from the public endpoint's /.well-known/jwks.json, or from --jwks-url. With --jwks-file,
+the key set is read from that file if it exists and written to it after fetching otherwise, so that repeated
+verifications work offline.
+
+The claims of the token are printed even if a claim is invalid. The command fails if the token is not valid.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := jwt.ParseSigned(args[0])
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not parse the token: %s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+			if len(token.Headers) == 0 {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "The token does not contain a JOSE header.")
+				return cmdx.FailSilently(cmd)
+			}
+
+			keys, err := loadVerificationKeys(cmd)
+			if err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Could not load the JSON Web Key Set: %s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+
+			result := outputVerifiedToken{
+				KeyID:     token.Headers[0].KeyID,
+				Algorithm: token.Headers[0].Algorithm,
+			}
+			if err := verifyTokenSignature(token, keys, &result); err != nil {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", err)
+				return cmdx.FailSilently(cmd)
+			}
+
+			if err := result.registered.ValidateWithLeeway(jwt.Expected{
+				Issuer:   flagx.MustGetString(cmd, flagVerifyIssuer),
+				Audience: flagx.MustGetStringSlice(cmd, flagVerifyAudience),
+				Time:     time.Now(),
+			}, flagx.MustGetDuration(cmd, flagVerifyLeeway)); err != nil {
+				result.Error = err.Error()
+				cmdx.PrintRow(cmd, result)
+				return cmdx.FailSilently(cmd)
+			}
+
+			result.Valid = true
+			cmdx.PrintRow(cmd, result)
+			return nil
+		},
+	}
+	cmd.Flags().String(flagVerifyJWKSURL, "", "Fetch the JSON Web Key Set from this URL instead of the endpoint's /.well-known/jwks.json.")
+	cmd.Flags().String(flagVerifyJWKSFile, "", "Read the JSON Web Key Set from this file. If the file does not exist, the fetched key set is written to it.")
+	cmd.Flags().StringSlice(flagVerifyAudience, []string{}, "Require the token to contain this audience. Can be repeated.")
+	cmd.Flags().String(flagVerifyIssuer, "", "Require the token to be issued by this issuer.")
+	cmd.Flags().Duration(flagVerifyLeeway, 0, "Accept tokens which expired or became valid no longer than this duration ago, e.g. 30s.")
+	return cmd
+}
+
+// verifyTokenSignature checks the signature of the token with the key identified by the token's
+// key ID, or with every key of the set if the token has none, and decodes the claims into result.
+func verifyTokenSignature(token *jwt.JSONWebToken, keys *jose.JSONWebKeySet, result *outputVerifiedToken) error {
+	candidates := keys.Keys
+	if result.KeyID != "" {
+		candidates = keys.Key(result.KeyID)
+		if len(candidates) == 0 {
+			return errors.Errorf("The JSON Web Key Set does not contain the key %s the token was signed with.", result.KeyID)
+		}
+	}
+
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if !key.IsPublic() {
+			key = key.Public()
+		}
+		if err := token.Claims(key, &result.registered, &result.Claims); err == nil {
+			return nil
+		}
+	}
+
+	return errors.New("The signature of the token is invalid.")
+}
+
+// loadVerificationKeys reads the JSON Web Key Set from the cache file or fetches it.
+func loadVerificationKeys(cmd *cobra.Command) (*jose.JSONWebKeySet, error) {
+	file := flagx.MustGetString(cmd, flagVerifyJWKSFile)
+	if file != "" {
+		raw, err := os.ReadFile(file)
+		if err == nil {
+			return decodeJWKS(raw)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	client, endpoint, err := cliclient.NewClient(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	location := flagx.MustGetString(cmd, flagVerifyJWKSURL)
+	if location == "" {
+		location = urlx.AppendPaths(endpoint, "/.well-known/jwks.json").String()
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.GetConfig().HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	raw, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("expected status code 200 from %s but got %d: %s", location, res.StatusCode, raw)
+	}
+
+	keys, err := decodeJWKS(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if file != "" {
+		if err := os.WriteFile(file, raw, 0600); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func decodeJWKS(raw []byte) (*jose.JSONWebKeySet, error) {
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return nil, errors.Wrap(err, "could not decode the JSON Web Key Set")
+	}
+	return &keys, nil
+}
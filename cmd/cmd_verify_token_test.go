@@ -0,0 +1,96 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+
+	"github.com/ory/fosite/token/jwt"
+	"github.com/ory/hydra/v2/cmd"
+	"github.com/ory/x/cmdx"
+)
+
+func TestVerifyToken(t *testing.T) {
+	ctx := context.Background()
+	c := cmd.NewVerifyTokenCmd()
+	public, _, reg := setupRoutes(t, c)
+	require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, public.URL))
+
+	generate := func(t *testing.T, expiresAt time.Time) string {
+		token, _, err := reg.OpenIDJWTStrategy().Generate(ctx, jwt.MapClaims{
+			"iss": "https://issuer.example.com/",
+			"sub": "foo@bar.com",
+			"aud": []string{"https://api.example.com"},
+			"exp": expiresAt.Unix(),
+			"iat": time.Now().Unix(),
+		}, jwt.NewHeaders())
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("case=verifies a valid token", func(t *testing.T) {
+		actual := gjson.Parse(cmdx.ExecNoErr(t, c, generate(t, time.Now().Add(time.Hour)),
+			"--audience", "https://api.example.com",
+			"--issuer", "https://issuer.example.com/",
+		))
+		assert.True(t, actual.Get("valid").Bool(), actual.Raw)
+		assert.Equal(t, "foo@bar.com", actual.Get("claims.sub").String())
+		assert.NotEmpty(t, actual.Get("kid").String())
+		assert.Equal(t, "RS256", actual.Get("alg").String())
+	})
+
+	for _, tc := range []struct {
+		name     string
+		expires  time.Time
+		args     []string
+		expected string
+	}{
+		{name: "expired", expires: time.Now().Add(-time.Hour), expected: "token is expired"},
+		{name: "wrong audience", expires: time.Now().Add(time.Hour), args: []string{"--audience", "https://other.example.com"}, expected: "invalid audience"},
+		{name: "wrong issuer", expires: time.Now().Add(time.Hour), args: []string{"--issuer", "https://other.example.com/"}, expected: "invalid issuer"},
+	} {
+		t.Run("case=prints the claims of a token with "+tc.name, func(t *testing.T) {
+			c := cmd.NewVerifyTokenCmd()
+			_, _, _ = setupRoutes(t, c)
+			require.NoError(t, c.Flags().Set(cmdx.FlagEndpoint, public.URL))
+
+			stdout, _, err := cmdx.Exec(t, c, nil, append([]string{generate(t, tc.expires)}, tc.args...)...)
+			require.True(t, errors.Is(err, cmdx.ErrNoPrintButFail), "%+v", err)
+
+			actual := gjson.Parse(stdout)
+			assert.False(t, actual.Get("valid").Bool())
+			assert.Contains(t, actual.Get("error").String(), tc.expected)
+			assert.Equal(t, "foo@bar.com", actual.Get("claims.sub").String())
+		})
+	}
+
+	t.Run("case=rejects a token with an invalid signature", func(t *testing.T) {
+		token := generate(t, time.Now().Add(time.Hour))
+		parts := strings.Split(token, ".")
+		parts[2] = strings.Repeat("A", len(parts[2]))
+
+		stderr := cmdx.ExecExpectedErr(t, c, strings.Join(parts, "."))
+		assert.Contains(t, stderr, "The signature of the token is invalid.")
+	})
+
+	t.Run("case=caches the key set", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "jwks.json")
+		token := generate(t, time.Now().Add(time.Hour))
+		assert.True(t, gjson.Get(cmdx.ExecNoErr(t, c, token, "--jwks-file", file), "valid").Bool())
+
+		offline := cmd.NewVerifyTokenCmd()
+		_, _, _ = setupRoutes(t, offline)
+		require.NoError(t, offline.Flags().Set(cmdx.FlagEndpoint, "http://127.0.0.1:1"))
+		assert.True(t, gjson.Get(cmdx.ExecNoErr(t, offline, token, "--jwks-file", file), "valid").Bool())
+	})
+}
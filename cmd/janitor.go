@@ -10,6 +10,7 @@ import (
 	"github.com/ory/x/servicelocatorx"
 
 	"github.com/ory/hydra/v2/cmd/cli"
+	"github.com/ory/x/cmdx"
 	"github.com/ory/x/configx"
 )
 
@@ -76,6 +77,7 @@ Janitor can be used in several ways.
 	cmd.Flags().Bool(cli.OnlyGrants, false, "This will only run the cleanup on trust relationships and will skip requests and token cleanup.")
 	cmd.Flags().BoolP(cli.ReadFromEnv, "e", false, "If set, reads the database connection string from the environment variable DSN or config file key dsn.")
 	configx.RegisterFlags(cmd.PersistentFlags())
+	cmdx.RegisterFormatFlags(cmd.Flags())
 	return cmd
 
 }
@@ -0,0 +1,42 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+)
+
+type outputBenchResult struct {
+	Flow       string  `json:"flow"`
+	Requests   int     `json:"requests"`
+	Errors     int     `json:"errors"`
+	ErrorRate  float64 `json:"error_rate"`
+	Throughput float64 `json:"requests_per_second"`
+	P50        float64 `json:"p50_ms"`
+	P90        float64 `json:"p90_ms"`
+	P99        float64 `json:"p99_ms"`
+	Max        float64 `json:"max_ms"`
+}
+
+func (outputBenchResult) Header() []string {
+	return []string{"FLOW", "REQUESTS", "ERRORS", "ERROR RATE", "REQ/S", "P50", "P90", "P99", "MAX"}
+}
+
+func (r outputBenchResult) Columns() []string {
+	return []string{
+		r.Flow,
+		fmt.Sprintf("%d", r.Requests),
+		fmt.Sprintf("%d", r.Errors),
+		fmt.Sprintf("%.2f%%", r.ErrorRate*100),
+		fmt.Sprintf("%.1f", r.Throughput),
+		fmt.Sprintf("%.2fms", r.P50),
+		fmt.Sprintf("%.2fms", r.P90),
+		fmt.Sprintf("%.2fms", r.P99),
+		fmt.Sprintf("%.2fms", r.Max),
+	}
+}
+
+func (r outputBenchResult) Interface() interface{} {
+	return r
+}
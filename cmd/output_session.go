@@ -0,0 +1,43 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+type (
+	outputRevokedSession struct {
+		Type    string `json:"type"`
+		Subject string `json:"subject,omitempty"`
+		Client  string `json:"client_id,omitempty"`
+	}
+	outputRevokedSessionCollection struct {
+		sessions []outputRevokedSession
+	}
+)
+
+func (outputRevokedSessionCollection) Header() []string {
+	return []string{"REVOKED", "SUBJECT", "CLIENT ID"}
+}
+
+func (c outputRevokedSessionCollection) Table() [][]string {
+	rows := make([][]string, len(c.sessions))
+	for i, s := range c.sessions {
+		rows[i] = []string{s.Type, s.Subject, s.Client}
+	}
+	return rows
+}
+
+func (c outputRevokedSessionCollection) Interface() interface{} {
+	return c.sessions
+}
+
+func (c outputRevokedSessionCollection) Len() int {
+	return len(c.sessions)
+}
+
+func (c outputRevokedSessionCollection) IDs() []string {
+	ids := make([]string, len(c.sessions))
+	for i, s := range c.sessions {
+		ids[i] = s.Type
+	}
+	return ids
+}
@@ -0,0 +1,56 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"strings"
+)
+
+type (
+	outputSyncChange struct {
+		Action   string   `json:"action"`
+		ID       string   `json:"id"`
+		Source   string   `json:"source,omitempty"`
+		Fields   []string `json:"fields,omitempty"`
+		Error    string   `json:"error,omitempty"`
+		Executed bool     `json:"executed"`
+	}
+	outputSyncChangeCollection struct {
+		changes []outputSyncChange
+	}
+)
+
+func (outputSyncChangeCollection) Header() []string {
+	return []string{"ACTION", "ID", "SOURCE", "CHANGED FIELDS", "EXECUTED"}
+}
+
+func (c outputSyncChangeCollection) Table() [][]string {
+	rows := make([][]string, len(c.changes))
+	for i, change := range c.changes {
+		executed := "no"
+		if change.Error != "" {
+			executed = "failed: " + change.Error
+		} else if change.Executed {
+			executed = "yes"
+		}
+		rows[i] = []string{change.Action, change.ID, change.Source, strings.Join(change.Fields, ", "), executed}
+	}
+	return rows
+}
+
+func (c outputSyncChangeCollection) Interface() interface{} {
+	return c.changes
+}
+
+func (c outputSyncChangeCollection) Len() int {
+	return len(c.changes)
+}
+
+func (c outputSyncChangeCollection) IDs() []string {
+	ids := make([]string, len(c.changes))
+	for i, change := range c.changes {
+		ids[i] = change.ID
+	}
+	return ids
+}
@@ -38,3 +38,20 @@ func (i outputOAuth2Token) Columns() []string {
 func (i outputOAuth2Token) Interface() interface{} {
 	return i
 }
+
+type outputRevokedTokens struct {
+	AccessTokens  int `json:"access_tokens"`
+	RefreshTokens int `json:"refresh_tokens"`
+}
+
+func (outputRevokedTokens) Header() []string {
+	return []string{"REVOKED ACCESS TOKENS", "REVOKED REFRESH TOKENS"}
+}
+
+func (r outputRevokedTokens) Columns() []string {
+	return []string{fmt.Sprintf("%d", r.AccessTokens), fmt.Sprintf("%d", r.RefreshTokens)}
+}
+
+func (r outputRevokedTokens) Interface() interface{} {
+	return r
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type outputVerifiedToken struct {
+	Valid     bool                   `json:"valid"`
+	Error     string                 `json:"error,omitempty"`
+	KeyID     string                 `json:"kid"`
+	Algorithm string                 `json:"alg"`
+	Claims    map[string]interface{} `json:"claims"`
+
+	registered jwt.Claims
+}
+
+func (outputVerifiedToken) Header() []string {
+	return []string{"VALID", "SUBJECT", "ISSUER", "AUDIENCE", "EXPIRY", "KEY ID", "ALGORITHM", "ERROR"}
+}
+
+func (v outputVerifiedToken) Columns() []string {
+	expiry := ""
+	if v.registered.Expiry != nil {
+		expiry = v.registered.Expiry.Time().Format(time.RFC3339)
+	}
+
+	return []string{
+		fmt.Sprintf("%v", v.Valid),
+		v.registered.Subject,
+		v.registered.Issuer,
+		strings.Join(v.registered.Audience, ", "),
+		expiry,
+		v.KeyID,
+		v.Algorithm,
+		v.Error,
+	}
+}
+
+func (v outputVerifiedToken) Interface() interface{} {
+	return v
+}
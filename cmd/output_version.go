@@ -0,0 +1,22 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+type outputVersion struct {
+	Version   string `json:"version"`
+	GitHash   string `json:"git_hash"`
+	BuildTime string `json:"build_time"`
+}
+
+func (outputVersion) Header() []string {
+	return []string{"VERSION", "GIT HASH", "BUILD TIME"}
+}
+
+func (v outputVersion) Columns() []string {
+	return []string{v.Version, v.GitHash, v.BuildTime}
+}
+
+func (v outputVersion) Interface() interface{} {
+	return v
+}
@@ -31,6 +31,7 @@ func RegisterCommandRecursive(parent *cobra.Command, slOpts []servicelocatorx.Op
 	createCmd.AddCommand(
 		NewCreateClientsCommand(),
 		NewCreateJWKSCmd(),
+		NewCreateSampleAppCmd(),
 	)
 
 	getCmd := NewGetCmd()
@@ -65,14 +66,32 @@ func RegisterCommandRecursive(parent *cobra.Command, slOpts []servicelocatorx.Op
 	)
 
 	revokeCmd := NewRevokeCmd()
-	revokeCmd.AddCommand(NewRevokeTokenCmd())
+	revokeCmd.AddCommand(
+		NewRevokeTokenCmd(),
+		NewRevokeSessionsCmd(),
+		NewRevokeTokensCmd(),
+	)
+
+	syncCmd := NewSyncCmd()
+	syncCmd.AddCommand(NewSyncClientsCmd())
+
+	benchCmd := NewBenchCmd()
+	benchCmd.AddCommand(NewBenchTokenCmd())
+
+	rotateCmd := NewRotateCmd()
+	rotateCmd.AddCommand(NewRotatePairwiseSaltCmd())
+	rotateCmd.AddCommand(NewRotateJWKSCmd())
 
 	introspectCmd := NewIntrospectCmd()
 	introspectCmd.AddCommand(NewIntrospectTokenCmd())
 
+	verifyCmd := NewVerifyCmd()
+	verifyCmd.AddCommand(NewVerifyTokenCmd())
+
 	migrateCmd := NewMigrateCmd()
 	migrateCmd.AddCommand(NewMigrateGenCmd())
 	migrateCmd.AddCommand(NewMigrateSqlCmd(slOpts, dOpts, cOpts))
+	migrateCmd.AddCommand(NewMigrateStatusCmd(slOpts, dOpts, cOpts))
 
 	serveCmd := NewServeCmd()
 	serveCmd.AddCommand(NewServeAdminCmd(slOpts, dOpts, cOpts))
@@ -88,7 +107,11 @@ func RegisterCommandRecursive(parent *cobra.Command, slOpts []servicelocatorx.Op
 		importCmd,
 		performCmd,
 		introspectCmd,
+		verifyCmd,
 		revokeCmd,
+		rotateCmd,
+		syncCmd,
+		benchCmd,
 		migrateCmd,
 		serveCmd,
 		NewJanitorCmd(slOpts, dOpts, cOpts),
@@ -42,7 +42,7 @@ All sub-commands share command line flags and configuration options.
 	}
 
 	configx.RegisterFlags(cmd.PersistentFlags())
-	cmd.PersistentFlags().Bool("dev", false, "Disables critical security checks to improve local development experience. Do not use in production.")
+	cmd.PersistentFlags().Bool("dev", false, "Disables critical security checks to improve local development experience. Unless configured, uses a SQLite database in the temporary directory, which is migrated on start, and a fixed system secret. Do not use in production.")
 	cmd.PersistentFlags().Bool("sqa-opt-out", false, "Disable anonymized telemetry reports - for more information please visit https://www.ory.sh/docs/ecosystem/sqa")
 
 	return cmd
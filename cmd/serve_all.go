@@ -15,7 +15,7 @@ import (
 
 // allCmd represents the all command
 func NewServeAllCmd(slOpts []servicelocatorx.Option, dOpts []driver.OptionsModifier, cOpts []configx.OptionModifier) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "all",
 		Short: "Serves both public and administrative HTTP/2 APIs",
 		Long: `Starts a process which listens on two ports for public and administrative HTTP/2 API requests.
@@ -30,7 +30,12 @@ you can also set environments by prepending key value pairs: "KEY=VALUE KEY2=VAL
 All possible controls are listed below. This command exposes exposes command line flags, which are listed below
 the controls section.
 
+For a local development loop, run "hydra serve all --dev --watch -c hydra.yml". It stores its data in a SQLite
+database in the temporary directory unless a DSN is configured and restarts the listeners whenever hydra.yml changes.
+
 ` + serveControls,
 		RunE: server.RunServeAll(slOpts, dOpts, cOpts),
 	}
+	cmd.Flags().Bool("watch", false, "Restarts the listeners with the new configuration whenever a configuration file changes. Requires --dev.")
+	return cmd
 }
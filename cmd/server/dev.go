@@ -0,0 +1,126 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ory/hydra/v2/driver"
+	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/x/configx"
+	"github.com/ory/x/flagx"
+	"github.com/ory/x/otelx"
+	"github.com/ory/x/servicelocatorx"
+	"github.com/ory/x/watcherx"
+)
+
+// developmentSecret is the system secret used with --dev if none is configured. It is fixed so that
+// the keys stored in the development database can still be decrypted after a restart.
+const developmentSecret = "development-mode-secret-do-not-use-in-production"
+
+// developmentOptions returns the defaults of the development mode: a SQLite database in the temporary
+// directory which is migrated on start and a fixed system secret. Configured values take precedence.
+func developmentOptions(cmd *cobra.Command) []driver.OptionsModifier {
+	if !flagx.MustGetBool(cmd, "dev") {
+		return nil
+	}
+
+	return []driver.OptionsModifier{
+		driver.WithOptions(configx.WithBaseValues(map[string]interface{}{
+			config.KeyDSN:             "sqlite://" + filepath.Join(os.TempDir(), "hydra-dev.sqlite") + "?_fk=true",
+			config.KeyGetSystemSecret: []string{developmentSecret},
+		})),
+		driver.WithAutoMigrate(),
+	}
+}
+
+// serveAllWatching serves the public and administrative APIs and restarts both listeners, reloading the
+// complete configuration, whenever a configuration file changes. Changes which do not pass validation
+// are ignored and the last working configuration keeps being served.
+func serveAllWatching(cmd *cobra.Command, sl *servicelocatorx.Options, dOpts []driver.OptionsModifier) error {
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	changed := make(chan struct{}, 1)
+	watcher := configx.AttachWatcher(func(_ watcherx.Event, err error) {
+		if et := new(configx.ImmutableError); err != nil && !errors.As(err, &et) {
+			return
+		}
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	for {
+		revisionCtx, cancelRevision := context.WithCancel(ctx)
+
+		d, err := driver.New(revisionCtx, sl, append(dOpts, driver.WithOptions(configx.WithFlags(cmd.Flags()), watcher)))
+		if err != nil {
+			cancelRevision()
+			return err
+		}
+
+		admin, public, adminmw, publicmw := setup(revisionCtx, d, cmd)
+		d.PrometheusManager().RegisterRouter(admin.Router)
+		d.PrometheusManager().RegisterRouter(public.Router)
+
+		stopReload := make(chan struct{})
+		servers := map[config.ServeInterface]*http.Server{
+			config.PublicInterface: newServer(revisionCtx, d, config.PublicInterface, traceHandler(d, cmd, EnhanceMiddleware(revisionCtx, sl, d, publicmw, d.Config().ListenOn(config.PublicInterface), public.Router, false, config.PublicInterface)), stopReload),
+			config.AdminInterface:  newServer(revisionCtx, d, config.AdminInterface, traceHandler(d, cmd, EnhanceMiddleware(revisionCtx, sl, d, adminmw, d.Config().ListenOn(config.AdminInterface), admin.Router, true, config.AdminInterface)), stopReload),
+		}
+
+		errs := make(chan error, len(servers))
+		for iface, srv := range servers {
+			go func(iface config.ServeInterface, srv *http.Server) {
+				if err := listenAndServe(revisionCtx, d, srv, iface, d.Config().ListenOn(iface), d.Config().SocketPermission(iface)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					errs <- err
+				}
+			}(iface, srv)
+		}
+
+		var restart bool
+		select {
+		case <-changed:
+			d.Logger().Info("The configuration has changed, restarting the listeners.")
+			restart = true
+		case err = <-errs:
+		case <-ctx.Done():
+		}
+
+		close(stopReload)
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+		for _, srv := range servers {
+			_ = srv.Shutdown(shutdownCtx)
+		}
+		cancelShutdown()
+		if c := d.Persister().Connection(context.Background()); c != nil {
+			_ = c.Close()
+		}
+		// Stop watching the files, the next revision loads the configuration again.
+		d.Config().Source(revisionCtx).Close()
+		cancelRevision()
+
+		if !restart {
+			return err
+		}
+	}
+}
+
+func traceHandler(d driver.Registry, cmd *cobra.Command, handler http.Handler) http.Handler {
+	if tracer := d.Tracer(cmd.Context()); tracer.IsLoaded() {
+		return otelx.TraceHandler(handler)
+	}
+	return handler
+}
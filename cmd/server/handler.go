@@ -18,7 +18,9 @@ import (
 	"github.com/ory/x/httprouterx"
 
 	analytics "github.com/ory/analytics-go/v4"
+	"github.com/ory/x/cmdx"
 	"github.com/ory/x/configx"
+	"github.com/ory/x/flagx"
 
 	"github.com/ory/x/reqlog"
 
@@ -55,7 +57,7 @@ func EnhanceMiddleware(ctx context.Context, sl *servicelocatorx.Options, d drive
 		n.UseFunc(mw)
 	}
 
-	n.UseHandler(router)
+	n.UseHandler(x.TimeHandler(router))
 	corsx.ContextualizedMiddleware(func(ctx context.Context) (opts cors.Options, enabled bool) {
 		return d.Config().CORS(ctx, iface)
 	})
@@ -73,6 +75,7 @@ func RunServeAdmin(slOpts []servicelocatorx.Option, dOpts []driver.OptionsModifi
 	return func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		sl := servicelocatorx.NewOptions(slOpts...)
+		dOpts := append(dOpts, developmentOptions(cmd)...)
 
 		d, err := driver.New(cmd.Context(), sl, append(dOpts, driver.WithOptions(configx.WithFlags(cmd.Flags()))))
 		if err != nil {
@@ -106,6 +109,7 @@ func RunServePublic(slOpts []servicelocatorx.Option, dOpts []driver.OptionsModif
 	return func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		sl := servicelocatorx.NewOptions(slOpts...)
+		dOpts := append(dOpts, developmentOptions(cmd)...)
 
 		d, err := driver.New(cmd.Context(), sl, append(dOpts, driver.WithOptions(configx.WithFlags(cmd.Flags()))))
 		if err != nil {
@@ -139,6 +143,15 @@ func RunServeAll(slOpts []servicelocatorx.Option, dOpts []driver.OptionsModifier
 	return func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		sl := servicelocatorx.NewOptions(slOpts...)
+		dOpts := append(dOpts, developmentOptions(cmd)...)
+
+		if flagx.MustGetBool(cmd, "watch") {
+			if !flagx.MustGetBool(cmd, "dev") {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Flag --watch can only be used together with --dev.")
+				return cmdx.FailSilently(cmd)
+			}
+			return serveAllWatching(cmd, sl, dOpts)
+		}
 
 		d, err := driver.New(cmd.Context(), sl, append(dOpts, driver.WithOptions(configx.WithFlags(cmd.Flags()))))
 		if err != nil {
@@ -191,6 +204,11 @@ func setup(ctx context.Context, d driver.Registry, cmd *cobra.Command) (admin *h
 		}
 	}
 
+	x.WatchLogSignals(ctx, d)
+
+	watchdog := x.NewWatchdog(d.Logger(), d.Config().WatchdogGoroutineThreshold(), d.Config().WatchdogLatencyThreshold(), d.Config().WatchdogCooldown())
+	go watchdog.Watch(ctx, d.Config().WatchdogInterval())
+
 	adminmw = negroni.New()
 	publicmw = negroni.New()
 
@@ -204,8 +222,11 @@ func setup(ctx context.Context, d driver.Registry, cmd *cobra.Command) (admin *h
 		adminLogger = adminLogger.ExcludePaths("/admin"+healthx.AliveCheckPath, "/admin"+healthx.ReadyCheckPath)
 	}
 
+	adminmw.UseFunc(x.LatencyBudgetMiddleware(d.Logger(), d.Config().LatencyBudget))
+	adminmw.UseFunc(watchdog.Middleware)
 	adminmw.Use(adminLogger)
 	adminmw.Use(d.PrometheusManager())
+	adminmw.UseFunc(x.TraceIDMiddleware)
 
 	publicLogger := reqlog.NewMiddlewareFromLogger(
 		d.Logger(),
@@ -215,8 +236,11 @@ func setup(ctx context.Context, d driver.Registry, cmd *cobra.Command) (admin *h
 		publicLogger.ExcludePaths(healthx.AliveCheckPath, healthx.ReadyCheckPath)
 	}
 
+	publicmw.UseFunc(x.LatencyBudgetMiddleware(d.Logger(), d.Config().LatencyBudget))
+	publicmw.UseFunc(watchdog.Middleware)
 	publicmw.Use(publicLogger)
 	publicmw.Use(d.PrometheusManager())
+	publicmw.UseFunc(x.TraceIDMiddleware)
 
 	metrics := metricsx.New(
 		cmd,
@@ -265,6 +289,7 @@ func setup(ctx context.Context, d driver.Registry, cmd *cobra.Command) (admin *h
 				"/admin" + consent.LogoutPath + "/reject",
 				"/admin" + consent.SessionsPath + "/login",
 				"/admin" + consent.SessionsPath + "/consent",
+				"/admin" + x.LogLevelPath,
 
 				healthx.AliveCheckPath,
 				healthx.ReadyCheckPath,
@@ -313,43 +338,52 @@ func serve(
 		handler = otelx.TraceHandler(handler)
 	}
 
-	var tlsConfig *tls.Config
 	stopReload := make(chan struct{})
+	srv := newServer(ctx, d, iface, handler, stopReload)
+
+	if err := graceful.Graceful(func() error {
+		return listenAndServe(ctx, d, srv, iface, address, permission)
+	}, func(ctx context.Context) error {
+		close(stopReload)
+		return srv.Shutdown(ctx)
+	}); err != nil {
+		d.Logger().WithError(err).Fatal("Could not gracefully run server")
+	}
+}
+
+func newServer(ctx context.Context, d driver.Registry, iface config.ServeInterface, handler http.Handler, stopReload chan struct{}) *http.Server {
+	var tlsConfig *tls.Config
 	if tc := d.Config().TLS(ctx, iface); tc.Enabled() {
 		// #nosec G402 - This is a false positive because we use graceful.WithDefaults which sets the correct TLS settings.
 		tlsConfig = &tls.Config{GetCertificate: GetOrCreateTLSCertificate(ctx, d, iface, stopReload)}
 	}
 
-	var srv = graceful.WithDefaults(&http.Server{
+	return graceful.WithDefaults(&http.Server{
 		Handler:           handler,
+		ConnState:         d.PrometheusMetrics().ConnState(strings.TrimPrefix(iface.String(), "serve.")),
 		TLSConfig:         tlsConfig,
 		ReadHeaderTimeout: time.Second * 5,
 	})
+}
 
-	if err := graceful.Graceful(func() error {
-		d.Logger().Infof("Setting up http server on %s", address)
-		listener, err := networkx.MakeListener(address, permission)
-		if err != nil {
-			return err
-		}
-
-		if networkx.AddressIsUnixSocket(address) {
-			return srv.Serve(listener)
-		}
+func listenAndServe(ctx context.Context, d driver.Registry, srv *http.Server, iface config.ServeInterface, address string, permission *configx.UnixPermission) error {
+	d.Logger().Infof("Setting up http server on %s", address)
+	listener, err := networkx.MakeListener(address, permission)
+	if err != nil {
+		return err
+	}
 
-		if tlsConfig != nil {
-			return srv.ServeTLS(listener, "", "")
-		}
+	if networkx.AddressIsUnixSocket(address) {
+		return srv.Serve(listener)
+	}
 
-		if iface == config.PublicInterface {
-			d.Logger().Warnln("HTTPS is disabled. Please ensure that your proxy is configured to provide HTTPS, and that it redirects HTTP to HTTPS.")
-		}
+	if d.Config().TLS(ctx, iface).Enabled() {
+		return srv.ServeTLS(listener, "", "")
+	}
 
-		return srv.Serve(listener)
-	}, func(ctx context.Context) error {
-		close(stopReload)
-		return srv.Shutdown(ctx)
-	}); err != nil {
-		d.Logger().WithError(err).Fatal("Could not gracefully run server")
+	if iface == config.PublicInterface {
+		d.Logger().Warnln("HTTPS is disabled. Please ensure that your proxy is configured to provide HTTPS, and that it redirects HTTP to HTTPS.")
 	}
+
+	return srv.Serve(listener)
 }
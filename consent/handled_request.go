@@ -0,0 +1,149 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/ory/fosite"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/urlx"
+)
+
+// acceptLoginRequest marks the login request as accepted and returns the URL the user agent
+// has to be redirected to in order to continue the flow.
+func acceptLoginRequest(ctx context.Context, m Manager, challenge string, p *HandledLoginRequest) (string, error) {
+	if p.Subject == "" {
+		return "", errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'subject' must not be empty."))
+	}
+
+	p.ID = challenge
+	ar, err := m.GetLoginRequest(ctx, challenge)
+	if err != nil {
+		return "", err
+	} else if ar.Subject != "" && p.Subject != ar.Subject {
+		return "", errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'subject' does not match subject from previous authentication."))
+	}
+
+	if ar.Skip {
+		p.Remember = true // If skip is true remember is also true to allow consecutive calls as the same user!
+		p.AuthenticatedAt = ar.AuthenticatedAt
+	} else {
+		p.AuthenticatedAt = sqlxx.NullTime(time.Now().UTC().
+			// Rounding is important to avoid SQL time synchronization issues in e.g. MySQL!
+			Truncate(time.Second))
+		ar.AuthenticatedAt = p.AuthenticatedAt
+	}
+	p.RequestedAt = ar.RequestedAt
+
+	// The raw device fingerprint is never stored.
+	p.DeviceFingerprintHash = deviceFingerprintHash(p.DeviceFingerprint)
+	p.DeviceFingerprint = ""
+
+	request, err := m.HandleLoginRequest(ctx, challenge, p)
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	}
+
+	ru, err := url.Parse(request.RequestURL)
+	if err != nil {
+		return "", err
+	}
+
+	return urlx.SetQuery(ru, url.Values{"login_verifier": {request.Verifier}}).String(), nil
+}
+
+// rejectLoginRequest marks the login request as rejected and returns the URL the user agent
+// has to be redirected to in order to continue the flow.
+func rejectLoginRequest(ctx context.Context, m Manager, challenge string, p *RequestDeniedError) (string, error) {
+	p.valid = true
+	p.SetDefaults(loginRequestDeniedErrorName)
+	ar, err := m.GetLoginRequest(ctx, challenge)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := m.HandleLoginRequest(ctx, challenge, &HandledLoginRequest{
+		Error:       p,
+		ID:          challenge,
+		RequestedAt: ar.RequestedAt,
+	})
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	}
+
+	ru, err := url.Parse(request.RequestURL)
+	if err != nil {
+		return "", err
+	}
+
+	return urlx.SetQuery(ru, url.Values{"login_verifier": {request.Verifier}}).String(), nil
+}
+
+// acceptConsentRequest marks the consent request as accepted, records the decision and returns
+// the URL the user agent has to be redirected to in order to continue the flow.
+func acceptConsentRequest(ctx context.Context, m Manager, challenge string, p *AcceptOAuth2ConsentRequest) (string, error) {
+	cr, err := m.GetConsentRequest(ctx, challenge)
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	}
+
+	p.ID = challenge
+	p.RequestedAt = cr.RequestedAt
+	p.HandledAt = sqlxx.NullTime(time.Now().UTC())
+
+	hr, err := m.HandleConsentRequest(ctx, p)
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	} else if hr.Skip {
+		p.Remember = false
+	}
+
+	if err := m.CreateConsentDecision(ctx, newConsentDecision(hr, p)); err != nil {
+		return "", err
+	}
+
+	ru, err := url.Parse(hr.RequestURL)
+	if err != nil {
+		return "", err
+	}
+
+	return urlx.SetQuery(ru, url.Values{"consent_verifier": {hr.Verifier}}).String(), nil
+}
+
+// rejectConsentRequest marks the consent request as rejected, records the decision and returns
+// the URL the user agent has to be redirected to in order to continue the flow.
+func rejectConsentRequest(ctx context.Context, m Manager, challenge string, p *RequestDeniedError) (string, error) {
+	p.valid = true
+	p.SetDefaults(consentRequestDeniedErrorName)
+	hr, err := m.GetConsentRequest(ctx, challenge)
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	}
+
+	handled := &AcceptOAuth2ConsentRequest{
+		Error:       p,
+		ID:          challenge,
+		RequestedAt: hr.RequestedAt,
+		HandledAt:   sqlxx.NullTime(time.Now().UTC()),
+	}
+	request, err := m.HandleConsentRequest(ctx, handled)
+	if err != nil {
+		return "", errorsx.WithStack(err)
+	}
+
+	if err := m.CreateConsentDecision(ctx, newConsentDecision(request, handled)); err != nil {
+		return "", err
+	}
+
+	ru, err := url.Parse(request.RequestURL)
+	if err != nil {
+		return "", err
+	}
+
+	return urlx.SetQuery(ru, url.Values{"consent_verifier": {request.Verifier}}).String(), nil
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"sort"
 	"time"
 
 	"github.com/ory/x/pagination/tokenpagination"
@@ -20,7 +21,7 @@ import (
 	"github.com/ory/hydra/v2/driver/config"
 	"github.com/ory/hydra/v2/x"
 	"github.com/ory/x/errorsx"
-	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/stringslice"
 	"github.com/ory/x/stringsx"
 	"github.com/ory/x/urlx"
 )
@@ -28,6 +29,8 @@ import (
 type Handler struct {
 	r InternalRegistry
 	c *config.DefaultProvider
+
+	upstreamDiscoveries upstreamDiscoveryCache
 }
 
 const (
@@ -35,6 +38,8 @@ const (
 	ConsentPath  = "/oauth2/auth/requests/consent"
 	LogoutPath   = "/oauth2/auth/requests/logout"
 	SessionsPath = "/oauth2/auth/sessions"
+	PairwisePath = "/oauth2/pairwise"
+	ScopesPath   = "/oauth2/scopes"
 )
 
 func NewHandler(
@@ -56,13 +61,29 @@ func (h *Handler) SetRoutes(admin *httprouterx.RouterAdmin) {
 	admin.PUT(ConsentPath+"/accept", h.acceptOAuth2ConsentRequest)
 	admin.PUT(ConsentPath+"/reject", h.rejectOAuth2ConsentRequest)
 
+	admin.GET(SessionsPath+"/login", h.listOAuth2LoginSessions)
 	admin.DELETE(SessionsPath+"/login", h.revokeOAuth2LoginSessions)
 	admin.GET(SessionsPath+"/consent", h.listOAuth2ConsentSessions)
+	admin.GET(SessionsPath+"/consent/decisions", h.listOAuth2ConsentDecisions)
 	admin.DELETE(SessionsPath+"/consent", h.revokeOAuth2ConsentSessions)
 
 	admin.GET(LogoutPath, h.getOAuth2LogoutRequest)
 	admin.PUT(LogoutPath+"/accept", h.acceptOAuth2LogoutRequest)
 	admin.PUT(LogoutPath+"/reject", h.rejectOAuth2LogoutRequest)
+
+	admin.POST(PairwisePath+"/rotate", h.rotateOAuth2PairwiseSalt)
+	admin.GET(PairwisePath+"/mappings", h.listOAuth2PairwiseSubjectMappings)
+
+	admin.GET(ScopesPath, h.listOAuth2ScopeDescriptions)
+	admin.PUT(ScopesPath, h.setOAuth2ScopeDescriptions)
+	admin.DELETE(ScopesPath, h.deleteOAuth2ScopeDescriptions)
+}
+
+// SetPublicRoutes registers the built-in login handler which delegates authentication to upstream
+// OpenID Connect providers.
+func (h *Handler) SetPublicRoutes(public *httprouterx.RouterPublic) {
+	public.GET(UpstreamLoginPath, h.upstreamLogin)
+	public.GET(UpstreamCallbackPath, h.upstreamCallback)
 }
 
 // Revoke OAuth 2.0 Consent Session Parameters
@@ -221,6 +242,161 @@ func (h *Handler) listOAuth2ConsentSessions(w http.ResponseWriter, r *http.Reque
 	h.r.Writer().Write(w, r, a)
 }
 
+// List OAuth 2.0 Login Session Parameters
+//
+// swagger:parameters listOAuth2LoginSessions
+type listOAuth2LoginSessions struct {
+	tokenpagination.RequestParameters
+
+	// The subject to list the login sessions for.
+	//
+	// in: query
+	// required: true
+	Subject string `json:"subject"`
+}
+
+// swagger:route GET /admin/oauth2/auth/sessions/login oAuth2 listOAuth2LoginSessions
+//
+// # List OAuth 2.0 Login Sessions of a Subject
+//
+// This endpoint lists all login sessions of a subject, including the IP address, the hashed User-Agent, and
+// the device identifier recorded when the login was accepted. If the subject is unknown or has no login
+// sessions, the endpoint returns an empty JSON array with status code 200 OK.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2LoginSessions
+//	  default: errorOAuth2
+func (h *Handler) listOAuth2LoginSessions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	subject := r.URL.Query().Get("subject")
+	if subject == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint(`Query parameter 'subject' is not defined but should have been.`)))
+		return
+	}
+
+	page, itemsPerPage := x.ParsePagination(r)
+
+	s, err := h.r.ConsentManager().FindSubjectsLoginSessions(r.Context(), subject, itemsPerPage, itemsPerPage*page)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if len(s) == 0 {
+		s = []LoginSession{}
+	}
+
+	n, err := h.r.ConsentManager().CountSubjectsLoginSessions(r.Context(), subject)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	x.PaginationHeader(w, r.URL, int64(n), itemsPerPage, itemsPerPage*page)
+	h.r.Writer().Write(w, r, s)
+}
+
+// List OAuth 2.0 Consent Decisions Parameters
+//
+// swagger:parameters listOAuth2ConsentDecisions
+type listOAuth2ConsentDecisions struct {
+	tokenpagination.RequestParameters
+
+	// Only return decisions made by this subject.
+	//
+	// in: query
+	Subject string `json:"subject"`
+
+	// Only return decisions made for this OAuth 2.0 Client.
+	//
+	// in: query
+	Client string `json:"client"`
+
+	// Only return decisions made at or after this time (RFC 3339).
+	//
+	// in: query
+	Since string `json:"since"`
+
+	// Only return decisions made before this time (RFC 3339).
+	//
+	// in: query
+	Until string `json:"until"`
+}
+
+// swagger:route GET /admin/oauth2/auth/sessions/consent/decisions oAuth2 listOAuth2ConsentDecisions
+//
+// # Export OAuth 2.0 Consent Decisions
+//
+// This endpoint exports the immutable audit trail of consent decisions, oldest first. Every time a consent
+// request is accepted or rejected a record is written containing the granted and denied scopes, the remember
+// flag, and the login session the subject was authenticated with. Scopes which Hydra merges into a grant
+// from a remembered consent are recorded separately with `automatic` set. Records are kept even if the
+// consent session is revoked or the client is deleted.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2ConsentDecisions
+//	  default: errorOAuth2
+func (h *Handler) listOAuth2ConsentDecisions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	query := r.URL.Query()
+	filter := ConsentDecisionFilter{
+		Subject:  query.Get("subject"),
+		ClientID: query.Get("client"),
+	}
+
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{
+		{name: "since", t: &filter.Since},
+		{name: "until", t: &filter.Until},
+	} {
+		if v := query.Get(bound.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Query parameter '%s' must be a RFC 3339 timestamp.", bound.name)))
+				return
+			}
+			*bound.t = t
+		}
+	}
+
+	page, itemsPerPage := x.ParsePagination(r)
+
+	ds, err := h.r.ConsentManager().FindConsentDecisions(r.Context(), filter, itemsPerPage, itemsPerPage*page)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if len(ds) == 0 {
+		ds = []ConsentDecision{}
+	}
+
+	n, err := h.r.ConsentManager().CountConsentDecisions(r.Context(), filter)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	x.PaginationHeader(w, r.URL, int64(n), itemsPerPage, itemsPerPage*page)
+	h.r.Writer().Write(w, r, ds)
+}
+
 // Revoke OAuth 2.0 Consent Login Sessions Parameters
 //
 // swagger:parameters revokeOAuth2LoginSessions
@@ -238,6 +414,14 @@ type revokeOAuth2LoginSessions struct {
 	//
 	// in: query
 	SessionID string `json:"sid"`
+
+	// Perform Back-Channel Logout
+	//
+	// If set to `true` together with `subject`, OpenID Connect Back-Channel Logout is performed for all revoked
+	// sessions. Defaults to `false`.
+	//
+	// in: query
+	BackChannelLogout bool `json:"back_channel_logout"`
 }
 
 // swagger:route DELETE /admin/oauth2/auth/sessions/login oAuth2 revokeOAuth2LoginSessions
@@ -248,7 +432,9 @@ type revokeOAuth2LoginSessions struct {
 // has to re-authenticate at the Ory OAuth2 Provider. This endpoint does not invalidate any tokens.
 //
 // If you send the subject in a query param, all authentication sessions that belong to that subject are revoked.
-// No OpennID Connect Front- or Back-channel logout is performed in this case.
+// No OpenID Connect Front-channel logout is performed in this case. Back-channel logout is only performed if the
+// `back_channel_logout` query param is set to `true`. Clients with subject-wide back-channel logout then receive a
+// single logout token, all other clients one logout token per session.
 //
 // Alternatively, you can send a SessionID via `sid` query param, in which case, only the session that is connected
 // to that SessionID is revoked. OpenID Connect Back-channel logout is performed in this case.
@@ -283,7 +469,17 @@ func (h *Handler) revokeOAuth2LoginSessions(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if err := h.r.ConsentManager().RevokeSubjectLoginSession(r.Context(), subject); err != nil {
+	if r.URL.Query().Get("back_channel_logout") != "true" {
+		if err := h.r.ConsentManager().RevokeSubjectLoginSession(r.Context(), subject); err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := h.r.ConsentStrategy().HandleHeadlessSubjectLogout(r.Context(), w, r, subject); err != nil {
 		h.r.Writer().WriteError(w, r, err)
 		return
 	}
@@ -414,46 +610,15 @@ func (h *Handler) acceptOAuth2LoginRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if p.Subject == "" {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'subject' must not be empty.")))
-		return
-	}
-
-	p.ID = challenge
-	ar, err := h.r.ConsentManager().GetLoginRequest(r.Context(), challenge)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, err)
-		return
-	} else if ar.Subject != "" && p.Subject != ar.Subject {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'subject' does not match subject from previous authentication.")))
-		return
-	}
-
-	if ar.Skip {
-		p.Remember = true // If skip is true remember is also true to allow consecutive calls as the same user!
-		p.AuthenticatedAt = ar.AuthenticatedAt
-	} else {
-		p.AuthenticatedAt = sqlxx.NullTime(time.Now().UTC().
-			// Rounding is important to avoid SQL time synchronization issues in e.g. MySQL!
-			Truncate(time.Second))
-		ar.AuthenticatedAt = p.AuthenticatedAt
-	}
-	p.RequestedAt = ar.RequestedAt
-
-	request, err := h.r.ConsentManager().HandleLoginRequest(r.Context(), challenge, &p)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	}
-
-	ru, err := url.Parse(request.RequestURL)
+	redirectTo, err := acceptLoginRequest(r.Context(), h.r.ConsentManager(), challenge, &p)
 	if err != nil {
+		h.countChallengeReplay("login", err)
 		h.r.Writer().WriteError(w, r, err)
 		return
 	}
 
 	h.r.Writer().Write(w, r, &OAuth2RedirectTo{
-		RedirectTo: urlx.SetQuery(ru, url.Values{"login_verifier": {request.Verifier}}).String(),
+		RedirectTo: redirectTo,
 	})
 }
 
@@ -515,32 +680,15 @@ func (h *Handler) rejectOAuth2LoginRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	p.valid = true
-	p.SetDefaults(loginRequestDeniedErrorName)
-	ar, err := h.r.ConsentManager().GetLoginRequest(r.Context(), challenge)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, err)
-		return
-	}
-
-	request, err := h.r.ConsentManager().HandleLoginRequest(r.Context(), challenge, &HandledLoginRequest{
-		Error:       &p,
-		ID:          challenge,
-		RequestedAt: ar.RequestedAt,
-	})
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	}
-
-	ru, err := url.Parse(request.RequestURL)
+	redirectTo, err := rejectLoginRequest(r.Context(), h.r.ConsentManager(), challenge, &p)
 	if err != nil {
+		h.countChallengeReplay("login", err)
 		h.r.Writer().WriteError(w, r, err)
 		return
 	}
 
 	h.r.Writer().Write(w, r, &OAuth2RedirectTo{
-		RedirectTo: urlx.SetQuery(ru, url.Values{"login_verifier": {request.Verifier}}).String(),
+		RedirectTo: redirectTo,
 	})
 }
 
@@ -612,6 +760,17 @@ func (h *Handler) getOAuth2ConsentRequest(w http.ResponseWriter, r *http.Request
 		request.RequestedAudience = []string{}
 	}
 
+	var uiLocales []string
+	if request.OpenIDConnectContext != nil {
+		uiLocales = request.OpenIDConnectContext.UILocales
+	}
+	catalog, err := scopeCatalog(r.Context(), h.c, h.r.ConsentManager(), request.RequestedScope)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+	request.RequestedScopeDescriptions = localizedScopeDescriptions(catalog, request.RequestedScope, uiLocales)
+
 	request.Client = sanitizeClient(request.Client)
 	h.r.Writer().Write(w, r, request)
 }
@@ -680,32 +839,15 @@ func (h *Handler) acceptOAuth2ConsentRequest(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	cr, err := h.r.ConsentManager().GetConsentRequest(r.Context(), challenge)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	}
-
-	p.ID = challenge
-	p.RequestedAt = cr.RequestedAt
-	p.HandledAt = sqlxx.NullTime(time.Now().UTC())
-
-	hr, err := h.r.ConsentManager().HandleConsentRequest(r.Context(), &p)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	} else if hr.Skip {
-		p.Remember = false
-	}
-
-	ru, err := url.Parse(hr.RequestURL)
+	redirectTo, err := acceptConsentRequest(r.Context(), h.r.ConsentManager(), challenge, &p)
 	if err != nil {
+		h.countChallengeReplay("consent", err)
 		h.r.Writer().WriteError(w, r, err)
 		return
 	}
 
 	h.r.Writer().Write(w, r, &OAuth2RedirectTo{
-		RedirectTo: urlx.SetQuery(ru, url.Values{"consent_verifier": {hr.Verifier}}).String(),
+		RedirectTo: redirectTo,
 	})
 }
 
@@ -772,33 +914,15 @@ func (h *Handler) rejectOAuth2ConsentRequest(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	p.valid = true
-	p.SetDefaults(consentRequestDeniedErrorName)
-	hr, err := h.r.ConsentManager().GetConsentRequest(r.Context(), challenge)
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	}
-
-	request, err := h.r.ConsentManager().HandleConsentRequest(r.Context(), &AcceptOAuth2ConsentRequest{
-		Error:       &p,
-		ID:          challenge,
-		RequestedAt: hr.RequestedAt,
-		HandledAt:   sqlxx.NullTime(time.Now().UTC()),
-	})
-	if err != nil {
-		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
-		return
-	}
-
-	ru, err := url.Parse(request.RequestURL)
+	redirectTo, err := rejectConsentRequest(r.Context(), h.r.ConsentManager(), challenge, &p)
 	if err != nil {
+		h.countChallengeReplay("consent", err)
 		h.r.Writer().WriteError(w, r, err)
 		return
 	}
 
 	h.r.Writer().Write(w, r, &OAuth2RedirectTo{
-		RedirectTo: urlx.SetQuery(ru, url.Values{"consent_verifier": {request.Verifier}}).String(),
+		RedirectTo: redirectTo,
 	})
 }
 
@@ -936,3 +1060,362 @@ func (h *Handler) getOAuth2LogoutRequest(w http.ResponseWriter, r *http.Request,
 
 	h.r.Writer().Write(w, r, request)
 }
+
+// Rotate OAuth 2.0 Pairwise Salt Request
+//
+// swagger:model rotateOAuth2PairwiseSaltRequest
+type RotateOAuth2PairwiseSaltRequest struct {
+	// Window is the duration (e.g. `720h`) during which clients keep receiving the pairwise subject
+	// identifiers computed with the previous salt.
+	//
+	// required: true
+	Window string `json:"window"`
+}
+
+// OAuth 2.0 Pairwise Salt Rotation
+//
+// swagger:model oAuth2PairwiseSaltRotation
+type OAuth2PairwiseSaltRotation struct {
+	// Mappings is the number of pairwise subject identifiers which were mapped.
+	Mappings int `json:"mappings"`
+
+	// ExpiresAt is the end of the dual-read window.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Rotate OAuth 2.0 Pairwise Salt Parameters
+//
+// swagger:parameters rotateOAuth2PairwiseSalt
+type rotateOAuth2PairwiseSalt struct {
+	// in: body
+	Body RotateOAuth2PairwiseSaltRequest
+}
+
+// swagger:route POST /admin/oauth2/pairwise/rotate oAuth2 rotateOAuth2PairwiseSalt
+//
+// # Rotate the Pairwise Subject Identifier Salt
+//
+// Call this endpoint after moving the current salt to `oidc.subject_identifiers.pairwise.previous_salt`
+// and configuring a new `oidc.subject_identifiers.pairwise.salt`. For every subject which has consented to a
+// pairwise client, the identifier computed with the previous salt is mapped to the one computed with the
+// new salt. Until the window ends, clients keep receiving the previous identifier and `id_token_hint`
+// accepts both, so that clients can migrate their records using the mappings endpoint.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2PairwiseSaltRotation
+//	  default: errorOAuth2
+func (h *Handler) rotateOAuth2PairwiseSalt(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var p RotateOAuth2PairwiseSaltRequest
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&p); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithHintf("Unable to decode body because: %s", err)))
+		return
+	}
+
+	window, err := time.ParseDuration(p.Window)
+	if err != nil || window <= 0 {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Field 'window' must be a positive duration but got '%s'.", p.Window)))
+		return
+	}
+
+	if !stringslice.Has(h.c.SubjectTypesSupported(r.Context()), "pairwise") {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("The pairwise subject identifier algorithm is not enabled.")))
+		return
+	}
+
+	// Both salts are read from the configuration on every call so that a salt changed by a
+	// configuration reload is used right away.
+	previousSalt := h.c.SubjectIdentifierAlgorithmPreviousSalt(r.Context())
+	if previousSalt == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Configuration key 'oidc.subject_identifiers.pairwise.previous_salt' must be set to rotate the pairwise salt.")))
+		return
+	}
+
+	currentSalt := h.c.SubjectIdentifierAlgorithmSalt(r.Context())
+	if currentSalt == previousSalt {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Configuration keys 'oidc.subject_identifiers.pairwise.salt' and 'oidc.subject_identifiers.pairwise.previous_salt' must differ to rotate the pairwise salt.")))
+		return
+	}
+
+	expiresAt := time.Now().UTC().Add(window).Round(time.Second)
+	n, err := rotatePairwiseSubjects(r.Context(), h.r, []byte(previousSalt), []byte(currentSalt), expiresAt)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("mappings", n).
+		WithField("expires_at", expiresAt).
+		Info("Pairwise subject identifier salt was rotated.")
+
+	h.r.Writer().Write(w, r, &OAuth2PairwiseSaltRotation{
+		Mappings:  n,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// List OAuth 2.0 Pairwise Subject Mappings Parameters
+//
+// swagger:parameters listOAuth2PairwiseSubjectMappings
+type listOAuth2PairwiseSubjectMappings struct {
+	tokenpagination.RequestParameters
+
+	// The OAuth 2.0 Client to list the mappings for.
+	//
+	// in: query
+	// required: true
+	Client string `json:"client"`
+}
+
+// swagger:route GET /admin/oauth2/pairwise/mappings oAuth2 listOAuth2PairwiseSubjectMappings
+//
+// # List OAuth 2.0 Pairwise Subject Mappings
+//
+// This endpoint lists the pairwise subject identifiers of a client which are still within the dual-read
+// window of a salt rotation, together with the identifiers the client will receive once the window ends.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2PairwiseSubjectMappings
+//	  default: errorOAuth2
+func (h *Handler) listOAuth2PairwiseSubjectMappings(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	cl := r.URL.Query().Get("client")
+	if cl == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint(`Query parameter 'client' is not defined but should have been.`)))
+		return
+	}
+
+	page, itemsPerPage := x.ParsePagination(r)
+
+	ms, err := h.r.ConsentManager().FindPairwiseSubjectMappings(r.Context(), cl, itemsPerPage, itemsPerPage*page)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if len(ms) == 0 {
+		ms = []PairwiseSubjectMapping{}
+	}
+
+	n, err := h.r.ConsentManager().CountPairwiseSubjectMappings(r.Context(), cl)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	x.PaginationHeader(w, r.URL, int64(n), itemsPerPage, itemsPerPage*page)
+	h.r.Writer().Write(w, r, ms)
+}
+
+// Set OAuth 2.0 Scope Descriptions Request
+//
+// swagger:model setOAuth2ScopeDescriptionsRequest
+type SetOAuth2ScopeDescriptionsRequest struct {
+	// Name is the OAuth 2.0 scope to describe.
+	//
+	// required: true
+	Name string `json:"name"`
+
+	// Locales contains the display name and description of the scope, keyed by BCP 47 language tag.
+	//
+	// required: true
+	Locales map[string]config.ScopeDescription `json:"locales"`
+}
+
+// Set OAuth 2.0 Scope Descriptions Parameters
+//
+// swagger:parameters setOAuth2ScopeDescriptions
+type setOAuth2ScopeDescriptions struct {
+	// in: body
+	Body SetOAuth2ScopeDescriptionsRequest
+}
+
+// swagger:route PUT /admin/oauth2/scopes oAuth2 setOAuth2ScopeDescriptions
+//
+// # Set OAuth 2.0 Scope Descriptions
+//
+// This endpoint replaces the localized descriptions of a scope. They take precedence over the entries of
+// `oauth2.scope_catalog` for the same scope and are included in consent requests matching the request's
+// `ui_locales`. The scope is passed in the body because scopes often contain slashes.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2ScopeDescriptions
+//	  default: errorOAuth2
+func (h *Handler) setOAuth2ScopeDescriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var p SetOAuth2ScopeDescriptionsRequest
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&p); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithHintf("Unable to decode body because: %s", err)))
+		return
+	}
+
+	if p.Name == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'name' must be set.")))
+		return
+	}
+	if len(p.Locales) == 0 {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'locales' must contain at least one locale.")))
+		return
+	}
+
+	descriptions := make([]ScopeDescription, 0, len(p.Locales))
+	for locale, description := range p.Locales {
+		if locale == "" {
+			h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("Field 'locales' must not contain an empty locale.")))
+			return
+		}
+		descriptions = append(descriptions, ScopeDescription{Locale: locale, Name: description.Name, Description: description.Description})
+	}
+	sort.Slice(descriptions, func(i, j int) bool { return descriptions[i].Locale < descriptions[j].Locale })
+
+	if err := h.r.ConsentManager().SetScopeDescriptions(r.Context(), p.Name, descriptions); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("scope", p.Name).
+		WithField("locales", len(descriptions)).
+		Info("Scope descriptions were set.")
+
+	h.r.Writer().Write(w, r, descriptions)
+}
+
+// List OAuth 2.0 Scope Descriptions Parameters
+//
+// swagger:parameters listOAuth2ScopeDescriptions
+type listOAuth2ScopeDescriptions struct {
+	tokenpagination.RequestParameters
+
+	// Only return the descriptions of this scope.
+	//
+	// in: query
+	Scope string `json:"scope"`
+}
+
+// swagger:route GET /admin/oauth2/scopes oAuth2 listOAuth2ScopeDescriptions
+//
+// # List OAuth 2.0 Scope Descriptions
+//
+// This endpoint lists the scope descriptions managed through the admin API. Entries of
+// `oauth2.scope_catalog` are not included.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: oAuth2ScopeDescriptions
+//	  default: errorOAuth2
+func (h *Handler) listOAuth2ScopeDescriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	scope := r.URL.Query().Get("scope")
+	page, itemsPerPage := x.ParsePagination(r)
+
+	ds, err := h.r.ConsentManager().FindScopeDescriptions(r.Context(), scope, itemsPerPage, itemsPerPage*page)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	if len(ds) == 0 {
+		ds = []ScopeDescription{}
+	}
+
+	n, err := h.r.ConsentManager().CountScopeDescriptions(r.Context(), scope)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	x.PaginationHeader(w, r.URL, int64(n), itemsPerPage, itemsPerPage*page)
+	h.r.Writer().Write(w, r, ds)
+}
+
+// Delete OAuth 2.0 Scope Descriptions Parameters
+//
+// swagger:parameters deleteOAuth2ScopeDescriptions
+type deleteOAuth2ScopeDescriptions struct {
+	// The scope to delete the descriptions of.
+	//
+	// in: query
+	// required: true
+	Scope string `json:"scope"`
+}
+
+// swagger:route DELETE /admin/oauth2/scopes oAuth2 deleteOAuth2ScopeDescriptions
+//
+// # Delete OAuth 2.0 Scope Descriptions
+//
+// This endpoint deletes the descriptions of a scope managed through the admin API. Afterwards the entries
+// of `oauth2.scope_catalog` apply again.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  204: emptyResponse
+//	  default: errorOAuth2
+func (h *Handler) deleteOAuth2ScopeDescriptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	scope := r.URL.Query().Get("scope")
+	if scope == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint(`Query parameter 'scope' is not defined but should have been.`)))
+		return
+	}
+
+	if err := h.r.ConsentManager().DeleteScopeDescriptions(r.Context(), scope); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("scope", scope).
+		Info("Scope descriptions were deleted.")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// countChallengeReplay counts attempts to accept or reject a login or consent request which was
+// already handled.
+func (h *Handler) countChallengeReplay(flow string, err error) {
+	if errors.Is(err, x.ErrConflict) {
+		h.r.PrometheusMetrics().CountChallengeReplay(flow, "challenge")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -21,11 +22,13 @@ import (
 
 	"github.com/ory/hydra/v2/internal"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	hydra "github.com/ory/hydra-client-go/v2"
 	"github.com/ory/hydra/v2/client"
 	. "github.com/ory/hydra/v2/consent"
+	"github.com/ory/hydra/v2/driver/config"
 )
 
 func TestGetLogoutRequest(t *testing.T) {
@@ -213,6 +216,180 @@ func TestGetConsentRequest(t *testing.T) {
 	}
 }
 
+func TestScopeDescriptions(t *testing.T) {
+	ctx := context.Background()
+	conf := internal.NewConfigurationWithDefaults()
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+
+	const scope = "https://api.example.com/orders.read"
+	conf.MustSet(ctx, config.KeyScopeCatalog, []map[string]interface{}{{
+		"name":    scope,
+		"locales": map[string]interface{}{"en": map[string]interface{}{"name": "Read orders (config)"}},
+	}})
+
+	cl := &client.Client{LegacyClientID: "scope-descriptions"}
+	require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+	lr := &LoginRequest{ID: "login-scope-descriptions", Client: cl, RequestURL: "http://192.0.2.1"}
+	require.NoError(t, reg.ConsentManager().CreateLoginRequest(ctx, lr))
+	_, err := reg.ConsentManager().HandleLoginRequest(ctx, lr.ID, &HandledLoginRequest{ID: lr.ID})
+	require.NoError(t, err)
+	require.NoError(t, reg.ConsentManager().CreateConsentRequest(ctx, &OAuth2ConsentRequest{
+		Client:               cl,
+		ID:                   "scope-descriptions",
+		Verifier:             "scope-descriptions",
+		CSRF:                 "scope-descriptions",
+		LoginChallenge:       sqlxx.NullString(lr.ID),
+		RequestedScope:       []string{scope},
+		OpenIDConnectContext: &OAuth2ConsentRequestOpenIDConnectContext{UILocales: []string{"de-CH"}},
+	}))
+
+	h := NewHandler(reg, conf)
+	r := x.NewRouterAdmin(conf.AdminURL)
+	h.SetRoutes(r)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	do := func(t *testing.T, method, path string, body interface{}, status int) *http.Response {
+		var b bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&b).Encode(body))
+		}
+		req, err := http.NewRequest(method, ts.URL+"/admin"+path, &b)
+		require.NoError(t, err)
+		res, err := ts.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		require.Equal(t, status, res.StatusCode)
+		return res
+	}
+
+	describe := func(t *testing.T) OAuth2ConsentRequestScopeDescription {
+		var result OAuth2ConsentRequest
+		require.NoError(t, json.NewDecoder(do(t, "GET", ConsentPath+"?challenge=scope-descriptions", nil, http.StatusOK).Body).Decode(&result))
+		return result.RequestedScopeDescriptions[scope]
+	}
+
+	t.Run("case=configured entry is used", func(t *testing.T) {
+		assert.Equal(t, OAuth2ConsentRequestScopeDescription{Locale: "en", Name: "Read orders (config)"}, describe(t))
+	})
+
+	t.Run("case=invalid requests are rejected", func(t *testing.T) {
+		do(t, "PUT", ScopesPath, SetOAuth2ScopeDescriptionsRequest{Locales: map[string]config.ScopeDescription{"en": {Name: "Read"}}}, http.StatusBadRequest)
+		do(t, "PUT", ScopesPath, SetOAuth2ScopeDescriptionsRequest{Name: scope}, http.StatusBadRequest)
+		do(t, "DELETE", ScopesPath, nil, http.StatusBadRequest)
+	})
+
+	t.Run("case=managed entry takes precedence", func(t *testing.T) {
+		do(t, "PUT", ScopesPath, SetOAuth2ScopeDescriptionsRequest{
+			Name: scope,
+			Locales: map[string]config.ScopeDescription{
+				"de": {Name: "Bestellungen lesen"},
+				"en": {Name: "Read orders"},
+			},
+		}, http.StatusOK)
+
+		var ds []ScopeDescription
+		require.NoError(t, json.NewDecoder(do(t, "GET", ScopesPath+"?scope="+url.QueryEscape(scope), nil, http.StatusOK).Body).Decode(&ds))
+		require.Len(t, ds, 2)
+
+		assert.Equal(t, OAuth2ConsentRequestScopeDescription{Locale: "de", Name: "Bestellungen lesen"}, describe(t))
+	})
+
+	t.Run("case=deleting the managed entry restores the configured one", func(t *testing.T) {
+		do(t, "DELETE", ScopesPath+"?scope="+url.QueryEscape(scope), nil, http.StatusNoContent)
+		assert.Equal(t, OAuth2ConsentRequestScopeDescription{Locale: "en", Name: "Read orders (config)"}, describe(t))
+	})
+}
+
+func TestRotateOAuth2PairwiseSalt(t *testing.T) {
+	ctx := context.Background()
+	conf := internal.NewConfigurationWithDefaults()
+	conf.MustSet(ctx, config.KeySubjectTypesSupported, []string{"public", "pairwise"})
+	conf.MustSet(ctx, config.KeySubjectIdentifierAlgorithmSalt, "old-salt")
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+
+	cl := &client.Client{LegacyClientID: "pairwise-rotation", SubjectType: "pairwise", RedirectURIs: []string{"https://rp.example.com/cb"}}
+	require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+	lr := &LoginRequest{ID: "login-pairwise-rotation", Client: cl, Subject: "alice", RequestURL: "http://192.0.2.1"}
+	require.NoError(t, reg.ConsentManager().CreateLoginRequest(ctx, lr))
+	_, err := reg.ConsentManager().HandleLoginRequest(ctx, lr.ID, &HandledLoginRequest{ID: lr.ID, Subject: "alice"})
+	require.NoError(t, err)
+	require.NoError(t, reg.ConsentManager().CreateConsentRequest(ctx, &OAuth2ConsentRequest{
+		Client:         cl,
+		ID:             "pairwise-rotation",
+		Verifier:       "pairwise-rotation",
+		CSRF:           "pairwise-rotation",
+		Subject:        "alice",
+		LoginChallenge: sqlxx.NullString(lr.ID),
+	}))
+	_, err = reg.ConsentManager().HandleConsentRequest(ctx, &AcceptOAuth2ConsentRequest{
+		ID:        "pairwise-rotation",
+		HandledAt: sqlxx.NullTime(time.Now()),
+	})
+	require.NoError(t, err)
+
+	h := NewHandler(reg, conf)
+	r := x.NewRouterAdmin(conf.AdminURL)
+	h.SetRoutes(r)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	rotate := func(t *testing.T, window string, status int) *http.Response {
+		res, err := ts.Client().Post(ts.URL+"/admin"+PairwisePath+"/rotate", "application/json", bytes.NewBufferString(fmt.Sprintf(`{"window":%q}`, window)))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		require.Equal(t, status, res.StatusCode)
+		return res
+	}
+
+	t.Run("case=previous salt is required", func(t *testing.T) {
+		rotate(t, "1h", http.StatusBadRequest)
+	})
+
+	t.Run("case=salts must differ", func(t *testing.T) {
+		conf.MustSet(ctx, config.KeySubjectIdentifierAlgorithmPreviousSalt, "old-salt")
+		rotate(t, "1h", http.StatusBadRequest)
+	})
+
+	conf.MustSet(ctx, config.KeySubjectIdentifierAlgorithmSalt, "new-salt")
+	conf.MustSet(ctx, config.KeySubjectIdentifierAlgorithmPreviousSalt, "old-salt")
+
+	t.Run("case=window must be a positive duration", func(t *testing.T) {
+		rotate(t, "not-a-duration", http.StatusBadRequest)
+		rotate(t, "-1h", http.StatusBadRequest)
+	})
+
+	t.Run("case=mappings are created with the salts from the configuration", func(t *testing.T) {
+		var result OAuth2PairwiseSaltRotation
+		require.NoError(t, json.NewDecoder(rotate(t, "1h", http.StatusOK).Body).Decode(&result))
+		assert.Equal(t, 1, result.Mappings)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), result.ExpiresAt, time.Minute)
+
+		previous, err := NewSubjectIdentifierAlgorithmPairwise([]byte("old-salt")).Obfuscate("alice", cl)
+		require.NoError(t, err)
+		current, err := NewSubjectIdentifierAlgorithmPairwise([]byte("new-salt")).Obfuscate("alice", cl)
+		require.NoError(t, err)
+
+		res, err := ts.Client().Get(ts.URL + "/admin" + PairwisePath + "/mappings?client=" + cl.GetID())
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var ms []PairwiseSubjectMapping
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&ms))
+		require.Len(t, ms, 1)
+		assert.Equal(t, previous, ms[0].PreviousSubject)
+		assert.Equal(t, current, ms[0].CurrentSubject)
+	})
+
+	t.Run("case=listing mappings requires a client", func(t *testing.T) {
+		res, err := ts.Client().Get(ts.URL + "/admin" + PairwisePath + "/mappings")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
 func TestGetLoginRequestWithDuplicateAccept(t *testing.T) {
 	t.Run("Test get login request with duplicate accept", func(t *testing.T) {
 		challenge := "challenge"
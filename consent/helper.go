@@ -4,7 +4,12 @@
 package consent
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"time"
@@ -17,8 +22,10 @@ import (
 
 	"github.com/ory/fosite"
 	"github.com/ory/x/mapx"
+	"github.com/ory/x/stringslice"
 
 	"github.com/ory/hydra/v2/client"
+	"github.com/ory/hydra/v2/driver/config"
 )
 
 func sanitizeClientFromRequest(ar fosite.AuthorizeRequester) *client.Client {
@@ -51,6 +58,129 @@ func matchScopes(scopeStrategy fosite.ScopeStrategy, previousConsent []AcceptOAu
 	return nil
 }
 
+// previouslyGrantedScopes returns the requested scopes which have been granted by any of the
+// previous consent sessions.
+func previouslyGrantedScopes(scopeStrategy fosite.ScopeStrategy, previousConsent []AcceptOAuth2ConsentRequest, requestedScope []string) (granted []string) {
+	for _, scope := range requestedScope {
+		for _, cs := range previousConsent {
+			if scopeStrategy(cs.GrantedScope, scope) {
+				granted = append(granted, scope)
+				break
+			}
+		}
+	}
+	return granted
+}
+
+// missingRequiredAMR returns the authentication method references required by the client
+// which are not part of amr.
+func missingRequiredAMR(cl fosite.Client, amr []string) (missing []string) {
+	c, ok := cl.(*client.Client)
+	if !ok {
+		return nil
+	}
+
+	for _, required := range c.RequiredAMR {
+		if !stringslice.Has(amr, required) {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
+
+// unacceptableACR returns true if the client requires one of a set of authentication context
+// class references and acr is none of them.
+func unacceptableACR(cl fosite.Client, acr string) bool {
+	c, ok := cl.(*client.Client)
+	if !ok || len(c.RequiredACR) == 0 {
+		return false
+	}
+
+	return !stringslice.Has(c.RequiredACR, acr)
+}
+
+// defaultScopeLocale is used if none of the requested ui_locales has a scope catalog entry.
+const defaultScopeLocale = "en"
+
+// scopeCatalog returns the scope catalog for the given scopes. Scopes described through the
+// admin API replace the statically configured entries for that scope.
+func scopeCatalog(ctx context.Context, c *config.DefaultProvider, m Manager, scopes []string) (map[string]map[string]config.ScopeDescription, error) {
+	catalog := c.ScopeCatalog(ctx)
+	if len(scopes) == 0 {
+		return catalog, nil
+	}
+
+	managed, err := m.GetScopeDescriptions(ctx, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := map[string]bool{}
+	for _, d := range managed {
+		if !replaced[d.Scope] {
+			catalog[d.Scope] = map[string]config.ScopeDescription{}
+			replaced[d.Scope] = true
+		}
+		catalog[d.Scope][d.Locale] = config.ScopeDescription{Name: d.Name, Description: d.Description}
+	}
+	return catalog, nil
+}
+
+// localizedScopeDescriptions picks the best matching catalog entry for each scope. Locales are
+// tried in the order of ui_locales, first as given and then by their primary language subtag,
+// before falling back to defaultScopeLocale.
+func localizedScopeDescriptions(catalog map[string]map[string]config.ScopeDescription, scopes, uiLocales []string) map[string]OAuth2ConsentRequestScopeDescription {
+	var candidates []string
+	for _, locale := range uiLocales {
+		candidates = append(candidates, locale)
+		if primary, _, found := strings.Cut(locale, "-"); found {
+			candidates = append(candidates, primary)
+		}
+	}
+	candidates = append(candidates, defaultScopeLocale)
+
+	result := map[string]OAuth2ConsentRequestScopeDescription{}
+	for _, scope := range scopes {
+		entries, ok := catalog[scope]
+		if !ok {
+			continue
+		}
+
+		for _, locale := range candidates {
+			if d, ok := entries[locale]; ok {
+				result[scope] = OAuth2ConsentRequestScopeDescription{Locale: locale, Name: d.Name, Description: d.Description}
+				break
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// userAgentHash returns the hex encoded SHA-256 hash of the request's User-Agent header, or
+// an empty string if the header is not set.
+func userAgentHash(r *http.Request) string {
+	ua := r.UserAgent()
+	if ua == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(ua))
+	return hex.EncodeToString(sum[:])
+}
+
+// deviceFingerprintHash returns the hex encoded SHA-256 hash of the device fingerprint provided
+// by the login provider, or an empty string if none was provided.
+func deviceFingerprintHash(fingerprint string) string {
+	if fingerprint == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(fingerprint))
+	return hex.EncodeToString(sum[:])
+}
+
 func createCsrfSession(w http.ResponseWriter, r *http.Request, conf x.CookieConfigProvider, store sessions.Store, name string, csrfValue string, maxAge time.Duration) error {
 	// Errors can be ignored here, because we always get a session back. Error typically means that the
 	// session doesn't exist yet.
@@ -105,3 +235,14 @@ func legacyCsrfSessionName(name string) string {
 func isLegacyCsrfSessionName(name string) bool {
 	return strings.HasSuffix(name, "_legacy")
 }
+
+// maxAgeExceeded returns the max_age of the authorization request and whether a login
+// authenticated at authTime is too old for a request registered at requestedAt.
+func maxAgeExceeded(form url.Values, authTime, requestedAt time.Time) (maxAge int64, exceeded bool) {
+	maxAge, err := strconv.ParseInt(form.Get("max_age"), 10, 64)
+	if err != nil || maxAge <= 0 || authTime.IsZero() || requestedAt.IsZero() {
+		return 0, false
+	}
+
+	return maxAge, authTime.Add(time.Second * time.Duration(maxAge)).Before(requestedAt)
+}
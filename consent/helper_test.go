@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 
 	"github.com/ory/fosite"
 	"github.com/ory/hydra/v2/client"
+	"github.com/ory/hydra/v2/driver/config"
 )
 
 func TestSanitizeClient(t *testing.T) {
@@ -88,6 +90,75 @@ func TestMatchScopes(t *testing.T) {
 	}
 }
 
+func TestPreviouslyGrantedScopes(t *testing.T) {
+	previous := []AcceptOAuth2ConsentRequest{
+		{GrantedScope: []string{"openid", "email"}},
+		{GrantedScope: []string{"profile"}},
+	}
+
+	assert.Equal(t, []string{"openid", "profile"}, previouslyGrantedScopes(fosite.ExactScopeStrategy, previous, []string{"openid", "offline_access", "profile"}))
+	assert.Empty(t, previouslyGrantedScopes(fosite.ExactScopeStrategy, previous, []string{"offline_access"}))
+	assert.Empty(t, previouslyGrantedScopes(fosite.ExactScopeStrategy, nil, []string{"openid"}))
+}
+
+func TestMissingRequiredAMR(t *testing.T) {
+	for k, tc := range []struct {
+		required []string
+		amr      []string
+		expected []string
+	}{
+		{required: nil, amr: []string{"pwd"}, expected: nil},
+		{required: []string{"mfa"}, amr: []string{"pwd", "mfa"}, expected: nil},
+		{required: []string{"mfa"}, amr: []string{"pwd"}, expected: []string{"mfa"}},
+		{required: []string{"mfa", "hwk"}, amr: []string{"hwk"}, expected: []string{"mfa"}},
+		{required: []string{"mfa", "hwk"}, amr: nil, expected: []string{"mfa", "hwk"}},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expected, missingRequiredAMR(&client.Client{RequiredAMR: tc.required}, tc.amr))
+		})
+	}
+}
+
+func TestLocalizedScopeDescriptions(t *testing.T) {
+	catalog := map[string]map[string]config.ScopeDescription{
+		"offline_access": {
+			"en": {Name: "Offline access"},
+			"de": {Name: "Offline-Zugriff"},
+		},
+		"email": {
+			"en":    {Name: "Email"},
+			"de-CH": {Name: "E-Mail-Adresse"},
+		},
+	}
+
+	assert.Nil(t, localizedScopeDescriptions(catalog, []string{"openid"}, nil))
+	assert.Equal(t, map[string]OAuth2ConsentRequestScopeDescription{
+		"offline_access": {Locale: "de", Name: "Offline-Zugriff"},
+		"email":          {Locale: "de-CH", Name: "E-Mail-Adresse"},
+	}, localizedScopeDescriptions(catalog, []string{"openid", "offline_access", "email"}, []string{"de-CH", "fr"}))
+	assert.Equal(t, map[string]OAuth2ConsentRequestScopeDescription{
+		"offline_access": {Locale: "en", Name: "Offline access"},
+	}, localizedScopeDescriptions(catalog, []string{"offline_access"}, []string{"fr"}))
+}
+
+func TestUnacceptableACR(t *testing.T) {
+	for k, tc := range []struct {
+		required []string
+		acr      string
+		expected bool
+	}{
+		{required: nil, acr: "", expected: false},
+		{required: nil, acr: "silver", expected: false},
+		{required: []string{"gold", "silver"}, acr: "silver", expected: false},
+		{required: []string{"gold"}, acr: "silver", expected: true},
+		{required: []string{"gold"}, acr: "", expected: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			assert.Equal(t, tc.expected, unacceptableACR(&client.Client{RequiredACR: tc.required}, tc.acr))
+		})
+	}
+}
+
 func TestValidateCsrfSession(t *testing.T) {
 	const name = "oauth2_authentication_csrf"
 
@@ -420,3 +491,26 @@ func TestCreateCsrfSession(t *testing.T) {
 		})
 	}
 }
+
+func TestMaxAgeExceeded(t *testing.T) {
+	now := time.Now().UTC()
+	for k, tc := range []struct {
+		form           url.Values
+		authTime       time.Time
+		expectedMaxAge int64
+		exceeded       bool
+	}{
+		{form: url.Values{}, authTime: now.Add(-time.Hour)},
+		{form: url.Values{"max_age": {"0"}}, authTime: now.Add(-time.Hour)},
+		{form: url.Values{"max_age": {"invalid"}}, authTime: now.Add(-time.Hour)},
+		{form: url.Values{"max_age": {"60"}}},
+		{form: url.Values{"max_age": {"60"}}, authTime: now.Add(-time.Second * 30), expectedMaxAge: 60},
+		{form: url.Values{"max_age": {"60"}}, authTime: now.Add(-time.Hour), expectedMaxAge: 60, exceeded: true},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			maxAge, exceeded := maxAgeExceeded(tc.form, tc.authTime, now)
+			assert.Equal(t, tc.expectedMaxAge, maxAge)
+			assert.Equal(t, tc.exceeded, exceeded)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/ory/fosite"
+	"github.com/ory/hydra/v2/x"
+	"github.com/ory/x/errorsx"
+)
+
+// PreLogoutHookRequest is the request body sent to a client's pre-logout hook.
+//
+// swagger:ignore
+type PreLogoutHookRequest struct {
+	// Subject is the user who is being logged out.
+	Subject string `json:"subject"`
+	// SessionID is the login session which is being logged out.
+	SessionID string `json:"sid"`
+	// ClientID is the identifier of the OAuth 2.0 client which initiated the logout.
+	ClientID string `json:"client_id"`
+	// RPInitiated is true if the logout was initiated by the client using an id_token_hint.
+	RPInitiated bool `json:"rp_initiated"`
+	// RequestURL is the original logout URL.
+	RequestURL string `json:"request_url"`
+}
+
+// executePreLogoutHook calls the pre-logout hook of the client which initiated the
+// logout, if any. The hook may abort the logout by responding with HTTP 403.
+func (s *DefaultStrategy) executePreLogoutHook(ctx context.Context, lr *LogoutRequest) error {
+	if lr.Client == nil || lr.Client.PreLogoutHookURL == "" {
+		return nil
+	}
+
+	ctx, span := x.StartWebhookSpan(ctx, "pre_logout_hook", lr.Client.PreLogoutHookURL)
+	defer span.End()
+
+	body, err := json.Marshal(&PreLogoutHookRequest{
+		Subject:     lr.Subject,
+		SessionID:   lr.SessionID,
+		ClientID:    lr.Client.GetID(),
+		RPInitiated: lr.RPInitiated,
+		RequestURL:  lr.RequestURL,
+	})
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while encoding the pre-logout hook.").
+				WithDebugf("Unable to encode the pre-logout hook body: %s", err),
+		)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, lr.Client.PreLogoutHookURL, bytes.NewReader(body))
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while preparing the pre-logout hook.").
+				WithDebugf("Unable to prepare the HTTP Request: %s", err),
+		)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	start := time.Now()
+	resp, err := s.r.HTTPClient(ctx).Do(req)
+	s.r.Metrics(ctx).RecordWebhook(ctx, "pre_logout_hook", start, err)
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while executing the pre-logout hook.").
+				WithDebugf("Unable to execute HTTP Request: %s", err),
+		)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent:
+		return nil
+	case http.StatusForbidden:
+		return errorsx.WithStack(
+			fosite.ErrAccessDenied.
+				WithDescription("The pre-logout hook target denied the logout.").
+				WithDebugf("Pre-logout hook responded with HTTP status code: %s", resp.Status),
+		)
+	default:
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithDescription("The pre-logout hook target responded with an error.").
+				WithDebugf("Pre-logout hook responded with HTTP status code: %s", resp.Status),
+		)
+	}
+}
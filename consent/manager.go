@@ -36,12 +36,19 @@ type Manager interface {
 	FindSubjectsSessionGrantedConsentRequests(ctx context.Context, user, sid string, limit, offset int) ([]AcceptOAuth2ConsentRequest, error)
 	CountSubjectsGrantedConsentRequests(ctx context.Context, user string) (int, error)
 
+	// Consent decision audit trail
+	CreateConsentDecision(ctx context.Context, decision *ConsentDecision) error
+	FindConsentDecisions(ctx context.Context, filter ConsentDecisionFilter, limit, offset int) ([]ConsentDecision, error)
+	CountConsentDecisions(ctx context.Context, filter ConsentDecisionFilter) (int, error)
+
 	// Cookie management
 	GetRememberedLoginSession(ctx context.Context, id string) (*LoginSession, error)
 	CreateLoginSession(ctx context.Context, session *LoginSession) error
 	DeleteLoginSession(ctx context.Context, id string) error
 	RevokeSubjectLoginSession(ctx context.Context, user string) error
-	ConfirmLoginSession(ctx context.Context, id string, authTime time.Time, subject string, remember bool) error
+	ConfirmLoginSession(ctx context.Context, session *LoginSession) error
+	FindSubjectsLoginSessions(ctx context.Context, subject string, limit, offset int) ([]LoginSession, error)
+	CountSubjectsLoginSessions(ctx context.Context, subject string) (int, error)
 
 	CreateLoginRequest(ctx context.Context, req *LoginRequest) error
 	GetLoginRequest(ctx context.Context, challenge string) (*LoginRequest, error)
@@ -51,8 +58,23 @@ type Manager interface {
 	CreateForcedObfuscatedLoginSession(ctx context.Context, session *ForcedObfuscatedLoginSession) error
 	GetForcedObfuscatedLoginSession(ctx context.Context, client, obfuscated string) (*ForcedObfuscatedLoginSession, error)
 
+	// Admin-managed scope catalog
+	SetScopeDescriptions(ctx context.Context, scope string, descriptions []ScopeDescription) error
+	GetScopeDescriptions(ctx context.Context, scopes []string) ([]ScopeDescription, error)
+	FindScopeDescriptions(ctx context.Context, scope string, limit, offset int) ([]ScopeDescription, error)
+	CountScopeDescriptions(ctx context.Context, scope string) (int, error)
+	DeleteScopeDescriptions(ctx context.Context, scope string) error
+
+	// Pairwise subject salt rotation
+	ListPairwiseSubjects(ctx context.Context, limit, offset int) ([]PairwiseSubject, error)
+	CreatePairwiseSubjectMapping(ctx context.Context, mapping *PairwiseSubjectMapping) error
+	GetPairwiseSubjectMapping(ctx context.Context, client, subject string) (*PairwiseSubjectMapping, error)
+	FindPairwiseSubjectMappings(ctx context.Context, client string, limit, offset int) ([]PairwiseSubjectMapping, error)
+	CountPairwiseSubjectMappings(ctx context.Context, client string) (int, error)
+
 	ListUserAuthenticatedClientsWithFrontChannelLogout(ctx context.Context, subject, sid string) ([]client.Client, error)
 	ListUserAuthenticatedClientsWithBackChannelLogout(ctx context.Context, subject, sid string) ([]client.Client, error)
+	ListUserAuthenticatedClientsWithBackChannelLogoutForSessions(ctx context.Context, subject string, sids []string) (map[string][]client.Client, error)
 
 	CreateLogoutRequest(ctx context.Context, request *LogoutRequest) error
 	GetLogoutRequest(ctx context.Context, challenge string) (*LogoutRequest, error)
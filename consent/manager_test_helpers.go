@@ -287,8 +287,8 @@ func TestHelperNID(t1ClientManager client.Manager, t1ValidNID Manager, t2Invalid
 		require.Error(t, err)
 		_, err = t1ValidNID.HandleLoginRequest(context.Background(), testLR.ID, &testHLR)
 		require.NoError(t, err)
-		require.NoError(t, t2InvalidNID.ConfirmLoginSession(context.Background(), testLS.ID, time.Now(), testLS.Subject, true))
-		require.NoError(t, t1ValidNID.ConfirmLoginSession(context.Background(), testLS.ID, time.Now(), testLS.Subject, true))
+		require.NoError(t, t2InvalidNID.ConfirmLoginSession(context.Background(), &LoginSession{ID: testLS.ID, AuthenticatedAt: sqlxx.NullTime(time.Now()), Subject: testLS.Subject, Remember: true}))
+		require.NoError(t, t1ValidNID.ConfirmLoginSession(context.Background(), &LoginSession{ID: testLS.ID, AuthenticatedAt: sqlxx.NullTime(time.Now()), Subject: testLS.Subject, Remember: true}))
 		require.Error(t, t2InvalidNID.DeleteLoginSession(context.Background(), testLS.ID))
 		require.NoError(t, t1ValidNID.DeleteLoginSession(context.Background(), testLS.ID))
 	}
@@ -355,17 +355,41 @@ func ManagerTests(m Manager, clientManager client.Manager, fositeManager x.Fosit
 					require.EqualError(t, err, x.ErrNotFound.Error())
 
 					updatedAuth := time.Time(tc.s.AuthenticatedAt).Add(time.Second)
-					require.NoError(t, m.ConfirmLoginSession(context.Background(), tc.s.ID, updatedAuth, tc.s.Subject, true))
+					require.NoError(t, m.ConfirmLoginSession(context.Background(), &LoginSession{
+						ID:                    tc.s.ID,
+						AuthenticatedAt:       sqlxx.NullTime(updatedAuth),
+						Subject:               tc.s.Subject,
+						Remember:              true,
+						IPAddress:             "127.0.0.1",
+						UserAgentHash:         "ua-hash",
+						DeviceID:              "device-1",
+						DeviceFingerprintHash: "fingerprint-hash",
+					}))
 
 					got, err := m.GetRememberedLoginSession(context.Background(), tc.s.ID)
 					require.NoError(t, err)
 					assert.EqualValues(t, tc.s.ID, got.ID)
 					assert.Equal(t, updatedAuth.Unix(), time.Time(got.AuthenticatedAt).Unix()) // this was updated from confirm...
 					assert.EqualValues(t, tc.s.Subject, got.Subject)
+					assert.EqualValues(t, "127.0.0.1", got.IPAddress)
+					assert.EqualValues(t, "ua-hash", got.UserAgentHash)
+					assert.EqualValues(t, "device-1", got.DeviceID)
+					assert.EqualValues(t, "fingerprint-hash", got.DeviceFingerprintHash)
+
+					sessions, err := m.FindSubjectsLoginSessions(context.Background(), tc.s.Subject, 100, 0)
+					require.NoError(t, err)
+					var found bool
+					for _, s := range sessions {
+						found = found || s.ID == tc.s.ID
+					}
+					assert.True(t, found)
+					n, err := m.CountSubjectsLoginSessions(context.Background(), tc.s.Subject)
+					require.NoError(t, err)
+					assert.Equal(t, len(sessions), n)
 
 					time.Sleep(time.Second) // Make sure AuthAt does not equal...
 					updatedAuth2 := time.Now().Truncate(time.Second).UTC()
-					require.NoError(t, m.ConfirmLoginSession(context.Background(), tc.s.ID, updatedAuth2, "some-other-subject", true))
+					require.NoError(t, m.ConfirmLoginSession(context.Background(), &LoginSession{ID: tc.s.ID, AuthenticatedAt: sqlxx.NullTime(updatedAuth2), Subject: "some-other-subject", Remember: true}))
 
 					got2, err := m.GetRememberedLoginSession(context.Background(), tc.s.ID)
 					require.NoError(t, err)
@@ -873,6 +897,34 @@ func ManagerTests(m Manager, clientManager client.Manager, fositeManager x.Fosit
 						check(t, backChannels, actual)
 					})
 				}
+
+				sidsBySubject := map[string][]string{}
+				for _, ls := range sessions {
+					sidsBySubject[ls.Subject] = append(sidsBySubject[ls.Subject], ls.ID)
+				}
+
+				for subject, sids := range sidsBySubject {
+					t.Run(fmt.Sprintf("method=ListUserAuthenticatedClientsWithBackChannelLogoutForSessions/subject=%s", subject), func(t *testing.T) {
+						actual, err := m.ListUserAuthenticatedClientsWithBackChannelLogoutForSessions(context.Background(), subject, append(sids, "does-not-exist"))
+						require.NoError(t, err)
+						assert.Empty(t, actual["does-not-exist"])
+
+						for _, sid := range sids {
+							expected := backChannels[sid]
+							require.Len(t, actual[sid], len(expected), "%s", sid)
+							for _, e := range expected {
+								var found bool
+								for _, a := range actual[sid] {
+									if e.GetID() == a.GetID() {
+										found = true
+										assert.Equal(t, e.BackChannelLogoutURI, a.BackChannelLogoutURI)
+									}
+								}
+								assert.True(t, found, "%s", e.GetID())
+							}
+						}
+					})
+				}
 			})
 
 			t.Run("case=LogoutRequest", func(t *testing.T) {
@@ -936,6 +988,173 @@ func ManagerTests(m Manager, clientManager client.Manager, fositeManager x.Fosit
 			})
 		})
 
+		t.Run("case=consent-decisions", func(t *testing.T) {
+			ctx := context.Background()
+			subject, other := uuid.New().String(), uuid.New().String()
+			clientA, clientB := uuid.New().String(), uuid.New().String()
+			start := time.Now().UTC().Add(-time.Hour).Round(time.Second)
+
+			for k, d := range []ConsentDecision{
+				{Subject: subject, ClientID: clientA, Decision: ConsentDecisionAccept, GrantedScope: []string{"openid"}, CreatedAt: start},
+				{Subject: subject, ClientID: clientB, Decision: ConsentDecisionReject, Error: "access_denied", CreatedAt: start.Add(10 * time.Minute)},
+				{Subject: subject, ClientID: clientA, Decision: ConsentDecisionAccept, Automatic: true, CreatedAt: start.Add(20 * time.Minute)},
+				{Subject: other, ClientID: clientA, Decision: ConsentDecisionAccept, Skip: true, CreatedAt: start.Add(30 * time.Minute)},
+			} {
+				d := d
+				d.ConsentChallenge = fmt.Sprintf("challenge-%d", k)
+				require.NoError(t, m.CreateConsentDecision(ctx, &d))
+			}
+
+			for k, tc := range []struct {
+				filter   ConsentDecisionFilter
+				expected []string
+			}{
+				{filter: ConsentDecisionFilter{Subject: subject}, expected: []string{"challenge-0", "challenge-1", "challenge-2"}},
+				{filter: ConsentDecisionFilter{ClientID: clientA}, expected: []string{"challenge-0", "challenge-2", "challenge-3"}},
+				{filter: ConsentDecisionFilter{Subject: subject, ClientID: clientB}, expected: []string{"challenge-1"}},
+				{filter: ConsentDecisionFilter{ClientID: clientA, Since: start.Add(10 * time.Minute)}, expected: []string{"challenge-2", "challenge-3"}},
+				{filter: ConsentDecisionFilter{ClientID: clientA, Until: start.Add(20 * time.Minute)}, expected: []string{"challenge-0"}},
+				{filter: ConsentDecisionFilter{Subject: subject, Since: start.Add(5 * time.Minute), Until: start.Add(25 * time.Minute)}, expected: []string{"challenge-1", "challenge-2"}},
+				{filter: ConsentDecisionFilter{Subject: uuid.New().String()}, expected: []string{}},
+			} {
+				t.Run(fmt.Sprintf("filter=%d", k), func(t *testing.T) {
+					ds, err := m.FindConsentDecisions(ctx, tc.filter, 100, 0)
+					require.NoError(t, err)
+
+					actual := []string{}
+					for _, d := range ds {
+						actual = append(actual, d.ConsentChallenge)
+					}
+					assert.Equal(t, tc.expected, actual)
+
+					n, err := m.CountConsentDecisions(ctx, tc.filter)
+					require.NoError(t, err)
+					assert.Equal(t, len(tc.expected), n)
+				})
+			}
+
+			ds, err := m.FindConsentDecisions(ctx, ConsentDecisionFilter{Subject: subject}, 1, 1)
+			require.NoError(t, err)
+			require.Len(t, ds, 1)
+			assert.Equal(t, "challenge-1", ds[0].ConsentChallenge)
+			assert.Equal(t, "access_denied", ds[0].Error)
+
+			ds, err = m.FindConsentDecisions(ctx, ConsentDecisionFilter{Subject: other}, 100, 0)
+			require.NoError(t, err)
+			require.Len(t, ds, 1)
+			assert.True(t, ds[0].Skip)
+			assert.False(t, ds[0].Automatic)
+		})
+
+		t.Run("case=scope-descriptions", func(t *testing.T) {
+			ctx := context.Background()
+			scope := "https://api.example.com/" + uuid.New().String() + ".read"
+			other := uuid.New().String()
+
+			require.NoError(t, m.SetScopeDescriptions(ctx, scope, []ScopeDescription{
+				{Locale: "de", Name: "Lesen"},
+				{Locale: "en", Name: "Read", Description: "Read your data."},
+			}))
+			require.NoError(t, m.SetScopeDescriptions(ctx, other, []ScopeDescription{{Locale: "en", Name: "Other"}}))
+
+			ds, err := m.GetScopeDescriptions(ctx, []string{scope})
+			require.NoError(t, err)
+			require.Len(t, ds, 2)
+			assert.Equal(t, scope, ds[0].Scope)
+			assert.Equal(t, "de", ds[0].Locale)
+			assert.Equal(t, "Lesen", ds[0].Name)
+			assert.Equal(t, "en", ds[1].Locale)
+			assert.Equal(t, "Read your data.", ds[1].Description)
+
+			ds, err = m.GetScopeDescriptions(ctx, []string{scope, other, uuid.New().String()})
+			require.NoError(t, err)
+			assert.Len(t, ds, 3)
+
+			ds, err = m.FindScopeDescriptions(ctx, scope, 1, 1)
+			require.NoError(t, err)
+			require.Len(t, ds, 1)
+			assert.Equal(t, "en", ds[0].Locale)
+
+			n, err := m.CountScopeDescriptions(ctx, scope)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			// Setting the descriptions again replaces all locales of the scope.
+			require.NoError(t, m.SetScopeDescriptions(ctx, scope, []ScopeDescription{{Locale: "fr", Name: "Lire"}}))
+			ds, err = m.GetScopeDescriptions(ctx, []string{scope})
+			require.NoError(t, err)
+			require.Len(t, ds, 1)
+			assert.Equal(t, "fr", ds[0].Locale)
+
+			require.NoError(t, m.DeleteScopeDescriptions(ctx, scope))
+			n, err = m.CountScopeDescriptions(ctx, scope)
+			require.NoError(t, err)
+			assert.Equal(t, 0, n)
+
+			n, err = m.CountScopeDescriptions(ctx, other)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+		})
+
+		t.Run("case=pairwise-subject-mappings", func(t *testing.T) {
+			ctx := context.Background()
+			cl, other := uuid.New().String(), uuid.New().String()
+			expiresAt := time.Now().UTC().Add(time.Hour)
+
+			for _, mapping := range []PairwiseSubjectMapping{
+				{ClientID: cl, Subject: "alice", PreviousSubject: "alice-old", CurrentSubject: "alice-new", ExpiresAt: expiresAt},
+				{ClientID: cl, Subject: "bob", PreviousSubject: "bob-old", CurrentSubject: "bob-new", ExpiresAt: expiresAt},
+				{ClientID: cl, Subject: "carol", PreviousSubject: "carol-old", CurrentSubject: "carol-new", ExpiresAt: time.Now().UTC().Add(-time.Hour)},
+				{ClientID: other, Subject: "alice", PreviousSubject: "alice-other-old", CurrentSubject: "alice-other-new", ExpiresAt: expiresAt},
+			} {
+				mapping := mapping
+				require.NoError(t, m.CreatePairwiseSubjectMapping(ctx, &mapping))
+			}
+
+			actual, err := m.GetPairwiseSubjectMapping(ctx, cl, "alice")
+			require.NoError(t, err)
+			assert.Equal(t, "alice-old", actual.PreviousSubject)
+			assert.Equal(t, "alice-new", actual.CurrentSubject)
+
+			_, err = m.GetPairwiseSubjectMapping(ctx, cl, "carol")
+			assert.ErrorIs(t, err, x.ErrNotFound, "expired mappings must not be returned")
+
+			_, err = m.GetPairwiseSubjectMapping(ctx, cl, uuid.New().String())
+			assert.ErrorIs(t, err, x.ErrNotFound)
+
+			ms, err := m.FindPairwiseSubjectMappings(ctx, cl, 100, 0)
+			require.NoError(t, err)
+			require.Len(t, ms, 2)
+			assert.Equal(t, "alice-old", ms[0].PreviousSubject)
+			assert.Equal(t, "bob-old", ms[1].PreviousSubject)
+
+			ms, err = m.FindPairwiseSubjectMappings(ctx, cl, 1, 1)
+			require.NoError(t, err)
+			require.Len(t, ms, 1)
+			assert.Equal(t, "bob-old", ms[0].PreviousSubject)
+
+			n, err := m.CountPairwiseSubjectMappings(ctx, cl)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			// Creating a mapping for the same subject again replaces the previous one.
+			require.NoError(t, m.CreatePairwiseSubjectMapping(ctx, &PairwiseSubjectMapping{
+				ClientID: cl, Subject: "alice", PreviousSubject: "alice-new", CurrentSubject: "alice-newer", ExpiresAt: expiresAt,
+			}))
+			actual, err = m.GetPairwiseSubjectMapping(ctx, cl, "alice")
+			require.NoError(t, err)
+			assert.Equal(t, "alice-new", actual.PreviousSubject)
+			assert.Equal(t, "alice-newer", actual.CurrentSubject)
+
+			n, err = m.CountPairwiseSubjectMappings(ctx, cl)
+			require.NoError(t, err)
+			assert.Equal(t, 2, n)
+
+			n, err = m.CountPairwiseSubjectMappings(ctx, other)
+			require.NoError(t, err)
+			assert.Equal(t, 1, n)
+		})
+
 		t.Run("case=foreign key regression", func(t *testing.T) {
 			cl := &client.Client{LegacyClientID: uuid.New().String()}
 			require.NoError(t, clientManager.CreateClient(context.Background(), cl))
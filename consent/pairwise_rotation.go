@@ -0,0 +1,71 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"context"
+	"time"
+
+	"github.com/ory/hydra/v2/client"
+)
+
+// pairwiseRotationBatchSize is the number of subjects loaded at once when rotating the pairwise salt.
+const pairwiseRotationBatchSize = 500
+
+// rotatePairwiseSubjects maps the pairwise subject identifier computed with the previous salt to the
+// one computed with the current salt for every subject that has consented to a pairwise client. Until
+// expiresAt, clients keep receiving the previous identifier. It returns the number of mappings created.
+func rotatePairwiseSubjects(ctx context.Context, r InternalRegistry, previousSalt, currentSalt []byte, expiresAt time.Time) (int, error) {
+	previous := NewSubjectIdentifierAlgorithmPairwise(previousSalt)
+	current := NewSubjectIdentifierAlgorithmPairwise(currentSalt)
+
+	clients := map[string]*client.Client{}
+	var count int
+	for offset := 0; ; offset += pairwiseRotationBatchSize {
+		subjects, err := r.ConsentManager().ListPairwiseSubjects(ctx, pairwiseRotationBatchSize, offset)
+		if err != nil {
+			return count, err
+		}
+
+		for _, s := range subjects {
+			c, ok := clients[s.ClientID]
+			if !ok {
+				c, err = r.ClientManager().GetConcreteClient(ctx, s.ClientID)
+				if err != nil {
+					return count, err
+				}
+				clients[s.ClientID] = c
+			}
+
+			previousSubject, err := previous.Obfuscate(s.Subject, c)
+			if err != nil {
+				return count, err
+			}
+
+			currentSubject, err := current.Obfuscate(s.Subject, c)
+			if err != nil {
+				return count, err
+			}
+
+			if previousSubject == currentSubject {
+				continue
+			}
+
+			if err := r.ConsentManager().CreatePairwiseSubjectMapping(ctx, &PairwiseSubjectMapping{
+				ClientID:        s.ClientID,
+				Subject:         s.Subject,
+				PreviousSubject: previousSubject,
+				CurrentSubject:  currentSubject,
+				ExpiresAt:       expiresAt,
+			}); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if len(subjects) < pairwiseRotationBatchSize {
+			return count, nil
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/ory/fosite"
+	"github.com/ory/hydra/v2/x"
+	"github.com/ory/x/errorsx"
+)
+
+// PushModeLoginResponse is the response body expected from the push mode login endpoint.
+// Exactly one of Accept and Reject must be set.
+//
+// swagger:ignore
+type PushModeLoginResponse struct {
+	Accept *HandledLoginRequest `json:"accept,omitempty"`
+	Reject *RequestDeniedError  `json:"reject,omitempty"`
+}
+
+// PushModeConsentResponse is the response body expected from the push mode consent endpoint.
+// Exactly one of Accept and Reject must be set.
+//
+// swagger:ignore
+type PushModeConsentResponse struct {
+	Accept *AcceptOAuth2ConsentRequest `json:"accept,omitempty"`
+	Reject *RequestDeniedError         `json:"reject,omitempty"`
+}
+
+// pushLoginRequest sends the login request to the push mode login endpoint and applies the
+// endpoint's decision. The user agent is then redirected straight back to the authorization
+// endpoint, skipping the login UI.
+func (s *DefaultStrategy) pushLoginRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, endpoint *url.URL, challenge string) error {
+	request, err := s.r.ConsentManager().GetLoginRequest(ctx, challenge)
+	if err != nil {
+		return err
+	}
+	request.Client = sanitizeClient(request.Client)
+
+	var decision PushModeLoginResponse
+	if err := s.postPushModeRequest(ctx, endpoint, request, &decision); err != nil {
+		return err
+	}
+
+	var redirectTo string
+	switch {
+	case decision.Accept != nil && decision.Reject == nil:
+		redirectTo, err = acceptLoginRequest(ctx, s.r.ConsentManager(), challenge, decision.Accept)
+	case decision.Reject != nil && decision.Accept == nil:
+		redirectTo, err = rejectLoginRequest(ctx, s.r.ConsentManager(), challenge, decision.Reject)
+	default:
+		return errorsx.WithStack(fosite.ErrServerError.
+			WithDescription("The push mode login endpoint responded with an invalid decision.").
+			WithDebug("Exactly one of 'accept' and 'reject' must be set."))
+	}
+	if err != nil {
+		return err
+	}
+
+	s.r.AuditLogger().
+		WithRequest(r).
+		WithField("login_challenge", challenge).
+		WithField("accepted", decision.Accept != nil).
+		Info("Login request was handled by the push mode login endpoint.")
+
+	http.Redirect(w, r, redirectTo, http.StatusFound)
+	return errorsx.WithStack(ErrAbortOAuth2Request)
+}
+
+// pushConsentRequest sends the consent request to the push mode consent endpoint and applies
+// the endpoint's decision. The user agent is then redirected straight back to the authorization
+// endpoint, skipping the consent UI.
+func (s *DefaultStrategy) pushConsentRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, endpoint *url.URL, challenge string) error {
+	request, err := s.r.ConsentManager().GetConsentRequest(ctx, challenge)
+	if err != nil {
+		return err
+	}
+
+	if request.RequestedScope == nil {
+		request.RequestedScope = []string{}
+	}
+
+	if request.RequestedAudience == nil {
+		request.RequestedAudience = []string{}
+	}
+
+	var uiLocales []string
+	if request.OpenIDConnectContext != nil {
+		uiLocales = request.OpenIDConnectContext.UILocales
+	}
+	catalog, err := scopeCatalog(ctx, s.c, s.r.ConsentManager(), request.RequestedScope)
+	if err != nil {
+		return err
+	}
+	request.RequestedScopeDescriptions = localizedScopeDescriptions(catalog, request.RequestedScope, uiLocales)
+	request.Client = sanitizeClient(request.Client)
+
+	var decision PushModeConsentResponse
+	if err := s.postPushModeRequest(ctx, endpoint, request, &decision); err != nil {
+		return err
+	}
+
+	var redirectTo string
+	switch {
+	case decision.Accept != nil && decision.Reject == nil:
+		redirectTo, err = acceptConsentRequest(ctx, s.r.ConsentManager(), challenge, decision.Accept)
+	case decision.Reject != nil && decision.Accept == nil:
+		redirectTo, err = rejectConsentRequest(ctx, s.r.ConsentManager(), challenge, decision.Reject)
+	default:
+		return errorsx.WithStack(fosite.ErrServerError.
+			WithDescription("The push mode consent endpoint responded with an invalid decision.").
+			WithDebug("Exactly one of 'accept' and 'reject' must be set."))
+	}
+	if err != nil {
+		return err
+	}
+
+	s.r.AuditLogger().
+		WithRequest(r).
+		WithField("consent_challenge", challenge).
+		WithField("accepted", decision.Accept != nil).
+		Info("Consent request was handled by the push mode consent endpoint.")
+
+	http.Redirect(w, r, redirectTo, http.StatusFound)
+	return errorsx.WithStack(ErrAbortOAuth2Request)
+}
+
+func (s *DefaultStrategy) postPushModeRequest(ctx context.Context, endpoint *url.URL, in, out interface{}) error {
+	ctx, span := x.StartWebhookSpan(ctx, "push_mode", endpoint.String())
+	defer span.End()
+
+	body, err := json.Marshal(in)
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while encoding the push mode request.").
+				WithDebugf("Unable to encode the push mode request body: %s", err),
+		)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while preparing the push mode request.").
+				WithDebugf("Unable to prepare the HTTP Request: %s", err),
+		)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	start := time.Now()
+	resp, err := s.r.HTTPClient(ctx).Do(req)
+	s.r.Metrics(ctx).RecordWebhook(ctx, "push_mode", start, err)
+	if err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while executing the push mode request.").
+				WithDebugf("Unable to execute HTTP Request: %s", err),
+		)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithDescription("The push mode endpoint responded with an error.").
+				WithDebugf("Push mode endpoint responded with HTTP status code: %s", resp.Status),
+		)
+	}
+
+	d := json.NewDecoder(resp.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(out); err != nil {
+		return errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("The push mode endpoint responded with an error.").
+				WithDebugf("Response from push mode endpoint could not be decoded: %s", err),
+		)
+	}
+
+	return nil
+}
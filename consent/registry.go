@@ -6,6 +6,7 @@ package consent
 import (
 	"context"
 
+	"github.com/ory/fosite"
 	"github.com/ory/fosite/handler/openid"
 	"github.com/ory/hydra/v2/client"
 	"github.com/ory/hydra/v2/x"
@@ -16,11 +17,15 @@ type InternalRegistry interface {
 	x.RegistryCookieStore
 	x.RegistryLogger
 	x.HTTPClientProvider
+	x.MetricsProvider
+	x.PrometheusMetricsProvider
+	x.SecurityEventsProvider
 	Registry
 	client.Registry
 
 	OAuth2Storage() x.FositeStorer
 	OpenIDConnectRequestValidator() *openid.OpenIDConnectRequestValidator
+	GetJWKSFetcherStrategy() fosite.JWKSFetcherStrategy
 }
 
 type Registry interface {
@@ -0,0 +1,185 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/ory/fosite"
+	"github.com/ory/hydra/v2/x"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/httpx"
+)
+
+const (
+	riskHookStageLogin   = "login"
+	riskHookStageConsent = "consent"
+
+	RiskDecisionAllow  = "allow"
+	RiskDecisionDeny   = "deny"
+	RiskDecisionStepUp = "step_up"
+)
+
+// RiskHookRequest is the request body sent to the risk hook when a login or consent verifier
+// is redeemed.
+//
+// swagger:ignore
+type RiskHookRequest struct {
+	// Stage is either `login` or `consent`.
+	Stage string `json:"stage"`
+	// Subject is the user who is being authenticated.
+	Subject string `json:"subject"`
+	// ClientID is the identifier of the OAuth 2.0 client which started the flow.
+	ClientID string `json:"client_id"`
+	// IPAddress is the IP address of the user agent redeeming the verifier.
+	IPAddress string `json:"ip_address"`
+	// UserAgent is the user agent redeeming the verifier.
+	UserAgent string `json:"user_agent"`
+	// LoginRequest is the login request. Only set in the login stage.
+	LoginRequest *LoginRequest `json:"login_request,omitempty"`
+	// Login is the login request acceptance. Only set in the login stage.
+	Login *HandledLoginRequest `json:"login,omitempty"`
+	// ConsentRequest is the consent request. Only set in the consent stage.
+	ConsentRequest *OAuth2ConsentRequest `json:"consent_request,omitempty"`
+	// Consent is the consent request acceptance. Only set in the consent stage.
+	Consent *AcceptOAuth2ConsentRequest `json:"consent,omitempty"`
+}
+
+// RiskHookResponse is the response body expected from the risk hook.
+//
+// swagger:ignore
+type RiskHookResponse struct {
+	// Decision is one of `allow`, `deny` and `step_up`. With `step_up` the user has to log in
+	// again, which is useful to require a stronger authentication method.
+	Decision string `json:"decision"`
+	// Reason is shown to the client if the decision is `deny`.
+	Reason string `json:"reason,omitempty"`
+	// ACRValues replace the `acr_values` of the authorization request if the decision is `step_up`.
+	ACRValues []string `json:"acr_values,omitempty"`
+}
+
+// executeRiskHook asks the risk hook, if one is configured, whether the flow may continue.
+func (s *DefaultStrategy) executeRiskHook(ctx context.Context, r *http.Request, hr *RiskHookRequest) (*RiskHookResponse, error) {
+	hookURL := s.c.RiskHookURL(ctx)
+	if hookURL == nil {
+		return &RiskHookResponse{Decision: RiskDecisionAllow}, nil
+	}
+
+	ctx, span := x.StartWebhookSpan(ctx, "risk_hook", hookURL.String())
+	defer span.End()
+
+	hr.IPAddress = httpx.ClientIP(r)
+	hr.UserAgent = r.UserAgent()
+
+	body, err := json.Marshal(hr)
+	if err != nil {
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while encoding the risk hook.").
+				WithDebugf("Unable to encode the risk hook body: %s", err),
+		)
+	}
+
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, hookURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while preparing the risk hook.").
+				WithDebugf("Unable to prepare the HTTP Request: %s", err),
+		)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+
+	start := time.Now()
+	resp, err := s.r.HTTPClient(ctx).Do(req)
+	s.r.Metrics(ctx).RecordWebhook(ctx, "risk_hook", start, err)
+	if err != nil {
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("An error occurred while executing the risk hook.").
+				WithDebugf("Unable to execute HTTP Request: %s", err),
+		)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return &RiskHookResponse{Decision: RiskDecisionAllow}, nil
+	case http.StatusForbidden:
+		return &RiskHookResponse{Decision: RiskDecisionDeny}, nil
+	case http.StatusOK:
+		// Handled below.
+	default:
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithDescription("The risk hook target responded with an error.").
+				WithDebugf("Risk hook responded with HTTP status code: %s", resp.Status),
+		)
+	}
+
+	var decision RiskHookResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decision); err != nil {
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithWrap(err).
+				WithDescription("The risk hook target responded with an error.").
+				WithDebugf("Response from risk hook could not be decoded: %s", err),
+		)
+	}
+
+	switch decision.Decision {
+	case RiskDecisionAllow, RiskDecisionDeny, RiskDecisionStepUp:
+		return &decision, nil
+	default:
+		return nil, errorsx.WithStack(
+			fosite.ErrServerError.
+				WithDescription("The risk hook target responded with an invalid decision.").
+				WithDebugf("Expected one of 'allow', 'deny' and 'step_up' but got '%s'.", decision.Decision),
+		)
+	}
+}
+
+// applyRiskDecision returns nil if the flow may continue. Otherwise, it either denies the request or
+// forgets the login session and sends the user back to the login UI.
+func (s *DefaultStrategy) applyRiskDecision(ctx context.Context, w http.ResponseWriter, r *http.Request, req fosite.AuthorizeRequester, hr *RiskHookRequest, decision *RiskHookResponse) error {
+	if decision.Decision == RiskDecisionAllow {
+		return nil
+	}
+
+	s.r.AuditLogger().
+		WithRequest(r).
+		WithField("subject", hr.Subject).
+		WithField("client_id", hr.ClientID).
+		WithField("stage", hr.Stage).
+		WithField("decision", decision.Decision).
+		WithField("reason", decision.Reason).
+		Info("Risk hook did not allow the request.")
+
+	if decision.Decision == RiskDecisionDeny {
+		reason := decision.Reason
+		if reason == "" {
+			reason = "The request was denied by the risk assessment."
+		}
+		return errorsx.WithStack(fosite.ErrAccessDenied.WithHint(reason))
+	}
+
+	if len(decision.ACRValues) > 0 {
+		req.GetRequestForm().Set("acr_values", strings.Join(decision.ACRValues, " "))
+	}
+
+	if err := s.revokeAuthenticationSession(ctx, w, r); err != nil {
+		return err
+	}
+	return s.forwardAuthenticationRequest(ctx, w, r, req, "", time.Time{}, nil)
+}
@@ -5,6 +5,7 @@ package consent
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -25,6 +26,7 @@ import (
 	"github.com/ory/hydra/v2/driver/config"
 	"github.com/ory/hydra/v2/x"
 	"github.com/ory/x/errorsx"
+	"github.com/ory/x/httpx"
 	"github.com/ory/x/mapx"
 	"github.com/ory/x/sqlcon"
 	"github.com/ory/x/sqlxx"
@@ -35,6 +37,9 @@ import (
 
 const (
 	CookieAuthenticationSIDName = "sid"
+
+	// subjectLogoutBatchSize is the number of login sessions loaded at once when logging out all sessions of a subject.
+	subjectLogoutBatchSize = 500
 )
 
 type DefaultStrategy struct {
@@ -73,7 +78,16 @@ func (s *DefaultStrategy) matchesValueFromSession(ctx context.Context, c fosite.
 	}
 
 	if hintSubject != sessionSubject && hintSubject != obfuscatedUserID && hintSubject != forcedObfuscatedUserID {
-		return ErrHintDoesNotMatchAuthentication
+		// During a pairwise salt rotation both the previous and the current identifier are accepted.
+		if !s.pairwiseRotationActive(ctx, c) {
+			return ErrHintDoesNotMatchAuthentication
+		} else if m, err := s.r.ConsentManager().GetPairwiseSubjectMapping(ctx, c.GetID(), sessionSubject); errors.Is(err, x.ErrNotFound) {
+			return ErrHintDoesNotMatchAuthentication
+		} else if err != nil {
+			return err
+		} else if hintSubject != m.CurrentSubject {
+			return ErrHintDoesNotMatchAuthentication
+		}
 	}
 
 	return nil
@@ -252,6 +266,10 @@ func (s *DefaultStrategy) forwardAuthenticationRequest(ctx context.Context, w ht
 				UILocales:         stringsx.Splitx(ar.GetRequestForm().Get("ui_locales"), " "),
 				Display:           ar.GetRequestForm().Get("display"),
 				LoginHint:         ar.GetRequestForm().Get("login_hint"),
+
+				SupportedAuthenticationMethods: []string(cl.SupportedAuthenticationMethods),
+				RequiredAMR:                    []string(cl.RequiredAMR),
+				RequiredACR:                    []string(cl.RequiredACR),
 			},
 		},
 	); err != nil {
@@ -268,6 +286,10 @@ func (s *DefaultStrategy) forwardAuthenticationRequest(ctx context.Context, w ht
 		return errorsx.WithStack(err)
 	}
 
+	if endpoint := s.c.PushModeLoginEndpoint(ctx); endpoint != nil {
+		return s.pushLoginRequest(ctx, w, r, endpoint, challenge)
+	}
+
 	http.Redirect(w, r, urlx.SetQuery(s.c.LoginURL(ctx), url.Values{"login_challenge": {challenge}}).String(), http.StatusFound)
 
 	// generate the verifier
@@ -315,6 +337,10 @@ func (s *DefaultStrategy) revokeAuthenticationCookie(w http.ResponseWriter, r *h
 func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Request, req fosite.AuthorizeRequester, verifier string) (*HandledLoginRequest, error) {
 	ctx := r.Context()
 	session, err := s.r.ConsentManager().VerifyAndInvalidateLoginRequest(ctx, verifier)
+	if errors.Is(err, fosite.ErrInvalidRequest) {
+		// The verifier exists but its login request was already used.
+		s.r.PrometheusMetrics().CountChallengeReplay("login", "verifier")
+	}
 	if errors.Is(err, sqlcon.ErrNoRows) {
 		return nil, errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The login verifier has already been used, has not been granted, or is invalid."))
 	} else if err != nil {
@@ -353,6 +379,60 @@ func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Re
 		return nil, errorsx.WithStack(fosite.ErrServerError.WithHint("The login request is marked as remember, but the subject from the login confirmation does not match the original subject from the cookie."))
 	}
 
+	if session.LoginRequest.Skip {
+		remembered, err := s.r.ConsentManager().GetRememberedLoginSession(ctx, session.LoginRequest.SessionID.String())
+		if err != nil && !errors.Is(err, x.ErrNotFound) {
+			return nil, err
+		} else if err == nil && remembered.DeviceFingerprintHash != "" &&
+			subtle.ConstantTimeCompare([]byte(remembered.DeviceFingerprintHash), []byte(session.DeviceFingerprintHash)) != 1 {
+			s.r.AuditLogger().
+				WithRequest(r).
+				WithField("subject", session.Subject).
+				WithField("session_id", remembered.ID).
+				WithField("client_id", req.GetClient().GetID()).
+				Info("Remembered login session was revoked because the device fingerprint changed.")
+
+			// The remember cookie was most likely used from another device, so the session is
+			// revoked and the user has to log in again.
+			if err := s.r.ConsentManager().DeleteLoginSession(ctx, remembered.ID); err != nil && !errors.Is(err, x.ErrNotFound) {
+				return nil, err
+			}
+			if _, err := s.revokeAuthenticationCookie(w, r, store); err != nil {
+				return nil, err
+			}
+			return nil, s.forwardAuthenticationRequest(ctx, w, r, req, "", time.Time{}, nil)
+		}
+	}
+
+	missingAMR := missingRequiredAMR(req.GetClient(), session.AMR)
+	if unacceptable := unacceptableACR(req.GetClient(), session.ACR); len(missingAMR) > 0 || unacceptable {
+		s.r.AuditLogger().
+			WithRequest(r).
+			WithField("subject", session.Subject).
+			WithField("client_id", req.GetClient().GetID()).
+			WithField("missing_amr", missingAMR).
+			WithField("acr", session.ACR).
+			WithField("skip", session.LoginRequest.Skip).
+			Info("Accepted login request does not satisfy the client's required authentication methods.")
+
+		// Only a remembered login session is sent back to the login UI. The login UI received the
+		// requirements in the login request, so a fresh login which does not satisfy them would
+		// otherwise end up in a loop.
+		if !s.c.AMRPolicyReprompt(ctx) || !session.LoginRequest.Skip {
+			if len(missingAMR) > 0 {
+				return nil, errorsx.WithStack(fosite.ErrAccessDenied.WithHintf("The login request was accepted without the authentication methods required by this client: %s", strings.Join(missingAMR, ", ")))
+			}
+			return nil, errorsx.WithStack(fosite.ErrAccessDenied.WithHintf("The login request was accepted with authentication context class reference '%s' which is not accepted by this client.", session.ACR))
+		}
+
+		// The remembered login session can not be trusted for this client, so we forget it and ask
+		// the user to log in again.
+		if err := s.revokeAuthenticationSession(ctx, w, r); err != nil {
+			return nil, err
+		}
+		return nil, s.forwardAuthenticationRequest(ctx, w, r, req, "", time.Time{}, nil)
+	}
+
 	subjectIdentifier, err := s.ObfuscateSubjectIdentifier(ctx, req.GetClient(), session.Subject, session.ForceSubjectIdentifier)
 	if err != nil {
 		return nil, err
@@ -360,6 +440,26 @@ func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Re
 
 	sessionID := session.LoginRequest.SessionID.String()
 
+	if maxAge, exceeded := maxAgeExceeded(req.GetRequestForm(), time.Time(session.AuthenticatedAt), session.RequestedAt); exceeded && s.c.MaxAgePolicyStrict(ctx) {
+		authTime := time.Time(session.AuthenticatedAt).UTC()
+		s.r.AuditLogger().
+			WithRequest(r).
+			WithField("subject", session.Subject).
+			WithField("client_id", req.GetClient().GetID()).
+			WithField("auth_time", authTime).
+			WithField("requested_at", session.RequestedAt.UTC()).
+			WithField("max_age", maxAge).
+			Info("Accepted login request does not satisfy the requested max_age.")
+
+		return nil, errorsx.WithStack(fosite.ErrLoginRequired.WithHintf(
+			"The login request was accepted with auth_time '%s' which is %s older than the max_age of %d seconds allows for the authorization request registered at '%s'.",
+			authTime.Format(time.RFC3339),
+			session.RequestedAt.Sub(authTime.Add(time.Second*time.Duration(maxAge))).Round(time.Second),
+			maxAge,
+			session.RequestedAt.UTC().Format(time.RFC3339),
+		))
+	}
+
 	if err := s.r.OpenIDConnectRequestValidator().ValidatePrompt(ctx, &fosite.AuthorizeRequest{
 		ResponseTypes: req.GetResponseTypes(),
 		RedirectURI:   req.GetRedirectURI(),
@@ -397,6 +497,21 @@ func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Re
 		return nil, err
 	}
 
+	loginRequest := *session.LoginRequest
+	loginRequest.Client = sanitizeClient(loginRequest.Client)
+	riskRequest := &RiskHookRequest{
+		Stage:        riskHookStageLogin,
+		Subject:      session.Subject,
+		ClientID:     req.GetClient().GetID(),
+		LoginRequest: &loginRequest,
+		Login:        session,
+	}
+	if decision, err := s.executeRiskHook(ctx, r, riskRequest); err != nil {
+		return nil, err
+	} else if err := s.applyRiskDecision(ctx, w, r, req, riskRequest, decision); err != nil {
+		return nil, err
+	}
+
 	if session.ForceSubjectIdentifier != "" {
 		if err := s.r.ConsentManager().CreateForcedObfuscatedLoginSession(r.Context(), &ForcedObfuscatedLoginSession{
 			Subject:           session.Subject,
@@ -412,9 +527,41 @@ func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Re
 			return nil, errorsx.WithStack(fosite.ErrServerError.WithHint("Expected the handled login request to contain a valid authenticated_at value but it was zero. This is a bug which should be reported to https://github.com/ory/hydra."))
 		}
 
-		if err := s.r.ConsentManager().ConfirmLoginSession(r.Context(), sessionID, time.Time(session.AuthenticatedAt), session.Subject, session.Remember); err != nil {
+		loginSession := &LoginSession{
+			ID:                    sessionID,
+			AuthenticatedAt:       session.AuthenticatedAt,
+			Subject:               session.Subject,
+			Remember:              session.Remember,
+			IPAddress:             httpx.ClientIP(r),
+			UserAgentHash:         userAgentHash(r),
+			DeviceID:              session.DeviceID,
+			DeviceFingerprintHash: session.DeviceFingerprintHash,
+		}
+		if err := s.r.ConsentManager().ConfirmLoginSession(r.Context(), loginSession); err != nil {
 			return nil, err
 		}
+
+		s.r.AuditLogger().
+			WithRequest(r).
+			WithField("subject", session.Subject).
+			WithField("session_id", sessionID).
+			WithField("client_id", req.GetClient().GetID()).
+			WithField("ip_address", loginSession.IPAddress).
+			WithField("user_agent_hash", loginSession.UserAgentHash).
+			WithField("device_id", loginSession.DeviceID).
+			Info("Login session was confirmed.")
+
+		s.r.SecurityEvents().Emit(r, x.SecurityEvent{
+			Type:     x.SecurityEventLoginSessionConfirmed,
+			Severity: x.SecuritySeverityLow,
+			ClientID: req.GetClient().GetID(),
+			Subject:  session.Subject,
+			Details: map[string]string{
+				"session_id":      sessionID,
+				"user_agent_hash": loginSession.UserAgentHash,
+				"device_id":       loginSession.DeviceID,
+			},
+		})
 	}
 
 	if !session.Remember && !session.LoginRequest.Skip {
@@ -459,7 +606,7 @@ func (s *DefaultStrategy) verifyAuthentication(w http.ResponseWriter, r *http.Re
 func (s *DefaultStrategy) requestConsent(ctx context.Context, w http.ResponseWriter, r *http.Request, ar fosite.AuthorizeRequester, authenticationSession *HandledLoginRequest) error {
 	prompt := stringsx.Splitx(ar.GetRequestForm().Get("prompt"), " ")
 	if stringslice.Has(prompt, "consent") {
-		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil)
+		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil, nil)
 	}
 
 	// https://tools.ietf.org/html/rfc6749
@@ -483,7 +630,7 @@ func (s *DefaultStrategy) requestConsent(ctx context.Context, w http.ResponseWri
 		// This is tracked as issue: https://github.com/ory/hydra/issues/866
 		// This is also tracked as upstream issue: https://github.com/openid-certification/oidctest/issues/97
 		if !(ar.GetRedirectURI().Scheme == "https" || (fosite.IsLocalhost(ar.GetRedirectURI()) && ar.GetRedirectURI().Scheme == "http")) {
-			return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil)
+			return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil, nil)
 		}
 	}
 
@@ -496,19 +643,30 @@ func (s *DefaultStrategy) requestConsent(ctx context.Context, w http.ResponseWri
 
 	consentSessions, err := s.r.ConsentManager().FindGrantedAndRememberedConsentRequests(r.Context(), ar.GetClient().GetID(), authenticationSession.Subject)
 	if errors.Is(err, ErrNoPreviousConsentFound) {
-		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil)
+		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil, nil)
 	} else if err != nil {
 		return err
 	}
 
 	if found := matchScopes(s.r.Config().GetScopeStrategy(ctx), consentSessions, ar.GetRequestedScopes()); found != nil {
-		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, found)
+		return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, found, nil)
+	}
+
+	if s.c.ConsentScopeMergePolicy(ctx) == config.ConsentScopeMergeIncremental {
+		// Only ask for the scopes which have not been granted before, they are merged back in once
+		// the consent request was accepted.
+		granted := previouslyGrantedScopes(s.r.Config().GetScopeStrategy(ctx), consentSessions, ar.GetRequestedScopes())
+		if len(granted) > 0 {
+			return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil, granted)
+		}
 	}
 
-	return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil)
+	return s.forwardConsentRequest(ctx, w, r, ar, authenticationSession, nil, nil)
 }
 
-func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, ar fosite.AuthorizeRequester, as *HandledLoginRequest, cs *AcceptOAuth2ConsentRequest) error {
+// forwardConsentRequest redirects the user agent to the consent app. If cs is set, the consent
+// request can be skipped. Scopes listed in previouslyGranted are not requested again.
+func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, ar fosite.AuthorizeRequester, as *HandledLoginRequest, cs *AcceptOAuth2ConsentRequest, previouslyGranted []string) error {
 	skip := false
 	if cs != nil {
 		skip = true
@@ -524,6 +682,13 @@ func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.Resp
 	challenge := strings.Replace(uuid.New(), "-", "", -1)
 	csrf := strings.Replace(uuid.New(), "-", "", -1)
 
+	requestedScope := []string{}
+	for _, scope := range ar.GetRequestedScopes() {
+		if !stringslice.Has(previouslyGranted, scope) {
+			requestedScope = append(requestedScope, scope)
+		}
+	}
+
 	cl := sanitizeClientFromRequest(ar)
 	if err := s.r.ConsentManager().CreateConsentRequest(
 		r.Context(),
@@ -534,7 +699,7 @@ func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.Resp
 			Verifier:               verifier,
 			CSRF:                   csrf,
 			Skip:                   skip,
-			RequestedScope:         []string(ar.GetRequestedScopes()),
+			RequestedScope:         requestedScope,
 			RequestedAudience:      []string(ar.GetRequestedAudience()),
 			Subject:                as.Subject,
 			Client:                 cl,
@@ -561,6 +726,10 @@ func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.Resp
 		return errorsx.WithStack(err)
 	}
 
+	if endpoint := s.c.PushModeConsentEndpoint(ctx); endpoint != nil {
+		return s.pushConsentRequest(ctx, w, r, endpoint, challenge)
+	}
+
 	http.Redirect(
 		w, r,
 		urlx.SetQuery(s.c.ConsentURL(ctx), url.Values{"consent_challenge": {challenge}}).String(),
@@ -573,6 +742,10 @@ func (s *DefaultStrategy) forwardConsentRequest(ctx context.Context, w http.Resp
 
 func (s *DefaultStrategy) verifyConsent(ctx context.Context, w http.ResponseWriter, r *http.Request, req fosite.AuthorizeRequester, verifier string) (*AcceptOAuth2ConsentRequest, error) {
 	session, err := s.r.ConsentManager().VerifyAndInvalidateConsentRequest(r.Context(), verifier)
+	if errors.Is(err, fosite.ErrInvalidRequest) {
+		// The verifier exists but its consent request was already used.
+		s.r.PrometheusMetrics().CountChallengeReplay("consent", "verifier")
+	}
 	if errors.Is(err, sqlcon.ErrNoRows) {
 		return nil, errorsx.WithStack(fosite.ErrAccessDenied.WithHint("The consent verifier has already been used, has not been granted, or is invalid."))
 	} else if err != nil {
@@ -614,10 +787,81 @@ func (s *DefaultStrategy) verifyConsent(ctx context.Context, w http.ResponseWrit
 		session.Session.IDToken = map[string]interface{}{}
 	}
 
+	granted, err := s.mergePreviouslyGrantedScopes(ctx, req, session)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged []string
+	for _, scope := range granted {
+		if !stringslice.Has(session.GrantedScope, scope) {
+			merged = append(merged, scope)
+		}
+	}
+	if len(merged) > 0 {
+		if err := s.r.ConsentManager().CreateConsentDecision(ctx, newAutomaticConsentDecision(session.ConsentRequest, merged)); err != nil {
+			return nil, err
+		}
+	}
+	session.GrantedScope = granted
+
+	consentRequest := *session.ConsentRequest
+	consentRequest.Client = sanitizeClient(consentRequest.Client)
+	riskRequest := &RiskHookRequest{
+		Stage:          riskHookStageConsent,
+		Subject:        session.ConsentRequest.Subject,
+		ClientID:       req.GetClient().GetID(),
+		ConsentRequest: &consentRequest,
+		Consent:        session,
+	}
+	if decision, err := s.executeRiskHook(ctx, r, riskRequest); err != nil {
+		return nil, err
+	} else if err := s.applyRiskDecision(ctx, w, r, req, riskRequest, decision); err != nil {
+		return nil, err
+	}
+
 	session.AuthenticatedAt = session.ConsentRequest.AuthenticatedAt
 	return session, nil
 }
 
+// mergePreviouslyGrantedScopes applies the configured scope merge policy to the scopes granted
+// by the consent app. Previously granted scopes are only merged in if they were requested again
+// and the client is still allowed to request them.
+func (s *DefaultStrategy) mergePreviouslyGrantedScopes(ctx context.Context, req fosite.AuthorizeRequester, session *AcceptOAuth2ConsentRequest) ([]string, error) {
+	strategy := s.r.Config().GetScopeStrategy(ctx)
+	mergeable := func(scope string) bool {
+		return strategy(req.GetRequestedScopes(), scope) && strategy(req.GetClient().GetScopes(), scope)
+	}
+
+	granted := []string(session.GrantedScope)
+	switch s.c.ConsentScopeMergePolicy(ctx) {
+	case config.ConsentScopeMergeIncremental:
+		// Scopes that were requested by the client but not by the consent request have been
+		// granted previously.
+		for _, scope := range req.GetRequestedScopes() {
+			if !stringslice.Has(session.ConsentRequest.RequestedScope, scope) && !stringslice.Has(granted, scope) && mergeable(scope) {
+				granted = append(granted, scope)
+			}
+		}
+	case config.ConsentScopeMergeUnion:
+		previous, err := s.r.ConsentManager().FindGrantedAndRememberedConsentRequests(ctx, req.GetClient().GetID(), session.ConsentRequest.Subject)
+		if errors.Is(err, ErrNoPreviousConsentFound) {
+			return granted, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, p := range previous {
+			for _, scope := range p.GrantedScope {
+				if !stringslice.Has(granted, scope) && mergeable(scope) {
+					granted = append(granted, scope)
+				}
+			}
+		}
+	}
+	return granted, nil
+}
+
 func (s *DefaultStrategy) generateFrontChannelLogoutURLs(ctx context.Context, subject, sid string) ([]string, error) {
 	clients, err := s.r.ConsentManager().ListUserAuthenticatedClientsWithFrontChannelLogout(ctx, subject, sid)
 	if err != nil {
@@ -641,9 +885,46 @@ func (s *DefaultStrategy) generateFrontChannelLogoutURLs(ctx context.Context, su
 }
 
 func (s *DefaultStrategy) executeBackChannelLogout(ctx context.Context, r *http.Request, subject, sid string) error {
-	clients, err := s.r.ConsentManager().ListUserAuthenticatedClientsWithBackChannelLogout(ctx, subject, sid)
-	if err != nil {
-		return err
+	return s.executeBackChannelLogoutForSessions(ctx, r, subject, []string{sid})
+}
+
+// executeBackChannelLogoutForSessions notifies all clients the subject authenticated with in any of the given
+// sessions. Clients with subject-wide back-channel logout receive a single sid-less logout token, all other
+// clients receive one logout token per session.
+func (s *DefaultStrategy) executeBackChannelLogoutForSessions(ctx context.Context, r *http.Request, subject string, sids []string) error {
+	type recipient struct {
+		client client.Client
+		sids   []string
+	}
+
+	var recipients []*recipient
+	byClient := map[string]*recipient{}
+	for start := 0; start < len(sids); start += subjectLogoutBatchSize {
+		end := start + subjectLogoutBatchSize
+		if end > len(sids) {
+			end = len(sids)
+		}
+
+		clientsBySession, err := s.r.ConsentManager().ListUserAuthenticatedClientsWithBackChannelLogoutForSessions(ctx, subject, sids[start:end])
+		if err != nil {
+			return err
+		}
+
+		for _, sid := range sids[start:end] {
+			for _, c := range clientsBySession[sid] {
+				rc, ok := byClient[c.GetID()]
+				if !ok {
+					rc = &recipient{client: c}
+					byClient[c.GetID()] = rc
+					recipients = append(recipients, rc)
+				}
+				rc.sids = append(rc.sids, sid)
+			}
+		}
+	}
+
+	if len(recipients) == 0 {
+		return nil
 	}
 
 	openIDKeyID, err := s.r.OpenIDJWTStrategy().GetPublicKeyID(ctx)
@@ -657,30 +938,54 @@ func (s *DefaultStrategy) executeBackChannelLogout(ctx context.Context, r *http.
 		clientID string
 	}
 
-	var tasks []task
-	for _, c := range clients {
-		// Getting the forced obfuscated login session is tricky because the user id could be obfuscated with a new
-		// ID every time the algorithm is used. Thus, we would only get the most recent version. It therefore makes
-		// sense to just use the sid.
-		//
-		// s.r.ConsentManager().GetForcedObfuscatedLoginSession(context.Background(), subject, <missing>)
-		// sub := s.obfuscateSubjectIdentifier(c, subject, )
-
-		t, _, err := s.r.OpenIDJWTStrategy().Generate(ctx, jwt.MapClaims{
-			"iss":    s.c.IssuerURL(ctx).String(),
-			"aud":    []string{c.LegacyClientID},
-			"iat":    time.Now().UTC().Unix(),
-			"jti":    uuid.New(),
-			"events": map[string]struct{}{"http://schemas.openid.net/event/backchannel-logout": {}},
-			"sid":    sid,
-		}, &jwt.Headers{
+	generate := func(c client.Client, claims jwt.MapClaims) (string, error) {
+		claims["iss"] = s.c.IssuerURL(ctx).String()
+		claims["aud"] = []string{c.LegacyClientID}
+		claims["iat"] = time.Now().UTC().Unix()
+		claims["jti"] = uuid.New()
+		claims["events"] = map[string]struct{}{"http://schemas.openid.net/event/backchannel-logout": {}}
+
+		t, _, err := s.r.OpenIDJWTStrategy().Generate(ctx, claims, &jwt.Headers{
 			Extra: map[string]interface{}{"kid": openIDKeyID},
 		})
-		if err != nil {
-			return err
+		return t, err
+	}
+
+	var tasks []task
+	for _, rc := range recipients {
+		c := rc.client
+
+		if c.BackChannelLogoutSubjectWide {
+			// A forced obfuscated subject only exists per login session, so subject-wide logout tokens carry the
+			// subject identifier as computed by the client's subject type.
+			sub, err := s.ObfuscateSubjectIdentifier(ctx, &c, subject, "")
+			if err != nil {
+				return err
+			}
+
+			t, err := generate(c, jwt.MapClaims{"sub": sub})
+			if err != nil {
+				return err
+			}
+
+			tasks = append(tasks, task{url: c.BackChannelLogoutURI, clientID: c.GetID(), token: t})
+			continue
 		}
 
-		tasks = append(tasks, task{url: c.BackChannelLogoutURI, clientID: c.GetID(), token: t})
+		for _, sid := range rc.sids {
+			// Getting the forced obfuscated login session is tricky because the user id could be obfuscated with a new
+			// ID every time the algorithm is used. Thus, we would only get the most recent version. It therefore makes
+			// sense to just use the sid.
+			//
+			// s.r.ConsentManager().GetForcedObfuscatedLoginSession(context.Background(), subject, <missing>)
+			// sub := s.obfuscateSubjectIdentifier(c, subject, )
+			t, err := generate(c, jwt.MapClaims{"sid": sid})
+			if err != nil {
+				return err
+			}
+
+			tasks = append(tasks, task{url: c.BackChannelLogoutURI, clientID: c.GetID(), token: t})
+		}
 	}
 
 	var execute = func(t task) {
@@ -843,13 +1148,14 @@ func (s *DefaultStrategy) issueLogoutVerifier(ctx context.Context, w http.Respon
 	if len(requestedRedir) > 0 {
 		var f *url.URL
 		for _, w := range cl.PostLogoutRedirectURIs {
-			if w == requestedRedir {
-				u, err := url.Parse(w)
+			if client.MatchPostLogoutRedirectURI(w, requestedRedir) {
+				u, err := url.Parse(requestedRedir)
 				if err != nil {
-					return nil, errorsx.WithStack(fosite.ErrServerError.WithHintf("Unable to parse post_logout_redirect_uri '%s'.", w).WithDebug(err.Error()))
+					return nil, errorsx.WithStack(fosite.ErrServerError.WithHintf("Unable to parse post_logout_redirect_uri '%s'.", requestedRedir).WithDebug(err.Error()))
 				}
 
 				f = u
+				break
 			}
 		}
 
@@ -895,6 +1201,21 @@ func (s *DefaultStrategy) issueLogoutVerifier(ctx context.Context, w http.Respon
 		return nil, err
 	}
 
+	if !s.c.LogoutConfirmationRequired(ctx) {
+		// The id_token_hint identifies the session, so we skip the logout UI and complete the logout right away.
+		lr, err := s.r.ConsentManager().AcceptLogoutRequest(r.Context(), challenge)
+		if err != nil {
+			return nil, err
+		}
+
+		s.r.AuditLogger().
+			WithRequest(r).
+			WithField("subject", lr.Subject).
+			Info("User logout does not require confirmation because a valid id_token_hint was given.")
+		http.Redirect(w, r, urlx.SetQuery(urlx.AppendPaths(s.c.PublicURL(ctx), "/oauth2/sessions/logout"), url.Values{"logout_verifier": {lr.Verifier}}).String(), http.StatusFound)
+		return nil, errorsx.WithStack(ErrAbortOAuth2Request)
+	}
+
 	http.Redirect(w, r, urlx.SetQuery(s.c.LogoutURL(ctx), url.Values{"logout_challenge": {challenge}}).String(), http.StatusFound)
 	return nil, errorsx.WithStack(ErrAbortOAuth2Request)
 }
@@ -955,6 +1276,15 @@ func (s *DefaultStrategy) completeLogout(ctx context.Context, w http.ResponseWri
 		}
 	}
 
+	if err := s.executePreLogoutHook(ctx, lr); err != nil {
+		s.r.AuditLogger().
+			WithRequest(r).
+			WithError(err).
+			WithField("subject", lr.Subject).
+			Info("User logout was aborted by the pre-logout hook.")
+		return nil, err
+	}
+
 	store, err := s.r.CookieStore(ctx)
 	if err != nil {
 		return nil, err
@@ -1016,6 +1346,42 @@ func (s *DefaultStrategy) HandleHeadlessLogout(ctx context.Context, w http.Respo
 	return nil
 }
 
+func (s *DefaultStrategy) HandleHeadlessSubjectLogout(ctx context.Context, w http.ResponseWriter, r *http.Request, subject string) error {
+	var sids []string
+	for offset := 0; ; offset += subjectLogoutBatchSize {
+		sessions, err := s.r.ConsentManager().FindSubjectsLoginSessions(ctx, subject, subjectLogoutBatchSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, ls := range sessions {
+			sids = append(sids, ls.ID)
+		}
+
+		if len(sessions) < subjectLogoutBatchSize {
+			break
+		}
+	}
+
+	// Back-channel logout has to happen before the sessions are revoked, as the sessions are needed to find
+	// the clients to notify.
+	if err := s.executeBackChannelLogoutForSessions(ctx, r, subject, sids); err != nil {
+		return err
+	}
+
+	if err := s.r.ConsentManager().RevokeSubjectLoginSession(ctx, subject); err != nil {
+		return err
+	}
+
+	s.r.AuditLogger().
+		WithRequest(r).
+		WithField("subject", subject).
+		WithField("sessions", len(sids)).
+		Info("User logout of all sessions completed via headless flow!")
+
+	return nil
+}
+
 func (s *DefaultStrategy) HandleOAuth2AuthorizationRequest(ctx context.Context, w http.ResponseWriter, r *http.Request, req fosite.AuthorizeRequester) (*AcceptOAuth2ConsentRequest, error) {
 	authenticationVerifier := strings.TrimSpace(req.GetRequestForm().Get("login_verifier"))
 	consentVerifier := strings.TrimSpace(req.GetRequestForm().Get("consent_verifier"))
@@ -1051,9 +1417,26 @@ func (s *DefaultStrategy) ObfuscateSubjectIdentifier(ctx context.Context, cl fos
 			return forcedIdentifier, nil
 		}
 
+		// While the pairwise salt is being rotated, the client keeps receiving the previous identifier.
+		if s.pairwiseRotationActive(ctx, c) {
+			if m, err := s.r.ConsentManager().GetPairwiseSubjectMapping(ctx, c.GetID(), subject); err == nil {
+				return m.PreviousSubject, nil
+			} else if !errors.Is(err, x.ErrNotFound) {
+				return "", err
+			}
+		}
+
 		return algorithm.Obfuscate(subject, c)
 	} else if !ok {
 		return "", errors.New("Unable to type assert OAuth 2.0 Client to *client.Client")
 	}
 	return subject, nil
 }
+
+// pairwiseRotationActive reports whether pairwise subject mappings need to be looked up for the
+// client. Mappings only exist while a salt rotation is in progress, which requires the previous
+// salt to be configured, so the lookup is skipped otherwise.
+func (s *DefaultStrategy) pairwiseRotationActive(ctx context.Context, cl fosite.Client) bool {
+	c, ok := cl.(*client.Client)
+	return ok && c.SubjectType == "pairwise" && s.c.SubjectIdentifierAlgorithmPreviousSalt(ctx) != ""
+}
@@ -60,6 +60,16 @@ func checkAndAcceptConsentHandler(t *testing.T, apiClient *hydra.APIClient, cb f
 	}
 }
 
+// getRawLoginRequest fetches the login request from the admin API as JSON, which includes fields
+// the SDK does not know about yet.
+func getRawLoginRequest(t *testing.T, adminURL, challenge string) gjson.Result {
+	res, err := http.Get(adminURL + "/admin" + LoginPath + "?" + url.Values{"login_challenge": {challenge}}.Encode())
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	return gjson.ParseBytes(ioutilx.MustReadAll(res.Body))
+}
+
 func makeOAuth2Request(t *testing.T, reg driver.Registry, hc *http.Client, oc *client.Client, values url.Values) (gjson.Result, *http.Response) {
 	ctx := context.Background()
 	if hc == nil {
@@ -13,11 +13,14 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/ory/x/pointerx"
 
+	"github.com/pborman/uuid"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
@@ -537,6 +540,62 @@ func TestLogoutFlows(t *testing.T) {
 		backChannelWG.Wait() // we want to ensure that all back channels have been called!
 	})
 
+	t.Run("case=should execute backchannel logout in headless flow with subject if requested", func(t *testing.T) {
+		subject := uuid.New()
+		sid := make(chan string, 2)
+		acceptLoginAsAndWatchSidForConsumers(t, subject, sid, true, 1)
+
+		var mu sync.Mutex
+		var received []string
+		backChannelWG := newWg(2)
+		c := createClientWithBackchannelLogout(t, backChannelWG, func(t *testing.T, logoutToken gjson.Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, logoutToken.Get("sid").String())
+		})
+
+		// Two browsers result in two login sessions of the same subject.
+		createBrowserWithSession(t, c)
+		createBrowserWithSession(t, c)
+		expected := []string{<-sid, <-sid}
+
+		logoutViaHeadlessAndExpectNoContent(t, browserWithoutSession, url.Values{"subject": {subject}, "back_channel_logout": {"true"}})
+
+		backChannelWG.Wait() // we want to ensure that all back channels have been called!
+		mu.Lock()
+		defer mu.Unlock()
+		assert.ElementsMatch(t, expected, received)
+
+		sessions, err := reg.ConsentManager().FindSubjectsLoginSessions(ctx, subject, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+	})
+
+	t.Run("case=should not execute backchannel logout in headless flow with subject by default", func(t *testing.T) {
+		subject := uuid.New()
+		acceptLoginAs(t, subject)
+
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		}))
+		t.Cleanup(server.Close)
+
+		c := createClient(t, reg, &client.Client{
+			BackChannelLogoutURI: server.URL,
+			RedirectURIs:         []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+		})
+		createBrowserWithSession(t, c)
+
+		logoutViaHeadlessAndExpectNoContent(t, browserWithoutSession, url.Values{"subject": {subject}})
+
+		sessions, err := reg.ConsentManager().FindSubjectsLoginSessions(ctx, subject, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, sessions)
+
+		assert.Never(t, func() bool { return atomic.LoadInt32(&calls) > 0 }, time.Second, 50*time.Millisecond)
+	})
+
 	t.Run("case=should logout in headless flow with non-existing sid", func(t *testing.T) {
 		logoutViaHeadlessAndExpectNoContent(t, browserWithoutSession, url.Values{"sid": {"non-existing-sid"}})
 	})
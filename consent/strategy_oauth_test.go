@@ -9,8 +9,12 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,8 +41,10 @@ import (
 
 	hydra "github.com/ory/hydra-client-go/v2"
 	"github.com/ory/hydra/v2/client"
+	"github.com/ory/hydra/v2/consent"
 	"github.com/ory/hydra/v2/driver/config"
 	"github.com/ory/hydra/v2/internal"
+	"github.com/ory/hydra/v2/x"
 )
 
 func TestStrategyLoginConsentNext(t *testing.T) {
@@ -200,6 +206,174 @@ func TestStrategyLoginConsentNext(t *testing.T) {
 		makeRequestAndExpectCode(t, nil, c, url.Values{})
 	})
 
+	t.Run("case=should pass without login and consent UI if push mode endpoints accept the requests", func(t *testing.T) {
+		c := createDefaultClient(t)
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			testhelpers.HTTPServerNoExpectedCallHandler(t),
+			testhelpers.HTTPServerNoExpectedCallHandler(t))
+
+		login := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var lr consent.OAuth2LoginRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&lr))
+			assert.Equal(t, c.GetID(), lr.Client.GetID())
+			require.NoError(t, json.NewEncoder(w).Encode(&consent.PushModeLoginResponse{Accept: &consent.HandledLoginRequest{Subject: "aeneas-rekkas"}}))
+		}))
+		t.Cleanup(login.Close)
+
+		consentTS := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var cr consent.OAuth2ConsentRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&cr))
+			assert.Equal(t, "aeneas-rekkas", cr.Subject)
+			require.NoError(t, json.NewEncoder(w).Encode(&consent.PushModeConsentResponse{Accept: &consent.AcceptOAuth2ConsentRequest{}}))
+		}))
+		t.Cleanup(consentTS.Close)
+
+		reg.Config().MustSet(ctx, config.KeyPushModeLoginEndpoint, login.URL)
+		reg.Config().MustSet(ctx, config.KeyPushModeConsentEndpoint, consentTS.URL)
+		defer reg.Config().MustSet(ctx, config.KeyPushModeLoginEndpoint, nil)
+		defer reg.Config().MustSet(ctx, config.KeyPushModeConsentEndpoint, nil)
+
+		makeRequestAndExpectCode(t, nil, c, url.Values{})
+	})
+
+	t.Run("case=should fail if the push mode login endpoint rejects the request", func(t *testing.T) {
+		c := createDefaultClient(t)
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			testhelpers.HTTPServerNoExpectedCallHandler(t),
+			testhelpers.HTTPServerNoExpectedCallHandler(t))
+
+		login := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewEncoder(w).Encode(&consent.PushModeLoginResponse{Reject: &consent.RequestDeniedError{Description: "expect-reject-push-login"}}))
+		}))
+		t.Cleanup(login.Close)
+
+		reg.Config().MustSet(ctx, config.KeyPushModeLoginEndpoint, login.URL)
+		defer reg.Config().MustSet(ctx, config.KeyPushModeLoginEndpoint, nil)
+
+		makeRequestAndExpectError(t, nil, c, url.Values{}, "expect-reject-push-login")
+	})
+
+	t.Run("case=should fail if the risk hook denies the consent", func(t *testing.T) {
+		c := createDefaultClient(t)
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			acceptLoginHandler(t, "aeneas-rekkas", nil),
+			acceptConsentHandler(t, nil))
+
+		var stages []string
+		hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var hr consent.RiskHookRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&hr))
+			assert.Equal(t, "aeneas-rekkas", hr.Subject)
+			assert.Equal(t, c.GetID(), hr.ClientID)
+			stages = append(stages, hr.Stage)
+
+			if hr.Stage == "login" {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(&consent.RiskHookResponse{Decision: consent.RiskDecisionDeny, Reason: "expect-risk-deny"}))
+		}))
+		t.Cleanup(hook.Close)
+
+		reg.Config().MustSet(ctx, config.KeyRiskHookURL, hook.URL)
+		defer reg.Config().MustSet(ctx, config.KeyRiskHookURL, nil)
+
+		makeRequestAndExpectError(t, nil, c, url.Values{}, "expect-risk-deny")
+		assert.Equal(t, []string{"login", "consent"}, stages)
+	})
+
+	t.Run("case=should fail if the accepted login does not satisfy the client's required amr", func(t *testing.T) {
+		c := createClient(t, reg, &client.Client{
+			RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+			RequiredAMR:  []string{"mfa"},
+		})
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(t *testing.T, res *hydra.OAuth2LoginRequest, err error) hydra.AcceptOAuth2LoginRequest {
+				require.NoError(t, err)
+				raw := getRawLoginRequest(t, adminTS.URL, res.Challenge)
+				assert.Equal(t, []interface{}{"mfa"}, raw.Get("oidc_context.required_amr").Value(), "%s", raw.Raw)
+				return hydra.AcceptOAuth2LoginRequest{Amr: []string{"pwd"}}
+			}),
+			testhelpers.HTTPServerNoExpectedCallHandler(t))
+
+		makeRequestAndExpectError(t, nil, c, url.Values{}, "accepted without the authentication methods required by this client: mfa")
+	})
+
+	t.Run("case=should fail if the accepted login does not satisfy the client's required acr", func(t *testing.T) {
+		c := createClient(t, reg, &client.Client{
+			RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+			RequiredACR:  []string{"gold"},
+		})
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(t *testing.T, res *hydra.OAuth2LoginRequest, err error) hydra.AcceptOAuth2LoginRequest {
+				require.NoError(t, err)
+				raw := getRawLoginRequest(t, adminTS.URL, res.Challenge)
+				assert.Equal(t, []interface{}{"gold"}, raw.Get("oidc_context.required_acr").Value(), "%s", raw.Raw)
+				return hydra.AcceptOAuth2LoginRequest{Acr: pointerx.String("silver")}
+			}),
+			testhelpers.HTTPServerNoExpectedCallHandler(t))
+
+		makeRequestAndExpectError(t, nil, c, url.Values{}, "authentication context class reference 'silver' which is not accepted by this client")
+	})
+
+	t.Run("case=should reprompt a remembered login which does not satisfy the client's required amr", func(t *testing.T) {
+		reg.Config().MustSet(ctx, config.KeyAMRPolicyOnViolation, "reprompt")
+		defer reg.Config().MustSet(ctx, config.KeyAMRPolicyOnViolation, "reject")
+
+		subject := "aeneas-rekkas"
+		hc := testhelpers.NewEmptyJarClient(t)
+
+		// Remember a password-only login with a client without requirements.
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			acceptLoginHandler(t, subject, &hydra.AcceptOAuth2LoginRequest{Remember: pointerx.Bool(true), Amr: []string{"pwd"}}),
+			acceptConsentHandler(t, nil))
+		makeRequestAndExpectCode(t, hc, createDefaultClient(t), url.Values{})
+
+		c := createClient(t, reg, &client.Client{
+			RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+			RequiredAMR:  []string{"mfa"},
+		})
+
+		var skipped, prompted int
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			checkAndAcceptLoginHandler(t, adminClient, subject, func(t *testing.T, res *hydra.OAuth2LoginRequest, err error) hydra.AcceptOAuth2LoginRequest {
+				require.NoError(t, err)
+				if res.Skip {
+					skipped++
+					return hydra.AcceptOAuth2LoginRequest{Amr: []string{"pwd"}}
+				}
+				prompted++
+				return hydra.AcceptOAuth2LoginRequest{Amr: []string{"pwd", "mfa"}}
+			}),
+			acceptConsentHandler(t, nil))
+
+		makeRequestAndExpectCode(t, hc, c, url.Values{})
+		assert.Equal(t, 1, skipped)
+		assert.Equal(t, 1, prompted)
+	})
+
+	t.Run("case=should not reprompt a fresh login which does not satisfy the client's required amr", func(t *testing.T) {
+		reg.Config().MustSet(ctx, config.KeyAMRPolicyOnViolation, "reprompt")
+		defer reg.Config().MustSet(ctx, config.KeyAMRPolicyOnViolation, "reject")
+
+		c := createClient(t, reg, &client.Client{
+			RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+			RequiredAMR:  []string{"mfa"},
+		})
+
+		var prompted int
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(t *testing.T, res *hydra.OAuth2LoginRequest, err error) hydra.AcceptOAuth2LoginRequest {
+				require.NoError(t, err)
+				prompted++
+				return hydra.AcceptOAuth2LoginRequest{Amr: []string{"pwd"}}
+			}),
+			testhelpers.HTTPServerNoExpectedCallHandler(t))
+
+		makeRequestAndExpectError(t, nil, c, url.Values{}, "accepted without the authentication methods required by this client: mfa")
+		assert.Equal(t, 1, prompted)
+	})
+
 	t.Run("case=should pass if both login and consent are granted and check remember flows as well as various payloads", func(t *testing.T) {
 		// Covers old test cases:
 		// - This should pass because login and consent have been granted, this time we remember the decision
@@ -1017,3 +1191,303 @@ func TestStrategyLoginConsentNext(t *testing.T) {
 		makeRequestAndExpectCode(t, hc, c, url.Values{"redirect_uri": {c.RedirectURIs[0]}})
 	})
 }
+
+func TestStrategyLoginSessionSecurityEvent(t *testing.T) {
+	ctx := context.Background()
+	received := make(chan []byte, 10)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(sink.Close)
+
+	reg := internal.NewMockedRegistry(t, &contextx.Default{})
+	reg.Config().MustSet(ctx, config.KeySecurityEventSinks, []map[string]interface{}{{"type": "http", "url": sink.URL}})
+	reg.Config().MustSet(ctx, config.KeyConsentRequestMaxAge, time.Hour)
+
+	_, adminTS := testhelpers.NewOAuth2Server(ctx, t, reg)
+	adminClient := hydra.NewAPIClient(hydra.NewConfiguration())
+	adminClient.GetConfig().Servers = hydra.ServerConfigurations{{URL: adminTS.URL}}
+
+	c := createClient(t, reg, &client.Client{RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)}})
+	testhelpers.NewLoginConsentUI(t, reg.Config(),
+		checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(*testing.T, *hydra.OAuth2LoginRequest, error) hydra.AcceptOAuth2LoginRequest {
+			return hydra.AcceptOAuth2LoginRequest{}
+		}),
+		checkAndAcceptConsentHandler(t, adminClient, func(*testing.T, *hydra.OAuth2ConsentRequest, error) hydra.AcceptOAuth2ConsentRequest {
+			return hydra.AcceptOAuth2ConsentRequest{}
+		}))
+
+	_, res := makeOAuth2Request(t, reg, nil, c, url.Values{})
+	require.NotEmpty(t, res.Request.URL.Query().Get("code"), "%s", res.Request.URL)
+
+	for {
+		select {
+		case body := <-received:
+			if gjson.GetBytes(body, "type").String() != x.SecurityEventLoginSessionConfirmed {
+				continue
+			}
+			assert.Equal(t, "aeneas-rekkas", gjson.GetBytes(body, "subject").String(), "%s", body)
+			assert.Equal(t, c.GetID(), gjson.GetBytes(body, "client_id").String(), "%s", body)
+			assert.NotEmpty(t, gjson.GetBytes(body, "ip_address").String(), "%s", body)
+			assert.NotEmpty(t, gjson.GetBytes(body, "details.session_id").String(), "%s", body)
+			assert.NotEmpty(t, gjson.GetBytes(body, "details.user_agent_hash").String(), "%s", body)
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("the security event sink did not receive the login session event")
+		}
+	}
+}
+
+func TestStrategyConsentScopeMerge(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		policy string
+		// requested and granted are the scopes requested by the client and granted by the consent
+		// app in the first and second flow.
+		requested, granted [2][]string
+		// asked is the requested scope of the second consent request.
+		asked []string
+		// expected is the scope of the token issued in the second flow.
+		expected []string
+		// automatic is the scope recorded as automatically granted in the second flow.
+		automatic []string
+	}{
+		{
+			policy:    config.ConsentScopeMergeIncremental,
+			requested: [2][]string{{"openid"}, {"openid", "offline"}},
+			granted:   [2][]string{{"openid"}, {"offline"}},
+			asked:     []string{"offline"},
+			expected:  []string{"offline", "openid"},
+			automatic: []string{"openid"},
+		},
+		{
+			policy:    config.ConsentScopeMergeUnion,
+			requested: [2][]string{{"openid", "offline"}, {"openid", "email"}},
+			granted:   [2][]string{{"openid", "offline"}, {"email"}},
+			asked:     []string{"openid", "email"},
+			// offline was granted before but is not requested again, so it must not be merged.
+			expected:  []string{"email", "openid"},
+			automatic: []string{"openid"},
+		},
+	} {
+		t.Run("policy="+tc.policy, func(t *testing.T) {
+			reg := internal.NewMockedRegistry(t, &contextx.Default{})
+			reg.Config().MustSet(ctx, config.KeyAccessTokenStrategy, "opaque")
+			reg.Config().MustSet(ctx, config.KeyConsentRequestMaxAge, time.Hour)
+			reg.Config().MustSet(ctx, config.KeyScopeStrategy, "exact")
+			reg.Config().MustSet(ctx, config.KeyConsentScopeMerge, tc.policy)
+
+			publicTS, adminTS := testhelpers.NewOAuth2Server(ctx, t, reg)
+			adminClient := hydra.NewAPIClient(hydra.NewConfiguration())
+			adminClient.GetConfig().Servers = hydra.ServerConfigurations{{URL: adminTS.URL}}
+
+			secret := uuid.New()
+			c := &client.Client{
+				LegacyClientID: uuid.New(),
+				Secret:         secret,
+				Scope:          "openid offline email",
+				RedirectURIs:   []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+			}
+			require.NoError(t, reg.ClientManager().CreateClient(ctx, c))
+			c.Secret = secret
+
+			conf := &oauth2.Config{
+				ClientID:     c.GetID(),
+				ClientSecret: c.Secret,
+				Endpoint:     oauth2.Endpoint{TokenURL: publicTS.URL + "/oauth2/token", AuthStyle: oauth2.AuthStyleInHeader},
+				RedirectURL:  c.RedirectURIs[0],
+			}
+
+			hc := testhelpers.NewEmptyJarClient(t)
+			var tokens [2]*oauth2.Token
+			for step := range tc.requested {
+				step := step
+				testhelpers.NewLoginConsentUI(t, reg.Config(),
+					checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(*testing.T, *hydra.OAuth2LoginRequest, error) hydra.AcceptOAuth2LoginRequest {
+						return hydra.AcceptOAuth2LoginRequest{Remember: pointerx.Bool(true)}
+					}),
+					checkAndAcceptConsentHandler(t, adminClient, func(t *testing.T, res *hydra.OAuth2ConsentRequest, err error) hydra.AcceptOAuth2ConsentRequest {
+						require.NoError(t, err)
+						if step == 1 {
+							assert.ElementsMatch(t, tc.asked, res.RequestedScope)
+						}
+						return hydra.AcceptOAuth2ConsentRequest{Remember: pointerx.Bool(true), GrantScope: tc.granted[step]}
+					}))
+
+				_, res := makeOAuth2Request(t, reg, hc, c, url.Values{"scope": {strings.Join(tc.requested[step], " ")}})
+				code := res.Request.URL.Query().Get("code")
+				require.NotEmpty(t, code, "%s", res.Request.URL)
+
+				token, err := conf.Exchange(ctx, code)
+				require.NoError(t, err)
+				tokens[step] = token
+			}
+
+			scope := strings.Split(fmt.Sprint(tokens[1].Extra("scope")), " ")
+			sort.Strings(scope)
+			assert.Equal(t, tc.expected, scope)
+
+			ds, err := reg.ConsentManager().FindConsentDecisions(ctx, consent.ConsentDecisionFilter{Subject: "aeneas-rekkas", ClientID: c.GetID()}, 100, 0)
+			require.NoError(t, err)
+
+			var automatic []string
+			for _, d := range ds {
+				if d.Automatic {
+					automatic = append(automatic, d.GrantedScope...)
+				}
+			}
+			assert.Equal(t, tc.automatic, automatic)
+		})
+	}
+}
+
+func TestStrategyLoginRequestHints(t *testing.T) {
+	ctx := context.Background()
+	reg := internal.NewMockedRegistry(t, &contextx.Default{})
+	reg.Config().MustSet(ctx, config.KeyAccessTokenStrategy, "opaque")
+	reg.Config().MustSet(ctx, config.KeyConsentRequestMaxAge, time.Hour)
+
+	publicTS, adminTS := testhelpers.NewOAuth2Server(ctx, t, reg)
+	adminClient := hydra.NewAPIClient(hydra.NewConfiguration())
+	adminClient.GetConfig().Servers = hydra.ServerConfigurations{{URL: adminTS.URL}}
+
+	c := createClient(t, reg, &client.Client{
+		RedirectURIs:                   []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)},
+		SupportedAuthenticationMethods: []string{"passkey", "otp"},
+	})
+	conf := &oauth2.Config{
+		ClientID:     c.GetID(),
+		ClientSecret: c.Secret,
+		Endpoint:     oauth2.Endpoint{TokenURL: publicTS.URL + "/oauth2/token", AuthStyle: oauth2.AuthStyleInHeader},
+		RedirectURL:  c.RedirectURIs[0],
+	}
+
+	// loginUI records the login request as it is stored, because the SDK does not know about all
+	// fields of the OpenID Connect context.
+	loginUI := func(t *testing.T, remember bool) <-chan *consent.LoginRequest {
+		requests := make(chan *consent.LoginRequest, 1)
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			checkAndAcceptLoginHandler(t, adminClient, "aeneas-rekkas", func(t *testing.T, res *hydra.OAuth2LoginRequest, err error) hydra.AcceptOAuth2LoginRequest {
+				require.NoError(t, err)
+				lr, err := reg.ConsentManager().GetLoginRequest(ctx, res.Challenge)
+				require.NoError(t, err)
+				requests <- lr
+				return hydra.AcceptOAuth2LoginRequest{Remember: pointerx.Bool(remember)}
+			}),
+			checkAndAcceptConsentHandler(t, adminClient, func(t *testing.T, res *hydra.OAuth2ConsentRequest, err error) hydra.AcceptOAuth2ConsentRequest {
+				require.NoError(t, err)
+				return hydra.AcceptOAuth2ConsentRequest{GrantScope: res.RequestedScope}
+			}))
+		return requests
+	}
+
+	hc := testhelpers.NewEmptyJarClient(t)
+
+	requests := loginUI(t, true)
+	_, res := makeOAuth2Request(t, reg, hc, c, url.Values{"scope": {"openid"}, "login_hint": {"aeneas@example.com"}})
+	code := res.Request.URL.Query().Get("code")
+	require.NotEmpty(t, code, "%s", res.Request.URL)
+
+	lr := <-requests
+	assert.Equal(t, "aeneas@example.com", lr.OpenIDConnectContext.LoginHint)
+	assert.Equal(t, []string{"passkey", "otp"}, lr.OpenIDConnectContext.SupportedAuthenticationMethods)
+	assert.Empty(t, lr.OpenIDConnectContext.IDTokenHintClaims)
+
+	token, err := conf.Exchange(ctx, code)
+	require.NoError(t, err)
+	idToken, ok := token.Extra("id_token").(string)
+	require.True(t, ok)
+
+	requests = loginUI(t, false)
+	_, res = makeOAuth2Request(t, reg, hc, c, url.Values{"scope": {"openid"}, "id_token_hint": {idToken}})
+	require.NotEmpty(t, res.Request.URL.Query().Get("code"), "%s", res.Request.URL)
+
+	lr = <-requests
+	assert.Equal(t, "aeneas-rekkas", lr.OpenIDConnectContext.IDTokenHintClaims["sub"])
+	assert.Empty(t, lr.OpenIDConnectContext.LoginHint)
+	assert.Equal(t, []string{"passkey", "otp"}, lr.OpenIDConnectContext.SupportedAuthenticationMethods)
+}
+
+func TestStrategyDeviceFingerprintBinding(t *testing.T) {
+	ctx := context.Background()
+	reg := internal.NewMockedRegistry(t, &contextx.Default{})
+	reg.Config().MustSet(ctx, config.KeyAccessTokenStrategy, "opaque")
+	reg.Config().MustSet(ctx, config.KeyConsentRequestMaxAge, time.Hour)
+
+	_, adminTS := testhelpers.NewOAuth2Server(ctx, t, reg)
+	adminClient := hydra.NewAPIClient(hydra.NewConfiguration())
+	adminClient.GetConfig().Servers = hydra.ServerConfigurations{{URL: adminTS.URL}}
+
+	c := createClient(t, reg, &client.Client{RedirectURIs: []string{testhelpers.NewCallbackURL(t, "callback", testhelpers.HTTPServerNotImplementedHandler)}})
+
+	// loginUI accepts every login request with the given device fingerprint and records whether
+	// the login request was skipped. The SDK does not know about the device fingerprint, so the
+	// login request is accepted with a plain HTTP request.
+	loginUI := func(t *testing.T, fingerprint string) *[]bool {
+		var skipped []bool
+		testhelpers.NewLoginConsentUI(t, reg.Config(),
+			func(w http.ResponseWriter, r *http.Request) {
+				challenge := r.URL.Query().Get("login_challenge")
+				lr, _, err := adminClient.OAuth2Api.GetOAuth2LoginRequest(ctx).LoginChallenge(challenge).Execute()
+				require.NoError(t, err)
+				skipped = append(skipped, lr.Skip)
+
+				body, err := json.Marshal(map[string]interface{}{"subject": "aeneas-rekkas", "remember": true, "device_fingerprint": fingerprint})
+				require.NoError(t, err)
+				req, err := http.NewRequest(http.MethodPut, adminTS.URL+"/admin"+consent.LoginPath+"/accept?login_challenge="+url.QueryEscape(challenge), bytes.NewReader(body))
+				require.NoError(t, err)
+				req.Header.Set("Content-Type", "application/json")
+				res, err := adminTS.Client().Do(req)
+				require.NoError(t, err)
+				defer res.Body.Close()
+				require.Equal(t, http.StatusOK, res.StatusCode)
+
+				var redirect hydra.OAuth2RedirectTo
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&redirect))
+				http.Redirect(w, r, redirect.RedirectTo, http.StatusFound)
+			},
+			checkAndAcceptConsentHandler(t, adminClient, func(t *testing.T, res *hydra.OAuth2ConsentRequest, err error) hydra.AcceptOAuth2ConsentRequest {
+				require.NoError(t, err)
+				return hydra.AcceptOAuth2ConsentRequest{GrantScope: res.RequestedScope}
+			}))
+		return &skipped
+	}
+
+	hc := testhelpers.NewEmptyJarClient(t)
+	authenticate := func(t *testing.T) {
+		_, res := makeOAuth2Request(t, reg, hc, c, url.Values{"scope": {"openid"}})
+		require.NotEmpty(t, res.Request.URL.Query().Get("code"), "%s", res.Request.URL)
+	}
+
+	skipped := loginUI(t, "device-a")
+	authenticate(t)
+	require.Equal(t, []bool{false}, *skipped)
+
+	sessions, err := reg.ConsentManager().FindSubjectsLoginSessions(ctx, "aeneas-rekkas", 100, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	remembered := sessions[0]
+	assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256([]byte("device-a"))), remembered.DeviceFingerprintHash, "only the hash of the fingerprint is stored")
+
+	t.Run("case=matching fingerprint keeps the remembered session", func(t *testing.T) {
+		skipped := loginUI(t, "device-a")
+		authenticate(t)
+		assert.Equal(t, []bool{true}, *skipped)
+
+		_, err := reg.ConsentManager().GetRememberedLoginSession(ctx, remembered.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("case=changed fingerprint revokes the remembered session and asks to log in again", func(t *testing.T) {
+		skipped := loginUI(t, "device-b")
+		authenticate(t)
+		assert.Equal(t, []bool{true, false}, *skipped)
+
+		_, err := reg.ConsentManager().GetRememberedLoginSession(ctx, remembered.ID)
+		assert.ErrorIs(t, err, x.ErrNotFound)
+	})
+}
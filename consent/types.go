@@ -20,6 +20,7 @@ import (
 	"github.com/ory/hydra/v2/client"
 	"github.com/ory/x/sqlcon"
 	"github.com/ory/x/sqlxx"
+	"github.com/ory/x/stringslice"
 )
 
 const (
@@ -40,19 +41,65 @@ type OAuth2RedirectTo struct {
 	RedirectTo string `json:"redirect_to"`
 }
 
-// swagger:ignore
+// OAuth 2.0 Login Session
+//
+// A login session is created once a subject has authenticated at the login provider.
+//
+// swagger:model oAuth2LoginSession
 type LoginSession struct {
-	ID              string         `db:"id"`
-	NID             uuid.UUID      `db:"nid"`
-	AuthenticatedAt sqlxx.NullTime `db:"authenticated_at"`
-	Subject         string         `db:"subject"`
-	Remember        bool           `db:"remember"`
+	// ID is the login session ID, also known as `sid`.
+	ID string `db:"id" json:"id"`
+
+	NID uuid.UUID `db:"nid" json:"-"`
+
+	// AuthenticatedAt is the time the subject authenticated.
+	AuthenticatedAt sqlxx.NullTime `db:"authenticated_at" json:"authenticated_at"`
+
+	// Subject is the user ID of the end-user that authenticated.
+	Subject string `db:"subject" json:"subject"`
+
+	// Remember is true if the login session is remembered using a cookie.
+	Remember bool `db:"remember" json:"remember"`
+
+	// IPAddress is the IP address of the user agent which completed the login.
+	IPAddress string `db:"ip_address" json:"ip_address"`
+
+	// UserAgentHash is the SHA-256 hash of the User-Agent header sent by the user agent which
+	// completed the login.
+	UserAgentHash string `db:"user_agent_hash" json:"user_agent_hash"`
+
+	// DeviceID is the optional device identifier the login provider set when accepting the
+	// login request.
+	DeviceID string `db:"device_id" json:"device_id"`
+
+	// DeviceFingerprintHash is the SHA-256 hash of the device fingerprint the remembered session
+	// is bound to.
+	DeviceFingerprintHash string `db:"device_fingerprint_hash" json:"-"`
 }
 
 func (_ LoginSession) TableName() string {
 	return "hydra_oauth2_authentication_session"
 }
 
+// List of OAuth 2.0 Login Sessions
+//
+// swagger:model oAuth2LoginSessions
+type oAuth2LoginSessions []LoginSession
+
+// OAuth 2.0 Consent Request Scope Description
+//
+// swagger:model oAuth2ConsentRequestScopeDescription
+type OAuth2ConsentRequestScopeDescription struct {
+	// Locale is the locale of the name and description.
+	Locale string `json:"locale"`
+
+	// Name is the human readable name of the scope.
+	Name string `json:"name"`
+
+	// Description describes what the scope grants access to.
+	Description string `json:"description"`
+}
+
 // The request payload used to accept a login or consent request.
 //
 // swagger:model rejectOAuth2Request
@@ -190,6 +237,212 @@ func (r *AcceptOAuth2ConsentRequest) HasError() bool {
 // swagger:model oAuth2ConsentSessions
 type oAuth2ConsentSessions []OAuth2ConsentSession
 
+const (
+	ConsentDecisionAccept = "accept"
+	ConsentDecisionReject = "reject"
+)
+
+// OAuth 2.0 Consent Decision
+//
+// An immutable record of a consent request being accepted or rejected.
+//
+// swagger:model oAuth2ConsentDecision
+type ConsentDecision struct {
+	// ID is the unique identifier of this record.
+	ID uuid.UUID `json:"id" db:"id"`
+
+	NID uuid.UUID `json:"-" db:"nid"`
+
+	// ConsentChallenge is the challenge of the consent request this decision was made for.
+	ConsentChallenge string `json:"consent_challenge" db:"consent_challenge_id"`
+
+	// Decision is either `accept` or `reject`.
+	Decision string `json:"decision" db:"decision"`
+
+	// Subject is the user ID of the end-user that made the decision.
+	Subject string `json:"subject" db:"subject"`
+
+	// ClientID is the ID of the OAuth 2.0 Client which requested consent.
+	ClientID string `json:"client_id" db:"client_id"`
+
+	// LoginSessionID is the login session the subject was authenticated with.
+	LoginSessionID sqlxx.NullString `json:"login_session_id" db:"login_session_id"`
+
+	// GrantedScope contains the scopes the subject granted.
+	GrantedScope sqlxx.StringSliceJSONFormat `json:"granted_scope" db:"granted_scope"`
+
+	// DeniedScope contains the requested scopes the subject did not grant.
+	DeniedScope sqlxx.StringSliceJSONFormat `json:"denied_scope" db:"denied_scope"`
+
+	// GrantedAudience contains the audiences the subject granted.
+	GrantedAudience sqlxx.StringSliceJSONFormat `json:"granted_audience" db:"granted_audience"`
+
+	// Remember is true if the consent app asked to remember the decision.
+	Remember bool `json:"remember" db:"remember"`
+
+	// RememberFor is the time in seconds the decision is remembered for.
+	RememberFor int `json:"remember_for" db:"remember_for"`
+
+	// Error is the error sent by the consent app when rejecting the request.
+	Error string `json:"error,omitempty" db:"error"`
+
+	// Skip is true if the consent request could be skipped because the subject had previously
+	// granted and remembered consent for this client.
+	Skip bool `json:"skip" db:"skip"`
+
+	// Automatic is true if the scopes were granted by Hydra itself rather than the consent app,
+	// because they were remembered from an earlier consent and merged into the grant according
+	// to `oauth2.consent.scope_merge`.
+	Automatic bool `json:"automatic" db:"automatic"`
+
+	// CreatedAt is the time the decision was made.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (_ ConsentDecision) TableName() string {
+	return "hydra_oauth2_consent_decision"
+}
+
+// List of OAuth 2.0 Consent Decisions
+//
+// swagger:model oAuth2ConsentDecisions
+type oAuth2ConsentDecisions []ConsentDecision
+
+// ConsentDecisionFilter narrows down the consent decisions returned by the manager. Zero
+// values are ignored.
+type ConsentDecisionFilter struct {
+	Subject  string
+	ClientID string
+	Since    time.Time
+	Until    time.Time
+}
+
+// newConsentDecision builds the audit record for a handled consent request.
+func newConsentDecision(cr *OAuth2ConsentRequest, handled *AcceptOAuth2ConsentRequest) *ConsentDecision {
+	d := &ConsentDecision{
+		ConsentChallenge: cr.ID,
+		Decision:         ConsentDecisionAccept,
+		Subject:          cr.Subject,
+		ClientID:         cr.ClientID,
+		LoginSessionID:   cr.LoginSessionID,
+		GrantedScope:     handled.GrantedScope,
+		GrantedAudience:  handled.GrantedAudience,
+		Remember:         handled.Remember,
+		RememberFor:      handled.RememberFor,
+		Skip:             cr.Skip,
+		CreatedAt:        time.Time(handled.HandledAt),
+	}
+	if d.ClientID == "" && cr.Client != nil {
+		d.ClientID = cr.Client.GetID()
+	}
+
+	if handled.HasError() {
+		d.Decision = ConsentDecisionReject
+		d.Error = handled.Error.Name
+		d.GrantedScope = nil
+		d.GrantedAudience = nil
+		d.Remember = false
+		d.RememberFor = 0
+	}
+
+	for _, scope := range cr.RequestedScope {
+		if !stringslice.Has(d.GrantedScope, scope) {
+			d.DeniedScope = append(d.DeniedScope, scope)
+		}
+	}
+	return d
+}
+
+// newAutomaticConsentDecision builds the audit record for scopes which were not granted by the
+// consent app but merged in from a remembered consent.
+func newAutomaticConsentDecision(cr *OAuth2ConsentRequest, granted []string) *ConsentDecision {
+	d := newConsentDecision(cr, &AcceptOAuth2ConsentRequest{
+		GrantedScope: granted,
+		HandledAt:    sqlxx.NullTime(time.Now().UTC()),
+	})
+	d.DeniedScope = nil
+	d.Automatic = true
+	return d
+}
+
+// OAuth 2.0 Pairwise Subject Mapping
+//
+// Maps the pairwise subject identifier computed with the previous salt to the one computed with
+// the current salt. While the mapping has not expired, the previous identifier is issued to the
+// client so that the client can migrate its records.
+//
+// swagger:model oAuth2PairwiseSubjectMapping
+type PairwiseSubjectMapping struct {
+	NID uuid.UUID `json:"-" db:"nid"`
+
+	// ClientID is the ID of the OAuth 2.0 Client the identifiers were computed for.
+	ClientID string `json:"client_id" db:"client_id"`
+
+	// Subject is the local subject identifier. It is not exposed to the client.
+	Subject string `json:"-" db:"subject"`
+
+	// PreviousSubject is the pairwise subject identifier computed with the previous salt.
+	PreviousSubject string `json:"previous_subject" db:"previous_subject"`
+
+	// CurrentSubject is the pairwise subject identifier computed with the current salt.
+	CurrentSubject string `json:"current_subject" db:"current_subject"`
+
+	// ExpiresAt is the end of the dual-read window. Afterwards the current identifier is issued.
+	ExpiresAt time.Time `json:"expires_at" db:"expires_at"`
+
+	// CreatedAt is the time the salt was rotated.
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+func (_ PairwiseSubjectMapping) TableName() string {
+	return "hydra_oauth2_pairwise_subject_mapping"
+}
+
+// List of OAuth 2.0 Pairwise Subject Mappings
+//
+// swagger:model oAuth2PairwiseSubjectMappings
+type oAuth2PairwiseSubjectMappings []PairwiseSubjectMapping
+
+// OAuth 2.0 Scope Description
+//
+// The localized display name and description of an OAuth 2.0 scope managed through the admin API.
+//
+// swagger:model oAuth2ScopeDescription
+type ScopeDescription struct {
+	NID uuid.UUID `json:"-" db:"nid"`
+
+	// Scope is the OAuth 2.0 scope this entry describes.
+	Scope string `json:"scope" db:"scope"`
+
+	// Locale is the BCP 47 language tag of this entry.
+	Locale string `json:"locale" db:"locale"`
+
+	// Name is the human readable name of the scope.
+	Name string `json:"name" db:"name"`
+
+	// Description describes what the scope grants access to.
+	Description string `json:"description" db:"description"`
+
+	// UpdatedAt is the time this entry was last set.
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (_ ScopeDescription) TableName() string {
+	return "hydra_oauth2_scope_description"
+}
+
+// List of OAuth 2.0 Scope Descriptions
+//
+// swagger:model oAuth2ScopeDescriptions
+type oAuth2ScopeDescriptions []ScopeDescription
+
+// PairwiseSubject is a local subject which has been issued a pairwise subject identifier
+// for a client.
+type PairwiseSubject struct {
+	ClientID string `db:"client_id"`
+	Subject  string `db:"subject"`
+}
+
 // OAuth 2.0 Consent Session
 //
 // A completed OAuth 2.0 Consent Session.
@@ -284,6 +537,24 @@ type HandledLoginRequest struct {
 	// authentication, the amr can express they used a software-secured key.
 	AMR sqlxx.StringSliceJSONFormat `json:"amr"`
 
+	// DeviceID is an optional identifier of the device the subject used to authenticate. It is
+	// stored on the login session together with the IP address and User-Agent of the request
+	// and can be used to detect unusual session activity.
+	//
+	// required: false
+	DeviceID string `json:"device_id,omitempty"`
+
+	// DeviceFingerprint is an optional fingerprint (or a hash of it) of the device the subject used to
+	// authenticate. If set when the login session is remembered, the login provider has to send the same
+	// fingerprint whenever it accepts a skipped login request for this session. Otherwise, the remembered
+	// session is revoked and the subject has to log in again.
+	//
+	// required: false
+	DeviceFingerprint string `json:"device_fingerprint,omitempty" faker:"-"`
+
+	// DeviceFingerprintHash is the SHA-256 hash of DeviceFingerprint. Only the hash is stored.
+	DeviceFingerprintHash string `json:"-"`
+
 	// Subject is the user ID of the end-user that authenticated.
 	//
 	// required: true
@@ -370,6 +641,18 @@ type OAuth2ConsentRequestOpenIDConnectContext struct {
 	// and then wants to pass that value as a hint to the discovered authorization service. This value MAY also be a
 	// phone number in the format specified for the phone_number Claim. The use of this parameter is optional.
 	LoginHint string `json:"login_hint,omitempty"`
+
+	// SupportedAuthenticationMethods are the authentication methods (e.g. `passkey`) declared by the client
+	// in `supported_authentication_methods`. Login apps can use them to pre-select a suitable authenticator.
+	SupportedAuthenticationMethods []string `json:"supported_authentication_methods,omitempty"`
+
+	// RequiredAMR are the authentication method references declared by the client in `required_amr`. The login
+	// must be accepted with all of them in `amr`, otherwise the authorization request fails.
+	RequiredAMR []string `json:"required_amr,omitempty"`
+
+	// RequiredACR are the authentication context class references declared by the client in `required_acr`. The login
+	// must be accepted with one of them as `acr`, otherwise the authorization request fails.
+	RequiredACR []string `json:"required_acr,omitempty"`
 }
 
 func (n *OAuth2ConsentRequestOpenIDConnectContext) Scan(value interface{}) error {
@@ -584,6 +867,11 @@ type OAuth2ConsentRequest struct {
 	// Context contains arbitrary information set by the login endpoint or is empty if not set.
 	Context sqlxx.JSONRawMessage `json:"context,omitempty"`
 
+	// RequestedScopeDescriptions contains the localized display name and description of the
+	// requested scopes, taken from the scope catalog and chosen based on `ui_locales`. Scopes
+	// without a catalog entry are omitted.
+	RequestedScopeDescriptions map[string]OAuth2ConsentRequestScopeDescription `json:"requested_scope_descriptions,omitempty" faker:"-"`
+
 	// If set to true means that the request was already handled. This
 	// can happen on form double-submit or other errors. If this is set
 	// we recommend redirecting the user to `request_url` to re-initiate
@@ -66,3 +66,48 @@ func TestRequestDeniedError(t *testing.T) {
 	require.NoError(t, err)
 	assert.EqualValues(t, "{}", fmt.Sprintf("%v", v))
 }
+
+func TestNewConsentDecision(t *testing.T) {
+	cr := &OAuth2ConsentRequest{
+		ID:             "challenge",
+		Subject:        "subject",
+		ClientID:       "client",
+		LoginSessionID: "sid",
+		RequestedScope: []string{"openid", "offline_access", "email"},
+	}
+
+	t.Run("case=accept", func(t *testing.T) {
+		d := newConsentDecision(cr, &AcceptOAuth2ConsentRequest{
+			GrantedScope: []string{"openid", "email"},
+			Remember:     true,
+			RememberFor:  10,
+		})
+		assert.Equal(t, ConsentDecisionAccept, d.Decision)
+		assert.EqualValues(t, []string{"openid", "email"}, d.GrantedScope)
+		assert.EqualValues(t, []string{"offline_access"}, d.DeniedScope)
+		assert.EqualValues(t, "sid", d.LoginSessionID)
+		assert.True(t, d.Remember)
+		assert.Equal(t, 10, d.RememberFor)
+	})
+
+	t.Run("case=reject", func(t *testing.T) {
+		d := newConsentDecision(cr, &AcceptOAuth2ConsentRequest{
+			Error: &RequestDeniedError{Name: "access_denied", valid: true},
+		})
+		assert.Equal(t, ConsentDecisionReject, d.Decision)
+		assert.Equal(t, "access_denied", d.Error)
+		assert.Empty(t, d.GrantedScope)
+		assert.EqualValues(t, []string{"openid", "offline_access", "email"}, d.DeniedScope)
+		assert.Equal(t, "client", d.ClientID)
+	})
+
+	t.Run("case=automatic", func(t *testing.T) {
+		d := newAutomaticConsentDecision(cr, []string{"offline_access"})
+		assert.Equal(t, ConsentDecisionAccept, d.Decision)
+		assert.True(t, d.Automatic)
+		assert.EqualValues(t, []string{"offline_access"}, d.GrantedScope)
+		assert.Empty(t, d.DeniedScope)
+		assert.False(t, d.Remember)
+		assert.False(t, d.CreatedAt.IsZero())
+	})
+}
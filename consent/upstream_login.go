@@ -0,0 +1,487 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"golang.org/x/oauth2"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/ory/fosite"
+	"github.com/ory/herodot"
+	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/mapx"
+	"github.com/ory/x/randx"
+	"github.com/ory/x/urlx"
+)
+
+const (
+	UpstreamLoginPath    = "/oauth2/upstream/login"
+	UpstreamCallbackPath = "/oauth2/upstream/callback"
+)
+
+// upstreamDiscoveryTTL is how long the discovery document of an upstream provider is cached.
+const upstreamDiscoveryTTL = time.Hour
+
+// upstreamDiscovery is the subset of the OpenID Connect discovery document used by the built-in login handler.
+type upstreamDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// upstreamDiscoveryCache caches the discovery documents of the upstream providers by issuer URL.
+type upstreamDiscoveryCache struct {
+	sync.Mutex
+	entries map[string]upstreamDiscoveryCacheEntry
+}
+
+type upstreamDiscoveryCacheEntry struct {
+	discovery *upstreamDiscovery
+	expiresAt time.Time
+}
+
+func (c *upstreamDiscoveryCache) get(issuer string) (*upstreamDiscovery, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, ok := c.entries[issuer]
+	if !ok || time.Now().After(e.expiresAt) {
+		return nil, false
+	}
+	return e.discovery, true
+}
+
+func (c *upstreamDiscoveryCache) set(issuer string, discovery *upstreamDiscovery) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]upstreamDiscoveryCacheEntry{}
+	}
+	c.entries[issuer] = upstreamDiscoveryCacheEntry{discovery: discovery, expiresAt: time.Now().Add(upstreamDiscoveryTTL)}
+}
+
+var upstreamChooserTemplate = template.Must(template.New("upstream").Parse(`<html>
+<head>
+	<title>Sign in</title>
+</head>
+<body>
+<h1>
+	Sign in with
+</h1>
+<ul>
+	{{ range .Providers }}<li><a href="{{ .URL }}">{{ .Label }}</a></li>
+	{{ end }}
+</ul>
+</body>
+</html>`))
+
+// upstreamLogin is the built-in login UI. It delegates authentication to one of the configured
+// upstream OpenID Connect providers. If several providers are configured and none was chosen yet,
+// it renders a page which lets the user choose.
+func (h *Handler) upstreamLogin(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	providers := h.c.UpstreamOIDCProviders(ctx)
+	if len(providers) == 0 {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(herodot.ErrNotFound.WithReasonf("Configuration key '%s' does not contain any providers.", config.KeyUpstreamOIDCProviders)))
+		return
+	}
+
+	challenge := r.URL.Query().Get("login_challenge")
+	if challenge == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint(`Query parameter 'login_challenge' is not defined but should have been.`)))
+		return
+	}
+
+	ar, err := h.r.ConsentManager().GetLoginRequest(ctx, challenge)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	// The user was already authenticated, there is no need to ask the upstream provider again.
+	if ar.Skip {
+		redirectTo, err := acceptLoginRequest(ctx, h.r.ConsentManager(), challenge, &HandledLoginRequest{Subject: ar.Subject})
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, redirectTo, http.StatusFound)
+		return
+	}
+
+	id := r.URL.Query().Get("provider")
+	if id == "" && len(providers) == 1 {
+		id = providers[0].ID
+	}
+
+	if id == "" {
+		h.renderUpstreamChooser(w, r, providers, challenge)
+		return
+	}
+
+	provider, ok := findUpstreamProvider(providers, id)
+	if !ok {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Upstream provider '%s' is not configured.", id)))
+		return
+	}
+
+	discovery, err := h.discoverUpstreamProvider(ctx, provider)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	state, err := randx.RuneSequence(32, randx.AlphaNum)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+	nonce, err := randx.RuneSequence(32, randx.AlphaNum)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+	verifier, err := randx.RuneSequence(64, randx.AlphaNum)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+
+	store, err := h.r.CookieStore(ctx)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	// Errors can be ignored here, because we always get a session back.
+	session, _ := store.Get(r, h.upstreamCookieName(ctx))
+	session.Values["challenge"] = challenge
+	session.Values["provider"] = provider.ID
+	session.Values["state"] = string(state)
+	session.Values["nonce"] = string(nonce)
+	session.Values["verifier"] = string(verifier)
+	session.Options.HttpOnly = true
+	session.Options.Secure = h.c.CookieSecure(ctx)
+	session.Options.SameSite = http.SameSiteLaxMode
+	session.Options.Domain = h.c.CookieDomain(ctx)
+	session.Options.MaxAge = int(h.c.ConsentRequestMaxAge(ctx).Seconds())
+	if err := session.Save(r, w); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+
+	challengeSum := sha256.Sum256([]byte(string(verifier)))
+	http.Redirect(w, r, h.upstreamOAuth2Config(ctx, provider, discovery).AuthCodeURL(
+		string(state),
+		oauth2.SetAuthURLParam("nonce", string(nonce)),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challengeSum[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), http.StatusFound)
+}
+
+// upstreamCallback handles the redirect from the upstream provider, verifies the ID Token and accepts
+// (or rejects) the login request with the upstream subject.
+func (h *Handler) upstreamCallback(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	ctx := r.Context()
+	store, err := h.r.CookieStore(ctx)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	session, err := store.Get(r, h.upstreamCookieName(ctx))
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrRequestForbidden.WithHint("The upstream login session cookie could not be decoded.")))
+		return
+	}
+
+	challenge, _ := mapx.GetString(session.Values, "challenge")
+	id, _ := mapx.GetString(session.Values, "provider")
+	state, _ := mapx.GetString(session.Values, "state")
+	nonce, _ := mapx.GetString(session.Values, "nonce")
+	verifier, _ := mapx.GetString(session.Values, "verifier")
+	if challenge == "" || state == "" || r.URL.Query().Get("state") != state {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrRequestForbidden.WithHint("The state parameter does not match the upstream login session.")))
+		return
+	}
+
+	// The session is single use.
+	session.Options.MaxAge = -1
+	if err := session.Save(r, w); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+
+	if upstreamErr := r.URL.Query().Get("error"); upstreamErr != "" {
+		redirectTo, err := rejectLoginRequest(ctx, h.r.ConsentManager(), challenge, &RequestDeniedError{
+			Name:        upstreamErr,
+			Description: r.URL.Query().Get("error_description"),
+			Hint:        fmt.Sprintf("The upstream provider '%s' rejected the authentication.", id),
+		})
+		if err != nil {
+			h.r.Writer().WriteError(w, r, err)
+			return
+		}
+		http.Redirect(w, r, redirectTo, http.StatusFound)
+		return
+	}
+
+	provider, ok := findUpstreamProvider(h.c.UpstreamOIDCProviders(ctx), id)
+	if !ok {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHintf("Upstream provider '%s' is not configured.", id)))
+		return
+	}
+
+	discovery, err := h.discoverUpstreamProvider(ctx, provider)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	token, err := h.upstreamOAuth2Config(ctx, provider, discovery).Exchange(
+		context.WithValue(ctx, oauth2.HTTPClient, h.r.HTTPClient(ctx).StandardClient()),
+		r.URL.Query().Get("code"),
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithHintf("Unable to exchange the authorization code at upstream provider '%s'.", provider.ID).WithDebug(err.Error())))
+		return
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrServerError.WithHintf("Upstream provider '%s' did not return an ID Token.", provider.ID)))
+		return
+	}
+
+	claims, err := h.verifyUpstreamIDToken(ctx, provider, discovery, rawIDToken, nonce)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	subjectClaim := provider.SubjectClaim
+	if subjectClaim == "" {
+		subjectClaim = "sub"
+	}
+	subject, _ := claims[subjectClaim].(string)
+	if subject == "" {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrServerError.WithHintf("The ID Token of upstream provider '%s' does not contain the string claim '%s'.", provider.ID, subjectClaim)))
+		return
+	}
+
+	loginContext, err := json.Marshal(map[string]interface{}{
+		"upstream_provider": provider.ID,
+		"upstream_claims":   claims,
+	})
+	if err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+		return
+	}
+
+	redirectTo, err := acceptLoginRequest(ctx, h.r.ConsentManager(), challenge, &HandledLoginRequest{
+		Subject: provider.SubjectPrefix + subject,
+		AMR:     []string{"fed"},
+		Context: loginContext,
+	})
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("login_challenge", challenge).
+		WithField("upstream_provider", provider.ID).
+		WithField("subject", provider.SubjectPrefix+subject).
+		Info("Login request was accepted by the upstream login handler.")
+
+	http.Redirect(w, r, redirectTo, http.StatusFound)
+}
+
+func (h *Handler) renderUpstreamChooser(w http.ResponseWriter, r *http.Request, providers []config.UpstreamOIDCProvider, challenge string) {
+	type link struct {
+		URL   string
+		Label string
+	}
+
+	links := make([]link, len(providers))
+	for k, p := range providers {
+		label := p.Label
+		if label == "" {
+			label = p.ID
+		}
+		links[k] = link{
+			URL: urlx.CopyWithQuery(urlx.AppendPaths(h.c.PublicURL(r.Context()), UpstreamLoginPath), url.Values{
+				"login_challenge": {challenge},
+				"provider":        {p.ID},
+			}).String(),
+			Label: label,
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := upstreamChooserTemplate.Execute(w, struct{ Providers []link }{Providers: links}); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(err))
+	}
+}
+
+func (h *Handler) upstreamCookieName(ctx context.Context) string {
+	return h.c.CookieNameLoginCSRF(ctx) + "_upstream"
+}
+
+func (h *Handler) upstreamOAuth2Config(ctx context.Context, provider config.UpstreamOIDCProvider, discovery *upstreamDiscovery) *oauth2.Config {
+	scope := provider.Scope
+	if len(scope) == 0 {
+		scope = []string{"openid"}
+	}
+
+	return &oauth2.Config{
+		ClientID:     provider.ClientID,
+		ClientSecret: provider.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+		RedirectURL: urlx.AppendPaths(h.c.PublicURL(ctx), UpstreamCallbackPath).String(),
+		Scopes:      scope,
+	}
+}
+
+func (h *Handler) discoverUpstreamProvider(ctx context.Context, provider config.UpstreamOIDCProvider) (*upstreamDiscovery, error) {
+	if discovery, ok := h.upstreamDiscoveries.get(provider.IssuerURL); ok {
+		return discovery, nil
+	}
+
+	var discovery upstreamDiscovery
+	if err := h.getUpstreamJSON(ctx, strings.TrimRight(provider.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+
+	if discovery.Issuer != provider.IssuerURL {
+		return nil, errorsx.WithStack(fosite.ErrServerError.
+			WithHintf("The discovery document of upstream provider '%s' contains an unexpected issuer.", provider.ID).
+			WithDebugf("Expected issuer '%s' but got '%s'.", provider.IssuerURL, discovery.Issuer))
+	}
+
+	h.upstreamDiscoveries.set(provider.IssuerURL, &discovery)
+	return &discovery, nil
+}
+
+func (h *Handler) verifyUpstreamIDToken(ctx context.Context, provider config.UpstreamOIDCProvider, discovery *upstreamDiscovery, rawIDToken, nonce string) (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithHintf("The ID Token of upstream provider '%s' could not be parsed.", provider.ID).WithDebug(err.Error()))
+	}
+
+	// The key set is cached by the JWKS fetcher. If the signature can not be verified with the
+	// cached keys, the upstream provider might have rotated its keys and they are fetched again.
+	keys, err := h.r.GetJWKSFetcherStrategy().Resolve(ctx, discovery.JWKSURI, false)
+	if err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+
+	standard, claims, verified := verifyUpstreamSignature(token, keys)
+	if !verified {
+		keys, err = h.r.GetJWKSFetcherStrategy().Resolve(ctx, discovery.JWKSURI, true)
+		if err != nil {
+			return nil, errorsx.WithStack(err)
+		}
+		standard, claims, verified = verifyUpstreamSignature(token, keys)
+	}
+	if !verified {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithHintf("The signature of the ID Token of upstream provider '%s' could not be verified.", provider.ID))
+	}
+
+	if err := standard.ValidateWithLeeway(jwt.Expected{
+		Issuer:   discovery.Issuer,
+		Audience: jwt.Audience{provider.ClientID},
+		Time:     time.Now(),
+	}, time.Minute); err != nil {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithHintf("The ID Token of upstream provider '%s' is invalid.", provider.ID).WithDebug(err.Error()))
+	}
+
+	if claimed, _ := claims["nonce"].(string); claimed != nonce {
+		return nil, errorsx.WithStack(fosite.ErrServerError.WithHintf("The nonce of the ID Token of upstream provider '%s' does not match.", provider.ID))
+	}
+
+	return claims, nil
+}
+
+// verifyUpstreamSignature verifies the signature of the ID Token with the signing keys of the key set
+// and returns its claims.
+func verifyUpstreamSignature(token *jwt.JSONWebToken, keys *jose.JSONWebKeySet) (jwt.Claims, map[string]interface{}, bool) {
+	var kid string
+	if len(token.Headers) > 0 {
+		kid = token.Headers[0].KeyID
+	}
+
+	candidates := keys.Keys
+	if kid != "" {
+		candidates = keys.Key(kid)
+	}
+
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		var standard jwt.Claims
+		var claims map[string]interface{}
+		if err := token.Claims(key.Key, &standard, &claims); err == nil {
+			return standard, claims, true
+		}
+	}
+
+	return jwt.Claims{}, nil, false
+}
+
+func (h *Handler) getUpstreamJSON(ctx context.Context, location string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithDebugf("Unable to prepare the HTTP Request: %s", err))
+	}
+
+	resp, err := h.r.HTTPClient(ctx).StandardClient().Do(req)
+	if err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithHint("Unable to reach the upstream provider.").WithDebugf("Unable to execute HTTP Request: %s", err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errorsx.WithStack(fosite.ErrServerError.WithHint("The upstream provider responded with an error.").WithDebugf("Expected HTTP status code 200 from %s but got: %s", location, resp.Status))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errorsx.WithStack(fosite.ErrServerError.WithWrap(err).WithHint("The upstream provider responded with an invalid document.").WithDebug(err.Error()))
+	}
+
+	return nil
+}
+
+func findUpstreamProvider(providers []config.UpstreamOIDCProvider, id string) (config.UpstreamOIDCProvider, bool) {
+	for _, p := range providers {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return config.UpstreamOIDCProvider{}, false
+}
@@ -0,0 +1,187 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package consent_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+
+	"github.com/ory/hydra/v2/client"
+	. "github.com/ory/hydra/v2/consent"
+	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/hydra/v2/internal"
+	"github.com/ory/hydra/v2/x"
+	"github.com/ory/x/contextx"
+)
+
+func TestUpstreamLogin(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var discoveries, jwks int32
+	var idToken atomic.Value
+	idp := httptest.NewUnstartedServer(nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&discoveries, 1)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&jwks, 1)
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "upstream", Algorithm: "RS256", Use: "sig"}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "access-token", "token_type": "bearer", "id_token": idToken.Load().(string)})
+	})
+	idp.Config.Handler = mux
+	idp.Start()
+	t.Cleanup(idp.Close)
+
+	conf := internal.NewConfigurationWithDefaults()
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+	conf.MustSet(ctx, config.KeyUpstreamOIDCProviders, []map[string]interface{}{{
+		"id":            "idp",
+		"issuer_url":    idp.URL,
+		"client_id":     "hydra",
+		"client_secret": "secret",
+	}})
+
+	h := NewHandler(reg, conf)
+	r := x.NewRouterPublic()
+	h.SetPublicRoutes(r)
+	ts := httptest.NewServer(r)
+	t.Cleanup(ts.Close)
+	conf.MustSet(ctx, config.KeyPublicURL, ts.URL)
+
+	cl := &client.Client{LegacyClientID: "upstream-login"}
+	require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+
+	sign := func(t *testing.T, signingKey *rsa.PrivateKey, claims map[string]interface{}) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: jose.JSONWebKey{Key: signingKey, KeyID: "upstream"}}, (&jose.SignerOptions{}).WithType("JWT"))
+		require.NoError(t, err)
+		token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	claims := func(nonce string) map[string]interface{} {
+		return map[string]interface{}{
+			"iss":   idp.URL,
+			"sub":   "alice",
+			"aud":   "hydra",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce,
+		}
+	}
+
+	// login starts the upstream login for a new login request and returns the state and nonce sent to the upstream provider.
+	login := func(t *testing.T, hc *http.Client, challenge string) (string, string) {
+		require.NoError(t, reg.ConsentManager().CreateLoginRequest(ctx, &LoginRequest{
+			ID:         challenge,
+			Client:     cl,
+			RequestURL: "http://192.0.2.1/oauth2/auth",
+		}))
+
+		res, err := hc.Get(ts.URL + UpstreamLoginPath + "?login_challenge=" + url.QueryEscape(challenge))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusFound, res.StatusCode)
+
+		location, err := url.Parse(res.Header.Get("Location"))
+		require.NoError(t, err)
+		require.Equal(t, idp.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+		return location.Query().Get("state"), location.Query().Get("nonce")
+	}
+
+	callback := func(t *testing.T, hc *http.Client, state string) *http.Response {
+		res, err := hc.Get(ts.URL + UpstreamCallbackPath + "?" + url.Values{"state": {state}, "code": {"code"}}.Encode())
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	newClient := func(t *testing.T) *http.Client {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		return &http.Client{
+			Jar: jar,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		}
+	}
+
+	t.Run("case=accepts a valid ID Token", func(t *testing.T) {
+		hc := newClient(t)
+		state, nonce := login(t, hc, "upstream-valid")
+		idToken.Store(sign(t, key, claims(nonce)))
+
+		res := callback(t, hc, state)
+		require.Equal(t, http.StatusFound, res.StatusCode)
+		assert.Contains(t, res.Header.Get("Location"), "login_verifier=")
+	})
+
+	t.Run("case=rejects a bad state", func(t *testing.T) {
+		hc := newClient(t)
+		_, nonce := login(t, hc, "upstream-bad-state")
+		idToken.Store(sign(t, key, claims(nonce)))
+
+		assert.Equal(t, http.StatusForbidden, callback(t, hc, "not-the-state").StatusCode)
+	})
+
+	for _, tc := range []struct {
+		d      string
+		key    *rsa.PrivateKey
+		mutate func(map[string]interface{})
+	}{
+		{d: "nonce mismatch", key: key, mutate: func(c map[string]interface{}) { c["nonce"] = "not-the-nonce" }},
+		{d: "wrong audience", key: key, mutate: func(c map[string]interface{}) { c["aud"] = "someone-else" }},
+		{d: "expired", key: key, mutate: func(c map[string]interface{}) {
+			c["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+			c["exp"] = time.Now().Add(-time.Hour).Unix()
+		}},
+		{d: "bad signature", key: otherKey, mutate: func(map[string]interface{}) {}},
+	} {
+		t.Run("case=rejects "+tc.d, func(t *testing.T) {
+			hc := newClient(t)
+			state, nonce := login(t, hc, "upstream-"+tc.d)
+			c := claims(nonce)
+			tc.mutate(c)
+			idToken.Store(sign(t, tc.key, c))
+
+			assert.Equal(t, http.StatusInternalServerError, callback(t, hc, state).StatusCode)
+		})
+	}
+
+	t.Run("case=discovery and keys are cached", func(t *testing.T) {
+		assert.EqualValues(t, 1, atomic.LoadInt32(&discoveries))
+		// The key set is fetched once and again when the bad signature could not be verified with the cached keys.
+		assert.EqualValues(t, 2, atomic.LoadInt32(&jwks))
+	})
+}
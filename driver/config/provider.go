@@ -5,6 +5,7 @@ package config
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -81,9 +82,17 @@ const (
 	KeyAccessTokenStrategy                       = "strategies.access_token"
 	KeyDBIgnoreUnknownTableColumns               = "db.ignore_unknown_table_columns"
 	KeySubjectIdentifierAlgorithmSalt            = "oidc.subject_identifiers.pairwise.salt"
+	KeySubjectIdentifierAlgorithmPreviousSalt    = "oidc.subject_identifiers.pairwise.previous_salt"
 	KeyPublicAllowDynamicRegistration            = "oidc.dynamic_client_registration.enabled"
 	KeyPKCEEnforced                              = "oauth2.pkce.enforced"
 	KeyPKCEEnforcedForPublicClients              = "oauth2.pkce.enforced_for_public_clients"
+	KeyAMRPolicyOnViolation                      = "oauth2.amr_policy.on_violation"
+	KeyMaxAgePolicyStrict                        = "oauth2.max_age_policy.strict"
+	KeyPushModeLoginEndpoint                     = "oauth2.push_mode.login_endpoint"
+	KeyPushModeConsentEndpoint                   = "oauth2.push_mode.consent_endpoint"
+	KeyScopeCatalog                              = "oauth2.scope_catalog"
+	KeyUpstreamOIDCProviders                     = "oidc.upstream_providers"
+	KeyConsentScopeMerge                         = "oauth2.consent.scope_merge"
 	KeyLogLevel                                  = "log.level"
 	KeyCGroupsV1AutoMaxProcsEnabled              = "cgroups.v1.auto_max_procs_enabled"
 	KeyGrantAllClientCredentialsScopesPerDefault = "oauth2.client_credentials.default_grant_allowed_scope" // #nosec G101
@@ -95,7 +104,23 @@ const (
 	KeyOAuth2GrantJWTMaxDuration                 = "oauth2.grant.jwt.max_ttl"
 	KeyRefreshTokenHookURL                       = "oauth2.refresh_token_hook" // #nosec G101
 	KeyTokenHookURL                              = "oauth2.token_hook"         // #nosec G101
+	KeyRiskHookURL                               = "oauth2.risk_hook"
+	KeyLogoutConfirmation                        = "oauth2.logout.confirmation"
+	KeyTokenProfiling                            = "oauth2.token_profiling"
 	KeyDevelopmentMode                           = "dev"
+	KeyMetricsOTLPServerURL                      = "metrics.otlp.server_url"
+	KeyMetricsOTLPInsecure                       = "metrics.otlp.insecure"
+	KeyMetricsOTLPInterval                       = "metrics.otlp.interval"
+	KeyMetricsResourceAttributes                 = "metrics.resource_attributes"
+	KeyMetricsPrometheusClientIDLabelLimit       = "metrics.prometheus.client_id_label_limit"
+	KeyMetricsPrometheusIPAddressLabelLimit      = "metrics.prometheus.ip_address_label_limit"
+	KeySecurityEventSinks                        = "security_events.sinks"
+	KeyLatencyBudgetDefault                      = "latency_budgets.default"
+	KeyLatencyBudgetRoutes                       = "latency_budgets.routes"
+	KeyWatchdogGoroutineThreshold                = "watchdog.goroutine_threshold"
+	KeyWatchdogLatencyThreshold                  = "watchdog.latency_threshold"
+	KeyWatchdogInterval                          = "watchdog.interval"
+	KeyWatchdogCooldown                          = "watchdog.cooldown"
 )
 
 const DSNMemory = "memory"
@@ -107,6 +132,7 @@ type DefaultProvider struct {
 	l *logrusx.Logger
 	p *configx.Provider
 	c contextx.Contextualizer
+	w *reloadWatcher
 }
 
 func (p *DefaultProvider) GetHasherAlgorithm(ctx context.Context) x.HashAlgorithm {
@@ -148,12 +174,14 @@ func (p *DefaultProvider) getProvider(ctx context.Context) *configx.Provider {
 }
 
 func New(ctx context.Context, l *logrusx.Logger, opts ...configx.OptionModifier) (*DefaultProvider, error) {
+	w := &reloadWatcher{l: l}
 	opts = append(
 		[]configx.OptionModifier{
 			configx.WithStderrValidationReporter(),
 			configx.OmitKeysFromTracing("dsn", "secrets.system", "secrets.cookie"),
 			configx.WithImmutables("log", "serve", "dsn", "profiling"),
 			configx.WithLogrusWatcher(l),
+			configx.AttachWatcher(w.watch),
 		}, opts...,
 	)
 
@@ -161,7 +189,11 @@ func New(ctx context.Context, l *logrusx.Logger, opts ...configx.OptionModifier)
 	if err != nil {
 		return nil, err
 	}
-	return NewCustom(l, p, &contextx.Default{}), nil
+	w.init(p)
+
+	c := NewCustom(l, p, &contextx.Default{})
+	c.w = w
+	return c, nil
 }
 
 func NewCustom(l *logrusx.Logger, p *configx.Provider, ctxt contextx.Contextualizer) *DefaultProvider {
@@ -305,6 +337,48 @@ func (p *DefaultProvider) Tracing() *otelx.Config {
 	return p.getProvider(contextx.RootContext).TracingConfig("Ory Hydra")
 }
 
+// MetricsOTLPServerURL returns the OTLP/HTTP endpoint to which metrics are exported. Metrics are
+// not exported if it is empty.
+func (p *DefaultProvider) MetricsOTLPServerURL() string {
+	return p.getProvider(contextx.RootContext).String(KeyMetricsOTLPServerURL)
+}
+
+func (p *DefaultProvider) MetricsOTLPInsecure() bool {
+	return p.getProvider(contextx.RootContext).Bool(KeyMetricsOTLPInsecure)
+}
+
+func (p *DefaultProvider) MetricsOTLPInterval() time.Duration {
+	return p.getProvider(contextx.RootContext).DurationF(KeyMetricsOTLPInterval, time.Minute)
+}
+
+// MetricsResourceAttributes returns the attributes describing this deployment which are attached to
+// all exported metrics.
+func (p *DefaultProvider) MetricsResourceAttributes() map[string]string {
+	attributes := map[string]string{}
+	raw, err := json.Marshal(p.getProvider(contextx.RootContext).GetF(KeyMetricsResourceAttributes, map[string]interface{}{}))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to encode the metrics resource attributes, ignoring them.")
+		return attributes
+	}
+	if err := json.Unmarshal(raw, &attributes); err != nil {
+		p.l.WithError(err).Warnf("Key `%s` contains an invalid value, ignoring it.", KeyMetricsResourceAttributes)
+		return map[string]string{}
+	}
+	return attributes
+}
+
+// MetricsPrometheusClientIDLabelLimit returns how many distinct OAuth 2.0 Client IDs are used as
+// Prometheus label values.
+func (p *DefaultProvider) MetricsPrometheusClientIDLabelLimit() int {
+	return p.getProvider(contextx.RootContext).IntF(KeyMetricsPrometheusClientIDLabelLimit, 100)
+}
+
+// MetricsPrometheusIPAddressLabelLimit returns how many distinct IP addresses are used as Prometheus
+// label values.
+func (p *DefaultProvider) MetricsPrometheusIPAddressLabelLimit() int {
+	return p.getProvider(contextx.RootContext).IntF(KeyMetricsPrometheusIPAddressLabelLimit, 100)
+}
+
 func (p *DefaultProvider) GetCookieSecrets(ctx context.Context) ([][]byte, error) {
 	secrets := p.getProvider(ctx).Strings(KeyGetCookieSecrets)
 	if len(secrets) == 0 {
@@ -426,6 +500,36 @@ func (p *DefaultProvider) TokenHookURL(ctx context.Context) *url.URL {
 	return p.getProvider(ctx).RequestURIF(KeyTokenHookURL, nil)
 }
 
+// LogoutConfirmationRequired returns false if RP-initiated logouts carrying a valid id_token_hint
+// should be completed without showing the logout UI. Logouts without an id_token_hint always
+// require confirmation and do not consult this setting.
+func (p *DefaultProvider) LogoutConfirmationRequired(ctx context.Context) bool {
+	return p.getProvider(ctx).StringF(KeyLogoutConfirmation, "always") != "without_id_token_hint"
+}
+
+// PushModeLoginEndpoint returns the endpoint login requests are pushed to instead of redirecting
+// the user agent to the login UI, or nil if push mode is disabled for login requests.
+func (p *DefaultProvider) PushModeLoginEndpoint(ctx context.Context) *url.URL {
+	return p.getProvider(ctx).RequestURIF(KeyPushModeLoginEndpoint, nil)
+}
+
+// PushModeConsentEndpoint returns the endpoint consent requests are pushed to instead of redirecting
+// the user agent to the consent UI, or nil if push mode is disabled for consent requests.
+func (p *DefaultProvider) PushModeConsentEndpoint(ctx context.Context) *url.URL {
+	return p.getProvider(ctx).RequestURIF(KeyPushModeConsentEndpoint, nil)
+}
+
+// RiskHookURL returns the hook which decides whether a login or consent verifier may be redeemed.
+func (p *DefaultProvider) RiskHookURL(ctx context.Context) *url.URL {
+	return p.getProvider(ctx).RequestURIF(KeyRiskHookURL, nil)
+}
+
+// TokenProfiling returns whether a timing breakdown of the token minting pipeline is logged for
+// every token response.
+func (p *DefaultProvider) TokenProfiling(ctx context.Context) bool {
+	return p.getProvider(ctx).BoolF(KeyTokenProfiling, false)
+}
+
 func (p *DefaultProvider) TokenRefreshHookURL(ctx context.Context) *url.URL {
 	return p.getProvider(ctx).RequestURIF(KeyRefreshTokenHookURL, nil)
 }
@@ -438,6 +542,12 @@ func (p *DefaultProvider) SubjectIdentifierAlgorithmSalt(ctx context.Context) st
 	return p.getProvider(ctx).String(KeySubjectIdentifierAlgorithmSalt)
 }
 
+// SubjectIdentifierAlgorithmPreviousSalt returns the pairwise salt which was used before the
+// last salt rotation, or an empty string if the salt was never rotated.
+func (p *DefaultProvider) SubjectIdentifierAlgorithmPreviousSalt(ctx context.Context) string {
+	return p.getProvider(ctx).String(KeySubjectIdentifierAlgorithmPreviousSalt)
+}
+
 func (p *DefaultProvider) OIDCDiscoverySupportedClaims(ctx context.Context) []string {
 	return stringslice.Unique(
 		append(
@@ -474,6 +584,200 @@ func (p *DefaultProvider) GetEnforcePKCEForPublicClients(ctx context.Context) bo
 	return p.getProvider(ctx).Bool(KeyPKCEEnforcedForPublicClients)
 }
 
+// AMRPolicyReprompt returns true if a login which does not satisfy a client's required
+// authentication method references should send the user back to the login UI instead
+// of rejecting the authorization request.
+func (p *DefaultProvider) AMRPolicyReprompt(ctx context.Context) bool {
+	return p.getProvider(ctx).StringF(KeyAMRPolicyOnViolation, "reject") == "reprompt"
+}
+
+// MaxAgePolicyStrict returns true if an accepted login whose auth_time does not satisfy the
+// requested max_age should fail the authorization request instead of revoking the login
+// session so that the user has to log in again.
+func (p *DefaultProvider) MaxAgePolicyStrict(ctx context.Context) bool {
+	return p.getProvider(ctx).BoolF(KeyMaxAgePolicyStrict, false)
+}
+
+const (
+	// ConsentScopeMergeReconsent asks for consent to all requested scopes unless a previous
+	// consent covers all of them.
+	ConsentScopeMergeReconsent = "reconsent"
+	// ConsentScopeMergeIncremental only asks for consent to scopes which have not been granted
+	// before and adds the previously granted ones to the result.
+	ConsentScopeMergeIncremental = "incremental"
+	// ConsentScopeMergeUnion asks for consent to all requested scopes and adds the previously
+	// granted scopes which were requested again to the result.
+	ConsentScopeMergeUnion = "union"
+)
+
+func (p *DefaultProvider) ConsentScopeMergePolicy(ctx context.Context) string {
+	switch policy := p.getProvider(ctx).StringF(KeyConsentScopeMerge, ConsentScopeMergeReconsent); policy {
+	case ConsentScopeMergeIncremental, ConsentScopeMergeUnion:
+		return policy
+	default:
+		return ConsentScopeMergeReconsent
+	}
+}
+
+// ScopeDescription is the localized display name and description of an OAuth 2.0 scope.
+type ScopeDescription struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ScopeCatalogEntry holds the localized descriptions of a single scope. Scopes are listed
+// by name rather than used as keys because they often contain dots (e.g. URLs), which the
+// configuration loader would split into nested keys.
+type ScopeCatalogEntry struct {
+	Name    string                      `json:"name"`
+	Locales map[string]ScopeDescription `json:"locales"`
+}
+
+// ScopeCatalog returns the localized scope descriptions, keyed by scope and locale.
+func (p *DefaultProvider) ScopeCatalog(ctx context.Context) map[string]map[string]ScopeDescription {
+	catalog := map[string]map[string]ScopeDescription{}
+	raw, err := json.Marshal(p.getProvider(ctx).GetF(KeyScopeCatalog, []interface{}{}))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to encode the scope catalog, ignoring it.")
+		return catalog
+	}
+
+	var entries []ScopeCatalogEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		p.l.WithError(err).Warnf("Key `%s` contains an invalid value, ignoring it.", KeyScopeCatalog)
+		return catalog
+	}
+
+	for _, entry := range entries {
+		if entry.Name == "" || len(entry.Locales) == 0 {
+			continue
+		}
+		catalog[entry.Name] = entry.Locales
+	}
+	return catalog
+}
+
+// UpstreamOIDCProvider is an upstream OpenID Connect provider to which the built-in login
+// handler delegates authentication.
+type UpstreamOIDCProvider struct {
+	ID            string   `json:"id"`
+	Label         string   `json:"label"`
+	IssuerURL     string   `json:"issuer_url"`
+	ClientID      string   `json:"client_id"`
+	ClientSecret  string   `json:"client_secret"`
+	Scope         []string `json:"scope"`
+	SubjectClaim  string   `json:"subject_claim"`
+	SubjectPrefix string   `json:"subject_prefix"`
+}
+
+// UpstreamOIDCProviders returns the upstream OpenID Connect providers of the built-in login handler.
+func (p *DefaultProvider) UpstreamOIDCProviders(ctx context.Context) []UpstreamOIDCProvider {
+	var providers []UpstreamOIDCProvider
+	raw, err := json.Marshal(p.getProvider(ctx).GetF(KeyUpstreamOIDCProviders, []interface{}{}))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to encode the upstream OpenID Connect providers, ignoring them.")
+		return nil
+	}
+	if err := json.Unmarshal(raw, &providers); err != nil {
+		p.l.WithError(err).Warnf("Key `%s` contains an invalid value, ignoring it.", KeyUpstreamOIDCProviders)
+		return nil
+	}
+	return providers
+}
+
+// SecurityEventSinkConfig configures a destination of security events. Which fields apply depends
+// on the type, which is one of file, syslog and http.
+type SecurityEventSinkConfig struct {
+	Type    string            `json:"type"`
+	Path    string            `json:"path"`
+	Network string            `json:"network"`
+	Address string            `json:"address"`
+	Tag     string            `json:"tag"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// SecurityEventSinks returns the destinations of security events.
+func (p *DefaultProvider) SecurityEventSinks() []SecurityEventSinkConfig {
+	var sinks []SecurityEventSinkConfig
+	raw, err := json.Marshal(p.getProvider(contextx.RootContext).GetF(KeySecurityEventSinks, []interface{}{}))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to encode the security event sinks, ignoring them.")
+		return nil
+	}
+	if err := json.Unmarshal(raw, &sinks); err != nil {
+		p.l.WithError(err).Warnf("Key `%s` contains an invalid value, ignoring it.", KeySecurityEventSinks)
+		return nil
+	}
+	return sinks
+}
+
+// LatencyBudgetRoute is the latency budget of the requests to a path. A path ending in `*` matches
+// all paths starting with the part before the `*`.
+type LatencyBudgetRoute struct {
+	Path   string `json:"path"`
+	Budget string `json:"budget"`
+}
+
+// LatencyBudget returns how long a request to the path may take before it is logged as slow. The
+// first matching route wins, and paths without a matching route use the default budget. Zero means
+// that requests are never logged as slow.
+func (p *DefaultProvider) LatencyBudget(ctx context.Context, path string) time.Duration {
+	for _, route := range p.latencyBudgetRoutes(ctx) {
+		if route.Path != path && !(strings.HasSuffix(route.Path, "*") && strings.HasPrefix(path, strings.TrimSuffix(route.Path, "*"))) {
+			continue
+		}
+
+		budget, err := time.ParseDuration(route.Budget)
+		if err != nil {
+			p.l.WithError(err).Warnf("Key `%s` contains an invalid budget for path %s, ignoring it.", KeyLatencyBudgetRoutes, route.Path)
+			continue
+		}
+		return budget
+	}
+
+	return p.getProvider(ctx).DurationF(KeyLatencyBudgetDefault, 0)
+}
+
+// LatencyBudgetsEnabled returns true if any route has a latency budget.
+func (p *DefaultProvider) LatencyBudgetsEnabled(ctx context.Context) bool {
+	return p.getProvider(ctx).DurationF(KeyLatencyBudgetDefault, 0) > 0 || len(p.latencyBudgetRoutes(ctx)) > 0
+}
+
+func (p *DefaultProvider) latencyBudgetRoutes(ctx context.Context) []LatencyBudgetRoute {
+	var routes []LatencyBudgetRoute
+	raw, err := json.Marshal(p.getProvider(ctx).GetF(KeyLatencyBudgetRoutes, []interface{}{}))
+	if err != nil {
+		p.l.WithError(err).Warn("Unable to encode the latency budgets, ignoring them.")
+		return nil
+	}
+	if err := json.Unmarshal(raw, &routes); err != nil {
+		p.l.WithError(err).Warnf("Key `%s` contains an invalid value, ignoring it.", KeyLatencyBudgetRoutes)
+		return nil
+	}
+	return routes
+}
+
+// WatchdogGoroutineThreshold returns the number of goroutines above which the watchdog dumps all
+// goroutines. Zero disables the check.
+func (p *DefaultProvider) WatchdogGoroutineThreshold() int {
+	return p.getProvider(contextx.RootContext).IntF(KeyWatchdogGoroutineThreshold, 0)
+}
+
+// WatchdogLatencyThreshold returns the request latency above which the watchdog dumps all
+// goroutines. Zero disables the check.
+func (p *DefaultProvider) WatchdogLatencyThreshold() time.Duration {
+	return p.getProvider(contextx.RootContext).DurationF(KeyWatchdogLatencyThreshold, 0)
+}
+
+func (p *DefaultProvider) WatchdogInterval() time.Duration {
+	return p.getProvider(contextx.RootContext).DurationF(KeyWatchdogInterval, 10*time.Second)
+}
+
+func (p *DefaultProvider) WatchdogCooldown() time.Duration {
+	return p.getProvider(contextx.RootContext).DurationF(KeyWatchdogCooldown, 5*time.Minute)
+}
+
 func (p *DefaultProvider) CGroupsV1AutoMaxProcsEnabled() bool {
 	return p.getProvider(contextx.RootContext).Bool(KeyCGroupsV1AutoMaxProcsEnabled)
 }
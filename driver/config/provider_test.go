@@ -455,3 +455,169 @@ func TestJWTBearer(t *testing.T) {
 	assert.Equal(t, true, p2.GetGrantTypeJWTBearerIssuedDateOptional(ctx))
 	assert.Equal(t, true, p2.GetGrantTypeJWTBearerIDOptional(ctx))
 }
+
+func TestUpstreamOIDCProviders(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.Empty(t, c.UpstreamOIDCProviders(ctx))
+
+	c.MustSet(ctx, KeyUpstreamOIDCProviders, []map[string]interface{}{{
+		"id":             "corp",
+		"issuer_url":     "https://idp.example.com",
+		"client_id":      "hydra",
+		"client_secret":  "secret",
+		"scope":          []string{"openid", "email"},
+		"subject_claim":  "email",
+		"subject_prefix": "corp:",
+	}})
+	assert.Equal(t, []UpstreamOIDCProvider{{
+		ID:            "corp",
+		IssuerURL:     "https://idp.example.com",
+		ClientID:      "hydra",
+		ClientSecret:  "secret",
+		Scope:         []string{"openid", "email"},
+		SubjectClaim:  "email",
+		SubjectPrefix: "corp:",
+	}}, c.UpstreamOIDCProviders(ctx))
+}
+
+func TestLogoutConfirmationRequired(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.True(t, c.LogoutConfirmationRequired(ctx))
+	c.MustSet(ctx, KeyLogoutConfirmation, "without_id_token_hint")
+	assert.False(t, c.LogoutConfirmationRequired(ctx))
+}
+
+func TestScopeCatalog(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.Empty(t, c.ScopeCatalog(ctx))
+
+	c.MustSet(ctx, KeyScopeCatalog, []map[string]interface{}{
+		{
+			"name": "https://api.example.com/orders.read",
+			"locales": map[string]interface{}{
+				"en": map[string]interface{}{"name": "Read orders", "description": "See your orders."},
+			},
+		},
+		{
+			"name": "offline_access",
+			"locales": map[string]interface{}{
+				"de-CH": map[string]interface{}{"name": "Offline-Zugriff"},
+			},
+		},
+	})
+	assert.Equal(t, map[string]map[string]ScopeDescription{
+		"https://api.example.com/orders.read": {"en": {Name: "Read orders", Description: "See your orders."}},
+		"offline_access":                      {"de-CH": {Name: "Offline-Zugriff"}},
+	}, c.ScopeCatalog(ctx))
+}
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.Empty(t, c.MetricsOTLPServerURL())
+	assert.False(t, c.MetricsOTLPInsecure())
+	assert.Equal(t, time.Minute, c.MetricsOTLPInterval())
+	assert.Empty(t, c.MetricsResourceAttributes())
+	assert.Equal(t, 100, c.MetricsPrometheusClientIDLabelLimit())
+	assert.Equal(t, 100, c.MetricsPrometheusIPAddressLabelLimit())
+
+	c.MustSet(ctx, KeyMetricsOTLPServerURL, "localhost:4318")
+	c.MustSet(ctx, KeyMetricsOTLPInsecure, true)
+	c.MustSet(ctx, KeyMetricsOTLPInterval, "15s")
+	c.MustSet(ctx, KeyMetricsResourceAttributes, map[string]interface{}{"deployment.environment": "production"})
+	c.MustSet(ctx, KeyMetricsPrometheusClientIDLabelLimit, 5)
+	c.MustSet(ctx, KeyMetricsPrometheusIPAddressLabelLimit, 7)
+
+	assert.Equal(t, "localhost:4318", c.MetricsOTLPServerURL())
+	assert.True(t, c.MetricsOTLPInsecure())
+	assert.Equal(t, 15*time.Second, c.MetricsOTLPInterval())
+	assert.Equal(t, map[string]string{"deployment.environment": "production"}, c.MetricsResourceAttributes())
+	assert.Equal(t, 5, c.MetricsPrometheusClientIDLabelLimit())
+	assert.Equal(t, 7, c.MetricsPrometheusIPAddressLabelLimit())
+}
+
+func TestSecurityEventSinks(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.Empty(t, c.SecurityEventSinks())
+
+	c.MustSet(ctx, KeySecurityEventSinks, []map[string]interface{}{
+		{"type": "file", "path": "/var/log/hydra/security.log"},
+		{"type": "http", "url": "https://siem.example.com/events", "headers": map[string]string{"Authorization": "Bearer token"}},
+	})
+	assert.Equal(t, []SecurityEventSinkConfig{
+		{Type: "file", Path: "/var/log/hydra/security.log"},
+		{Type: "http", URL: "https://siem.example.com/events", Headers: map[string]string{"Authorization": "Bearer token"}},
+	}, c.SecurityEventSinks())
+}
+
+func TestLatencyBudget(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.False(t, c.LatencyBudgetsEnabled(ctx))
+	assert.Zero(t, c.LatencyBudget(ctx, "/oauth2/token"))
+
+	c.MustSet(ctx, KeyLatencyBudgetRoutes, []map[string]interface{}{
+		{"path": "/oauth2/token", "budget": "300ms"},
+		{"path": "/admin/clients/*", "budget": "2s"},
+		{"path": "/admin/*", "budget": "500ms"},
+	})
+	assert.True(t, c.LatencyBudgetsEnabled(ctx))
+	assert.Equal(t, 300*time.Millisecond, c.LatencyBudget(ctx, "/oauth2/token"))
+	assert.Equal(t, 2*time.Second, c.LatencyBudget(ctx, "/admin/clients/foo"))
+	assert.Equal(t, 500*time.Millisecond, c.LatencyBudget(ctx, "/admin/keys/foo"))
+	assert.Zero(t, c.LatencyBudget(ctx, "/oauth2/auth"))
+
+	c.MustSet(ctx, KeyLatencyBudgetDefault, "1s")
+	assert.Equal(t, time.Second, c.LatencyBudget(ctx, "/oauth2/auth"))
+}
+
+func TestWatchdog(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.Zero(t, c.WatchdogGoroutineThreshold())
+	assert.Zero(t, c.WatchdogLatencyThreshold())
+	assert.Equal(t, 10*time.Second, c.WatchdogInterval())
+	assert.Equal(t, 5*time.Minute, c.WatchdogCooldown())
+
+	c.MustSet(ctx, KeyWatchdogGoroutineThreshold, 10000)
+	c.MustSet(ctx, KeyWatchdogLatencyThreshold, "30s")
+	assert.Equal(t, 10000, c.WatchdogGoroutineThreshold())
+	assert.Equal(t, 30*time.Second, c.WatchdogLatencyThreshold())
+}
+
+func TestTokenProfiling(t *testing.T) {
+	ctx := context.Background()
+	l := logrusx.New("", "")
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(context.Background(), l)
+
+	assert.False(t, c.TokenProfiling(ctx))
+
+	c.MustSet(ctx, KeyTokenProfiling, true)
+	assert.True(t, c.TokenProfiling(ctx))
+}
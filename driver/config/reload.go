@@ -0,0 +1,124 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ory/x/configx"
+	"github.com/ory/x/logrusx"
+	"github.com/ory/x/watcherx"
+)
+
+// redactedConfigValue replaces the values of sensitive keys in the logged configuration changes.
+const redactedConfigValue = "<redacted>"
+
+// sensitiveConfigKeys are parts of configuration keys whose values must not be logged.
+var sensitiveConfigKeys = []string{"dsn", "secret", "password", "pin", "headers"}
+
+// Change is a configuration key whose value changed when the configuration was reloaded. Added
+// keys have a nil old value and removed keys have a nil new value.
+type Change struct {
+	Key string      `json:"key"`
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// reloadWatcher logs which keys changed whenever the configuration is reloaded.
+type reloadWatcher struct {
+	sync.Mutex
+	l        *logrusx.Logger
+	p        *configx.Provider
+	previous map[string]interface{}
+	onReload []func(changes []Change, err error)
+}
+
+func (w *reloadWatcher) init(p *configx.Provider) {
+	w.Lock()
+	defer w.Unlock()
+	w.p = p
+	w.previous = p.All()
+}
+
+func (w *reloadWatcher) watch(_ watcherx.Event, err error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.p == nil {
+		return
+	}
+
+	var changes []Change
+	if err == nil {
+		current := w.p.All()
+		changes = diffConfig(w.previous, current, w.l.LeakSensitiveData())
+		w.previous = current
+
+		if len(changes) > 0 {
+			w.l.WithField("config_changes", changes).Info("The configuration was reloaded and some values changed.")
+		}
+	}
+
+	for _, f := range w.onReload {
+		f(changes, err)
+	}
+}
+
+// OnReload registers a function which is called whenever the configuration is reloaded, with the
+// changed keys or the error which prevented the reload.
+func (p *DefaultProvider) OnReload(f func(changes []Change, err error)) {
+	if p.w == nil {
+		return
+	}
+
+	p.w.Lock()
+	defer p.w.Unlock()
+	p.w.onReload = append(p.w.onReload, f)
+}
+
+// diffConfig returns the changes between the flattened configurations, sorted by key.
+func diffConfig(previous, current map[string]interface{}, leakSensitive bool) []Change {
+	keys := map[string]struct{}{}
+	for k := range previous {
+		keys[k] = struct{}{}
+	}
+	for k := range current {
+		keys[k] = struct{}{}
+	}
+
+	var changes []Change
+	for k := range keys {
+		before, after := previous[k], current[k]
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+
+		c := Change{Key: k, Old: before, New: after}
+		if !leakSensitive && isSensitiveConfigKey(k) {
+			if before != nil {
+				c.Old = redactedConfigValue
+			}
+			if after != nil {
+				c.New = redactedConfigValue
+			}
+		}
+		changes = append(changes, c)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package config
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/logrusx"
+)
+
+func TestDiffConfig(t *testing.T) {
+	previous := map[string]interface{}{
+		"urls.self.issuer": "https://auth.example.com/",
+		"secrets.system":   []interface{}{"a-very-secret-value"},
+		"ttl.access_token": "1h",
+	}
+	current := map[string]interface{}{
+		"urls.self.issuer":     "https://auth.example.com/",
+		"secrets.system":       []interface{}{"another-secret-value"},
+		"ttl.access_token":     "30m",
+		"oauth2.pkce.enforced": true,
+	}
+
+	assert.Equal(t, []Change{
+		{Key: "oauth2.pkce.enforced", Old: nil, New: true},
+		{Key: "secrets.system", Old: redactedConfigValue, New: redactedConfigValue},
+		{Key: "ttl.access_token", Old: "1h", New: "30m"},
+	}, diffConfig(previous, current, false))
+
+	assert.Contains(t, diffConfig(previous, current, true),
+		Change{Key: "secrets.system", Old: []interface{}{"a-very-secret-value"}, New: []interface{}{"another-secret-value"}})
+	assert.Empty(t, diffConfig(current, current, false))
+}
+
+func TestOnReload(t *testing.T) {
+	ctx := context.Background()
+	hook := test.Hook{}
+	l := logrusx.New("", "", logrusx.WithHook(&hook))
+	l.Logrus().SetOutput(io.Discard)
+	c := MustNew(ctx, l)
+
+	var reloads [][]Change
+	var reloadErr error
+	c.OnReload(func(changes []Change, err error) {
+		reloads = append(reloads, changes)
+		reloadErr = err
+	})
+
+	c.MustSet(ctx, KeyAccessTokenLifespan, "30m")
+	c.w.watch(nil, nil)
+	require.Len(t, reloads, 1)
+	require.NoError(t, reloadErr)
+	require.Len(t, reloads[0], 1)
+	assert.Equal(t, KeyAccessTokenLifespan, reloads[0][0].Key)
+	assert.Equal(t, "30m", reloads[0][0].New)
+	assert.Equal(t, "The configuration was reloaded and some values changed.", hook.LastEntry().Message)
+
+	c.w.watch(nil, errors.New("invalid configuration"))
+	require.Len(t, reloads, 2)
+	assert.Empty(t, reloads[1])
+	assert.Error(t, reloadErr)
+}
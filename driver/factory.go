@@ -20,6 +20,7 @@ type options struct {
 	config   *config.DefaultProvider
 	// The first default refers to determining the NID at startup; the second default referes to the fact that the Contextualizer may dynamically change the NID.
 	skipNetworkInit bool
+	migrate         bool
 }
 
 func newOptions() *options {
@@ -66,6 +67,13 @@ func SkipNetworkInit() OptionsModifier {
 	}
 }
 
+// WithAutoMigrate applies all pending SQL migrations when the registry is initialized.
+func WithAutoMigrate() OptionsModifier {
+	return func(o *options) {
+		o.migrate = true
+	}
+}
+
 func New(ctx context.Context, sl *servicelocatorx.Options, opts []OptionsModifier) (Registry, error) {
 	o := newOptions()
 	for _, f := range opts {
@@ -94,17 +102,25 @@ func New(ctx context.Context, sl *servicelocatorx.Options, opts []OptionsModifie
 		}
 	}
 
-	r, err := NewRegistryFromDSN(ctx, c, l, o.skipNetworkInit, false, ctxter)
+	r, err := NewRegistryFromDSN(ctx, c, l, o.skipNetworkInit, o.migrate, ctxter)
 	if err != nil {
 		l.WithError(err).Error("Unable to create service registry.")
 		return nil, err
 	}
 
-	if err = r.Init(ctx, o.skipNetworkInit, false, &contextx.Default{}); err != nil {
+	if err = r.Init(ctx, o.skipNetworkInit, o.migrate, &contextx.Default{}); err != nil {
 		l.WithError(err).Error("Unable to initialize service registry.")
 		return nil, err
 	}
 
+	c.OnReload(func(changes []config.Change, err error) {
+		keys := make([]string, len(changes))
+		for k, change := range changes {
+			keys[k] = change.Key
+		}
+		r.PrometheusMetrics().CountConfigReload(keys, err)
+	})
+
 	// Avoid cold cache issues on boot:
 	if o.preload {
 		CallRegistry(ctx, r)
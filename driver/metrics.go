@@ -0,0 +1,50 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"github.com/ory/hydra/v2/driver/config"
+)
+
+// newMeterProvider returns a meter provider which periodically exports metrics using OTLP/HTTP, or a
+// noop meter provider if no OTLP endpoint is configured.
+func newMeterProvider(ctx context.Context, c *config.DefaultProvider, version string) (metric.MeterProvider, error) {
+	endpoint := c.MetricsOTLPServerURL()
+	if endpoint == "" {
+		return metric.NewNoopMeterProvider(), nil
+	}
+
+	opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint)}
+	if c.MetricsOTLPInsecure() {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+
+	exp, err := otlpmetrichttp.New(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configured attributes come last so that they may override the service name and version.
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(c.Tracing().ServiceName),
+		semconv.ServiceVersionKey.String(version),
+	}
+	for k, v := range c.MetricsResourceAttributes() {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	return sdkmetric.NewMeterProvider(
+		sdkmetric.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attrs...)),
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exp, sdkmetric.WithInterval(c.MetricsOTLPInterval()))),
+	), nil
+}
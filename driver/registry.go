@@ -61,6 +61,9 @@ type Registry interface {
 	oauth2.Registry
 	PrometheusManager() *prometheus.MetricsManager
 	x.TracingProvider
+	x.MetricsProvider
+	x.PrometheusMetricsProvider
+	x.SecurityEventsProvider
 
 	RegisterRoutes(ctx context.Context, admin *httprouterx.RouterAdmin, public *httprouterx.RouterPublic)
 	ClientHandler() *client.Handler
@@ -116,5 +119,8 @@ func CallRegistry(ctx context.Context, r Registry) {
 	r.OpenIDJWTStrategy()
 	r.OpenIDConnectRequestValidator()
 	r.PrometheusManager()
+	r.PrometheusMetrics()
+	r.SecurityEvents()
 	r.Tracer(ctx)
+	r.Metrics(ctx)
 }
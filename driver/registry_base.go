@@ -13,6 +13,7 @@ import (
 	"github.com/gorilla/sessions"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
+	prom "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 
@@ -68,8 +69,12 @@ type RegistryBase struct {
 	coh             *consent.Handler
 	oah             *oauth2.Handler
 	sia             map[string]consent.SubjectIdentifierAlgorithm
+	siaSalt         string
 	trc             *otelx.Tracer
+	mtr             *x.Metrics
 	pmm             *prometheus.MetricsManager
+	ppm             *x.PrometheusMetrics
+	sec             *x.SecurityEvents
 	oa2mw           func(h http.Handler) http.Handler
 	arhs            []oauth2.AccessRequestHook
 	buildVersion    string
@@ -147,13 +152,18 @@ func (m *RegistryBase) RegisterRoutes(ctx context.Context, admin *httprouterx.Ro
 
 	m.HealthHandler().SetHealthRoutes(public.Router, false, healthx.WithMiddleware(m.addPublicCORSOnHandler(ctx)))
 
-	admin.Handler("GET", prometheus.MetricsPrometheusPath, promhttp.Handler())
+	// OpenMetrics is required to expose the exemplars of the OAuth 2.0 endpoint metrics.
+	admin.Handler("GET", prometheus.MetricsPrometheusPath, promhttp.InstrumentMetricHandler(
+		prom.DefaultRegisterer, promhttp.HandlerFor(prom.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	))
 
 	m.ConsentHandler().SetRoutes(admin)
+	m.ConsentHandler().SetPublicRoutes(public)
 	m.KeyHandler().SetRoutes(admin, public, m.OAuth2AwareMiddleware(ctx))
 	m.ClientHandler().SetRoutes(admin, public)
 	m.OAuth2Handler().SetRoutes(admin, public, m.OAuth2AwareMiddleware(ctx))
 	m.JWTGrantHandler().SetRoutes(admin)
+	x.NewLogHandler(m.r).SetRoutes(admin)
 }
 
 func (m *RegistryBase) BuildVersion() string {
@@ -446,14 +456,16 @@ func (m *RegistryBase) OAuth2Handler() *oauth2.Handler {
 }
 
 func (m *RegistryBase) SubjectIdentifierAlgorithm(ctx context.Context) map[string]consent.SubjectIdentifierAlgorithm {
-	if m.sia == nil {
+	// The pairwise salt may change on a configuration reload when it is rotated.
+	if salt := m.Config().SubjectIdentifierAlgorithmSalt(ctx); m.sia == nil || salt != m.siaSalt {
+		m.siaSalt = salt
 		m.sia = map[string]consent.SubjectIdentifierAlgorithm{}
 		for _, t := range m.Config().SubjectTypesSupported(ctx) {
 			switch t {
 			case "public":
 				m.sia["public"] = consent.NewSubjectIdentifierAlgorithmPublic()
 			case "pairwise":
-				m.sia["pairwise"] = consent.NewSubjectIdentifierAlgorithmPairwise([]byte(m.Config().SubjectIdentifierAlgorithmSalt(ctx)))
+				m.sia["pairwise"] = consent.NewSubjectIdentifierAlgorithmPairwise([]byte(salt))
 			}
 		}
 	}
@@ -476,6 +488,20 @@ func (m *RegistryBase) Tracer(ctx context.Context) *otelx.Tracer {
 	return m.trc
 }
 
+func (m *RegistryBase) Metrics(ctx context.Context) *x.Metrics {
+	if m.mtr == nil {
+		mp, err := newMeterProvider(ctx, m.conf, m.buildVersion)
+		if err == nil {
+			m.mtr, err = x.NewMetrics(mp)
+		}
+		if err != nil {
+			m.Logger().WithError(err).Error("Unable to initialize OpenTelemetry metrics.")
+			m.mtr = x.NewNoopMetrics()
+		}
+	}
+	return m.mtr
+}
+
 func (m *RegistryBase) PrometheusManager() *prometheus.MetricsManager {
 	if m.pmm == nil {
 		m.pmm = prometheus.NewMetricsManagerWithPrefix("hydra", prometheus.HTTPMetrics, m.buildVersion, m.buildHash, m.buildDate)
@@ -483,6 +509,13 @@ func (m *RegistryBase) PrometheusManager() *prometheus.MetricsManager {
 	return m.pmm
 }
 
+func (m *RegistryBase) PrometheusMetrics() *x.PrometheusMetrics {
+	if m.ppm == nil {
+		m.ppm = x.NewPrometheusMetrics(m.conf.MetricsPrometheusClientIDLabelLimit(), m.conf.MetricsPrometheusIPAddressLabelLimit())
+	}
+	return m.ppm
+}
+
 func (m *RegistryBase) Persister() persistence.Persister {
 	return m.persister
 }
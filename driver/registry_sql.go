@@ -68,10 +68,17 @@ func (m *RegistrySQL) Init(
 ) error {
 	if m.persister == nil {
 		m.WithContextualizer(ctxer)
+		// The instrumented driver is also needed to time SQL statements for the latency budgets.
 		var opts []instrumentedsql.Opt
-		if m.Tracer(ctx).IsLoaded() {
+		instrumented := m.Tracer(ctx).IsLoaded() || m.Config().LatencyBudgetsEnabled(ctx)
+		if instrumented {
+			var tracer instrumentedsql.Tracer = noopSQLTracer{}
+			if m.Tracer(ctx).IsLoaded() {
+				tracer = otelsql.NewTracer()
+			}
 			opts = []instrumentedsql.Opt{
-				instrumentedsql.WithTracer(otelsql.NewTracer()),
+				instrumentedsql.WithTracer(newSQLTracer(tracer, m.Config().DSN())),
+				instrumentedsql.WithOmitArgs(),
 			}
 		}
 
@@ -86,7 +93,7 @@ func (m *RegistrySQL) Init(
 				ConnMaxLifetime:           connMaxLifetime,
 				ConnMaxIdleTime:           connMaxIdleTime,
 				Pool:                      pool,
-				UseInstrumentedDriver:     m.Tracer(ctx).IsLoaded(),
+				UseInstrumentedDriver:     instrumented,
 				InstrumentedDriverOptions: opts,
 				Unsafe:                    m.Config().DbIgnoreUnknownTableColumns(),
 			},
@@ -0,0 +1,46 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/hydra/v2/x"
+)
+
+func (m *RegistryBase) newSecurityEventSink(ctx context.Context, c config.SecurityEventSinkConfig) (x.SecurityEventSink, error) {
+	switch c.Type {
+	case "file":
+		return x.NewFileSecurityEventSink(c.Path)
+	case "syslog":
+		tag := c.Tag
+		if tag == "" {
+			tag = "hydra"
+		}
+		return x.NewSyslogSecurityEventSink(c.Network, c.Address, tag)
+	case "http":
+		return x.NewHTTPSecurityEventSink(m.HTTPClient(ctx), c.URL, c.Headers), nil
+	default:
+		return nil, errors.Errorf("unknown security event sink type %q", c.Type)
+	}
+}
+
+func (m *RegistryBase) SecurityEvents() *x.SecurityEvents {
+	if m.sec == nil {
+		var sinks []x.SecurityEventSink
+		for _, c := range m.conf.SecurityEventSinks() {
+			sink, err := m.newSecurityEventSink(context.Background(), c)
+			if err != nil {
+				m.Logger().WithError(err).WithField("type", c.Type).Error("Unable to initialize the security event sink, events are not written to it.")
+				continue
+			}
+			sinks = append(sinks, sink)
+		}
+		m.sec = x.NewSecurityEvents(m.Logger(), sinks...)
+	}
+	return m.sec
+}
@@ -0,0 +1,94 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package driver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/luna-duclos/instrumentedsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+
+	"github.com/ory/hydra/v2/x"
+)
+
+// sqlTracer wraps the SQL tracer of instrumentedsql and records the statements using the semantic
+// conventions for database spans. Query arguments, which include token signatures, are omitted.
+// The duration of each statement is also added to the request timings of the latency budgets.
+type sqlTracer struct {
+	instrumentedsql.Tracer
+	system attribute.KeyValue
+}
+
+type sqlSpan struct {
+	instrumentedsql.Span
+	system attribute.KeyValue
+	ctx    context.Context
+	start  time.Time
+}
+
+func newSQLTracer(t instrumentedsql.Tracer, dsn string) instrumentedsql.Tracer {
+	return &sqlTracer{Tracer: t, system: sqlSystem(dsn)}
+}
+
+// sqlSystem maps the scheme of the DSN to the db.system attribute.
+func sqlSystem(dsn string) attribute.KeyValue {
+	switch scheme := strings.SplitN(dsn, "://", 2)[0]; scheme {
+	case "postgres", "postgresql":
+		return semconv.DBSystemPostgreSQL
+	case "mysql":
+		return semconv.DBSystemMySQL
+	case "cockroach", "cockroachdb", "crdb":
+		return semconv.DBSystemCockroachdb
+	case "sqlite", "sqlite3":
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemOtherSQL
+	}
+}
+
+func (t *sqlTracer) GetSpan(ctx context.Context) instrumentedsql.Span {
+	return &sqlSpan{Span: t.Tracer.GetSpan(ctx), system: t.system, ctx: ctx}
+}
+
+func (s *sqlSpan) NewChild(name string) instrumentedsql.Span {
+	child := s.Span.NewChild(name)
+	child.SetLabel(string(s.system.Key), s.system.Value.AsString())
+	return &sqlSpan{Span: child, system: s.system, ctx: s.ctx, start: time.Now()}
+}
+
+func (s *sqlSpan) Finish() {
+	if !s.start.IsZero() {
+		x.RecordTiming(s.ctx, x.TimingSQL, s.start)
+	}
+	s.Span.Finish()
+}
+
+func (s *sqlSpan) SetLabel(k, v string) {
+	switch k {
+	case "query":
+		s.Span.SetLabel(string(semconv.DBStatementKey), v)
+		if op := strings.SplitN(strings.TrimSpace(v), " ", 2)[0]; op != "" {
+			s.Span.SetLabel(string(semconv.DBOperationKey), strings.ToUpper(op))
+		}
+	case "args":
+		// Arguments may contain secrets and are not recorded.
+	default:
+		s.Span.SetLabel(k, v)
+	}
+}
+
+// noopSQLTracer is used when only the request timings are needed.
+type noopSQLTracer struct{}
+
+type noopSQLSpan struct{}
+
+func (noopSQLTracer) GetSpan(context.Context) instrumentedsql.Span { return noopSQLSpan{} }
+
+func (s noopSQLSpan) NewChild(string) instrumentedsql.Span { return s }
+func (noopSQLSpan) SetLabel(string, string)                {}
+func (noopSQLSpan) SetError(error)                         {}
+func (noopSQLSpan) Finish()                                {}
@@ -156,6 +156,14 @@ type Flow struct {
 	// refreshed (login skip=true).
 	LoginExtendSessionLifespan bool `db:"login_extend_session_lifespan"`
 
+	// LoginDeviceID is the optional device identifier set by the login provider when accepting
+	// the login request.
+	LoginDeviceID string `db:"login_device_id"`
+
+	// LoginDeviceFingerprintHash is the SHA-256 hash of the optional device fingerprint set by the
+	// login provider when accepting the login request.
+	LoginDeviceFingerprintHash string `db:"login_device_fingerprint_hash"`
+
 	// ACR sets the Authentication AuthorizationContext Class Reference value for this authentication session. You can use it
 	// to express that, for example, a user authenticated using two factor authentication.
 	ACR string `db:"acr"`
@@ -293,6 +301,8 @@ func (f *Flow) HandleLoginRequest(h *consent.HandledLoginRequest) error {
 	f.LoginRemember = h.Remember
 	f.LoginRememberFor = h.RememberFor
 	f.LoginExtendSessionLifespan = h.ExtendSessionLifespan
+	f.LoginDeviceID = h.DeviceID
+	f.LoginDeviceFingerprintHash = h.DeviceFingerprintHash
 	f.ACR = h.ACR
 	f.AMR = h.AMR
 	f.Context = h.Context
@@ -307,6 +317,8 @@ func (f *Flow) GetHandledLoginRequest() consent.HandledLoginRequest {
 		Remember:               f.LoginRemember,
 		RememberFor:            f.LoginRememberFor,
 		ExtendSessionLifespan:  f.LoginExtendSessionLifespan,
+		DeviceID:               f.LoginDeviceID,
+		DeviceFingerprintHash:  f.LoginDeviceFingerprintHash,
 		ACR:                    f.ACR,
 		AMR:                    f.AMR,
 		Subject:                f.Subject,
@@ -41,6 +41,8 @@ func (f *Flow) setHandledLoginRequest(r *consent.HandledLoginRequest) {
 	f.LoginRemember = r.Remember
 	f.LoginRememberFor = r.RememberFor
 	f.LoginExtendSessionLifespan = r.ExtendSessionLifespan
+	f.LoginDeviceID = r.DeviceID
+	f.LoginDeviceFingerprintHash = r.DeviceFingerprintHash
 	f.ACR = r.ACR
 	f.AMR = r.AMR
 	f.Subject = r.Subject
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/ory/hydra/v2/driver/config"
 	"github.com/ory/x/otelx"
@@ -368,3 +369,7 @@ func createKeys(key crypto11.Signer, kid, alg, use string) []jose.JSONWebKey {
 func (m *KeyManager) prefixKeySet(set string) string {
 	return fmt.Sprintf("%s%s", m.c.HSMKeySetPrefix(), set)
 }
+
+func (m *KeyManager) RetireKeys(_ context.Context, _ string, _ []string, _ time.Time) error {
+	return errors.WithStack(ErrPreGeneratedKeys)
+}
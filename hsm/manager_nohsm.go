@@ -9,6 +9,7 @@ package hsm
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/ory/hydra/v2/driver/config"
 	"github.com/ory/x/logrusx"
@@ -76,3 +77,7 @@ func (m *KeyManager) UpdateKey(_ context.Context, _ string, _ *jose.JSONWebKey)
 func (m *KeyManager) UpdateKeySet(_ context.Context, _ string, _ *jose.JSONWebKeySet) error {
 	return errors.WithStack(ErrOpSysNotSupported)
 }
+
+func (m *KeyManager) RetireKeys(_ context.Context, _ string, _ []string, _ time.Time) error {
+	return errors.WithStack(ErrOpSysNotSupported)
+}
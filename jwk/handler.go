@@ -6,10 +6,13 @@ package jwk
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/ory/x/httprouterx"
 
 	"github.com/gofrs/uuid"
+	"github.com/ory/herodot"
 	"github.com/pkg/errors"
 
 	"github.com/ory/x/urlx"
@@ -59,6 +62,7 @@ func (h *Handler) SetRoutes(admin *httprouterx.RouterAdmin, public *httprouterx.
 	admin.GET(KeyHandlerPath+"/:set", h.getJsonWebKeySet)
 
 	admin.POST(KeyHandlerPath+"/:set", h.createJsonWebKeySet)
+	admin.POST(KeyHandlerPath+"/:set/rotate", h.rotateJsonWebKeySet)
 
 	admin.PUT(KeyHandlerPath+"/:set/:key", h.adminUpdateJsonWebKey)
 	admin.PUT(KeyHandlerPath+"/:set", h.setJsonWebKeySet)
@@ -280,6 +284,122 @@ func (h *Handler) createJsonWebKeySet(w http.ResponseWriter, r *http.Request, ps
 	}
 }
 
+// Rotate JSON Web Key Set Request
+//
+// swagger:parameters rotateJsonWebKeySet
+type adminRotateJsonWebKeySet struct {
+	// The JSON Web Key Set ID
+	//
+	// in: path
+	// required: true
+	Set string `json:"set"`
+
+	// in: body
+	// required: true
+	Body rotateJsonWebKeySetBody
+}
+
+// Rotate JSON Web Key Set Request Body
+//
+// swagger:model rotateJsonWebKeySet
+type rotateJsonWebKeySetBody struct {
+	// JSON Web Key Algorithm
+	//
+	// The algorithm to be used for creating the new key. Supports `RS256`, `ES256`, `ES512`, `HS512`, and `HS256`.
+	//
+	// required: true
+	Algorithm string `json:"alg"`
+
+	// JSON Web Key Use
+	//
+	// The "use" (public key use) parameter of the new key. Valid values are "enc" and "sig".
+	//
+	// required: true
+	Use string `json:"use"`
+
+	// JSON Web Key ID
+	//
+	// The Key ID of the new key. If empty, a random ID is generated.
+	KeyID string `json:"kid"`
+
+	// Grace Period
+	//
+	// The duration, for example `24h`, during which the previous keys of the set remain
+	// published so that tokens signed with them can still be verified. Defaults to `0s`,
+	// which retires the previous keys immediately.
+	Grace string `json:"grace"`
+}
+
+// swagger:route POST /admin/keys/{set}/rotate jwk rotateJsonWebKeySet
+//
+// # Rotate JSON Web Key Set
+//
+// This endpoint generates a new key in the JSON Web Key Set, which is used for signing from now on, and schedules
+// all other keys of the set to be retired once the grace period has passed. Until then, they remain part of the
+// JSON Web Key Set so that tokens signed with them can still be verified.
+//
+// Keys stored in a Hardware Security Module can not be retired.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  201: jsonWebKeySet
+//	  default: errorOAuth2
+func (h *Handler) rotateJsonWebKeySet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var body rotateJsonWebKeySetBody
+	var set = ps.ByName("set")
+
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(herodot.ErrBadRequest.WithReasonf("Unable to decode the request body: %s", err)))
+		return
+	}
+
+	var grace time.Duration
+	if body.Grace != "" {
+		var err error
+		if grace, err = time.ParseDuration(body.Grace); err != nil || grace < 0 {
+			h.r.Writer().WriteError(w, r, errorsx.WithStack(herodot.ErrBadRequest.WithReasonf("The grace period must be a positive duration such as 24h but got: %s", body.Grace)))
+			return
+		}
+	}
+
+	keys, err := h.r.KeyManager().GenerateAndPersistKeySet(r.Context(), set, body.KeyID, body.Algorithm, body.Use)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	keep := make([]string, len(keys.Keys))
+	for k, key := range keys.Keys {
+		keep[k] = key.KeyID
+	}
+
+	if err := h.r.KeyManager().RetireKeys(r.Context(), set, keep, time.Now().UTC().Add(grace)); err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.SecurityEvents().Emit(r, x.SecurityEvent{
+		Type:     x.SecurityEventKeyRotated,
+		Severity: x.SecuritySeverityLow,
+		Reason:   "The JSON Web Key Set was rotated.",
+		Details: map[string]string{
+			"set":     set,
+			"key_ids": strings.Join(keep, ","),
+			"grace":   grace.String(),
+		},
+	})
+
+	keys = ExcludeOpaquePrivateKeys(keys)
+	h.r.Writer().WriteCreated(w, r, urlx.AppendPaths(h.r.Config().IssuerURL(r.Context()), "/keys/"+set).String(), keys)
+}
+
 // Set JSON Web Key Set Request
 //
 // swagger:parameters setJsonWebKeySet
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/ory/x/httprouterx"
@@ -107,3 +108,68 @@ func canonicalizeThumbprints(js jose.JSONWebKey) jose.JSONWebKey {
 	}
 	return js
 }
+
+func TestHandlerRotate(t *testing.T) {
+	conf := internal.NewConfigurationWithDefaults()
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+	if conf.HSMEnabled() {
+		t.Skip("Skipping test. Keys stored in a Hardware Security Module can not be retired.")
+	}
+	router := x.NewRouterPublic()
+	reg.KeyHandler().SetRoutes(httprouterx.NewRouterAdminWithPrefixAndRouter(router.Router, "/admin", conf.AdminURL), router, func(h http.Handler) http.Handler {
+		return h
+	})
+	testServer := httptest.NewServer(router)
+	t.Cleanup(testServer.Close)
+
+	_, err := reg.KeyManager().GenerateAndPersistKeySet(context.Background(), "rotate", "first", "ES256", "sig")
+	require.NoError(t, err)
+
+	rotate := func(t *testing.T, body string) *jose.JSONWebKeySet {
+		res, err := http.Post(testServer.URL+"/admin/keys/rotate/rotate", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var keys jose.JSONWebKeySet
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&keys))
+		return &keys
+	}
+
+	getKeySet := func(t *testing.T) *jose.JSONWebKeySet {
+		res, err := http.Get(testServer.URL + "/admin/keys/rotate")
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var keys jose.JSONWebKeySet
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&keys))
+		return &keys
+	}
+
+	t.Run("case=previous keys remain during the grace period", func(t *testing.T) {
+		rotated := rotate(t, `{"alg":"ES256","use":"sig","kid":"second","grace":"1h"}`)
+		require.Len(t, rotated.Keys, 1)
+		assert.Equal(t, "second", rotated.Keys[0].KeyID)
+
+		keys := getKeySet(t)
+		assert.Len(t, keys.Key("first"), 1)
+		assert.Len(t, keys.Key("second"), 1)
+		assert.Equal(t, "second", keys.Keys[0].KeyID, "the new key is used for signing")
+	})
+
+	t.Run("case=previous keys are retired without a grace period", func(t *testing.T) {
+		rotate(t, `{"alg":"ES256","use":"sig","kid":"third"}`)
+
+		keys := getKeySet(t)
+		require.Len(t, keys.Keys, 1)
+		assert.Equal(t, "third", keys.Keys[0].KeyID)
+	})
+
+	t.Run("case=rejects an invalid grace period", func(t *testing.T) {
+		res, err := http.Post(testServer.URL+"/admin/keys/rotate/rotate", "application/json", strings.NewReader(`{"alg":"ES256","use":"sig","grace":"soon"}`))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
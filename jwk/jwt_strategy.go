@@ -6,14 +6,21 @@ package jwk
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/ory/x/josex"
 
 	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gopkg.in/square/go-jose.v2"
+	josejwt "gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/ory/fosite"
 	"github.com/ory/hydra/v2/driver/config"
+	"github.com/ory/hydra/v2/x"
 
 	"github.com/pkg/errors"
 
@@ -72,6 +79,28 @@ func (j *DefaultJWTSigner) GetPublicKey(ctx context.Context) (jose.JSONWebKey, e
 	return josex.ToPublicKey(private), nil
 }
 
+// Generate signs the claims and records the signing key in a span.
+func (j *DefaultJWTSigner) Generate(ctx context.Context, claims jwt.MapClaims, header jwt.Mapper) (string, string, error) {
+	ctx, span := otel.GetTracerProvider().Tracer(tracingComponent).Start(ctx, "jwk.DefaultJWTSigner.Generate",
+		trace.WithAttributes(attribute.String("jwk.set", j.setID)))
+	defer span.End()
+	defer x.RecordTiming(ctx, x.TimingSigning, time.Now())
+
+	token, sig, err := j.DefaultSigner.Generate(ctx, claims, header)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return "", "", err
+	}
+
+	if parsed, err := josejwt.ParseSigned(token); err == nil && len(parsed.Headers) > 0 {
+		span.SetAttributes(
+			attribute.String("jwk.kid", parsed.Headers[0].KeyID),
+			attribute.String("jwk.alg", parsed.Headers[0].Algorithm),
+		)
+	}
+	return token, sig, nil
+}
+
 func (j *DefaultJWTSigner) getPrivateKey(ctx context.Context) (interface{}, error) {
 	private, err := j.getKeys(ctx)
 	if err != nil {
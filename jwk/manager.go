@@ -12,6 +12,7 @@ import (
 	jose "gopkg.in/square/go-jose.v2"
 
 	"github.com/ory/fosite"
+	"github.com/ory/x/sqlxx"
 )
 
 var ErrUnsupportedKeyAlgorithm = &fosite.RFC6749Error{
@@ -51,6 +52,8 @@ type (
 		DeleteKey(ctx context.Context, set, kid string) error
 
 		DeleteKeySet(ctx context.Context, set string) error
+
+		RetireKeys(ctx context.Context, set string, keep []string, at time.Time) error
 	}
 
 	SQLData struct {
@@ -63,6 +66,8 @@ type (
 		Version      int       `db:"version"`
 		CreatedAt    time.Time `db:"created_at"`
 		Key          string    `db:"keydata"`
+		// RetireAt is the time after which the key is no longer returned.
+		RetireAt sqlxx.NullTime `db:"retire_at"`
 	}
 )
 
@@ -10,6 +10,7 @@ package jwk_test
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	jose "gopkg.in/square/go-jose.v2"
@@ -139,6 +140,20 @@ func (mr *MockManagerMockRecorder) GetKeySet(ctx, set interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeySet", reflect.TypeOf((*MockManager)(nil).GetKeySet), ctx, set)
 }
 
+// RetireKeys mocks base method.
+func (m *MockManager) RetireKeys(ctx context.Context, set string, keep []string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireKeys", ctx, set, keep, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireKeys indicates an expected call of RetireKeys.
+func (mr *MockManagerMockRecorder) RetireKeys(ctx, set, keep, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireKeys", reflect.TypeOf((*MockManager)(nil).RetireKeys), ctx, set, keep, at)
+}
+
 // UpdateKey mocks base method.
 func (m *MockManager) UpdateKey(ctx context.Context, set string, key *jose.JSONWebKey) error {
 	m.ctrl.T.Helper()
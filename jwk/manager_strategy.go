@@ -5,6 +5,7 @@ package jwk
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	"go.opentelemetry.io/otel"
@@ -159,3 +160,14 @@ func (m ManagerStrategy) DeleteKeySet(ctx context.Context, set string) error {
 		return nil
 	}
 }
+
+func (m ManagerStrategy) RetireKeys(ctx context.Context, set string, keep []string, at time.Time) error {
+	ctx, span := otel.GetTracerProvider().Tracer(tracingComponent).Start(ctx, "jwk.RetireKeys")
+	defer span.End()
+	attrs := map[string]string{
+		"set": set,
+	}
+	span.SetAttributes(otelx.StringAttrs(attrs)...)
+
+	return m.softwareKeyManager.RetireKeys(ctx, set, keep, at)
+}
@@ -193,6 +193,33 @@ func TestHelperManagerGenerateAndPersistKeySet(m Manager, alg string, parallel b
 	}
 }
 
+func TestHelperManagerRetireKeys(m Manager, alg string, suffix string) func(t *testing.T) {
+	return func(t *testing.T) {
+		set := "retire" + suffix
+		_, err := m.GenerateAndPersistKeySet(context.TODO(), set, "old"+suffix, alg, "sig")
+		require.NoError(t, err)
+		_, err = m.GenerateAndPersistKeySet(context.TODO(), set, "new"+suffix, alg, "sig")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = m.DeleteKeySet(context.TODO(), set)
+		})
+
+		require.NoError(t, m.RetireKeys(context.TODO(), set, []string{"new" + suffix}, time.Now().Add(time.Hour)))
+		got, err := m.GetKeySet(context.TODO(), set)
+		require.NoError(t, err)
+		assert.Len(t, got.Key("old"+suffix), 1, "keys remain available until they are retired")
+
+		require.NoError(t, m.RetireKeys(context.TODO(), set, []string{"new" + suffix}, time.Now().Add(-time.Second)))
+		got, err = m.GetKeySet(context.TODO(), set)
+		require.NoError(t, err)
+		assert.Empty(t, got.Key("old"+suffix))
+		assert.Len(t, got.Key("new"+suffix), 1)
+
+		_, err = m.GetKey(context.TODO(), set, "old"+suffix)
+		require.Error(t, err)
+	}
+}
+
 func TestHelperManagerNIDIsolationKeySet(t1 Manager, t2 Manager, alg string) func(t *testing.T) {
 	return func(t *testing.T) {
 		_, err := t1.GetKeySet(context.TODO(), "foo")
@@ -11,6 +11,7 @@ import (
 type InternalRegistry interface {
 	x.RegistryWriter
 	x.RegistryLogger
+	x.SecurityEventsProvider
 	Registry
 }
 
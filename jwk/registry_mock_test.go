@@ -15,6 +15,7 @@ import (
 	herodot "github.com/ory/herodot"
 	config "github.com/ory/hydra/v2/driver/config"
 	jwk "github.com/ory/hydra/v2/jwk"
+	x "github.com/ory/hydra/v2/x"
 	logrusx "github.com/ory/x/logrusx"
 )
 
@@ -111,6 +112,20 @@ func (mr *MockInternalRegistryMockRecorder) Logger() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logger", reflect.TypeOf((*MockInternalRegistry)(nil).Logger))
 }
 
+// SecurityEvents mocks base method.
+func (m *MockInternalRegistry) SecurityEvents() *x.SecurityEvents {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecurityEvents")
+	ret0, _ := ret[0].(*x.SecurityEvents)
+	return ret0
+}
+
+// SecurityEvents indicates an expected call of SecurityEvents.
+func (mr *MockInternalRegistryMockRecorder) SecurityEvents() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecurityEvents", reflect.TypeOf((*MockInternalRegistry)(nil).SecurityEvents))
+}
+
 // SoftwareKeyManager mocks base method.
 func (m *MockInternalRegistry) SoftwareKeyManager() jwk.Manager {
 	m.ctrl.T.Helper()
@@ -178,6 +178,7 @@ func TestHelperRunner(t *testing.T, store InternalRegistry, k string) {
 	t.Run(fmt.Sprintf("case=testFositeStoreClientAssertionJWTValid/db=%s", k), testFositeStoreClientAssertionJWTValid(store))
 	t.Run(fmt.Sprintf("case=testHelperDeleteAccessTokens/db=%s", k), testHelperDeleteAccessTokens(store))
 	t.Run(fmt.Sprintf("case=testHelperRevokeAccessToken/db=%s", k), testHelperRevokeAccessToken(store))
+	t.Run(fmt.Sprintf("case=testHelperRevokeTokens/db=%s", k), testHelperRevokeTokens(store))
 	t.Run(fmt.Sprintf("case=testFositeJWTBearerGrantStorage/db=%s", k), testFositeJWTBearerGrantStorage(store))
 }
 
@@ -387,6 +388,53 @@ func testHelperDeleteAccessTokens(x InternalRegistry) func(t *testing.T) {
 	}
 }
 
+func testHelperRevokeTokens(reg InternalRegistry) func(t *testing.T) {
+	return func(t *testing.T) {
+		m := reg.OAuth2Storage()
+		ctx := context.Background()
+
+		cl := &client.Client{LegacyClientID: "revoke-tokens"}
+		require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+
+		create := func(signature, subject string, audience fosite.Arguments, requestedAt time.Time) {
+			req := &fosite.Request{
+				ID:              uuid.New(),
+				Client:          cl,
+				RequestedAt:     requestedAt.UTC().Round(time.Second),
+				GrantedAudience: audience,
+				Session:         &Session{DefaultSession: &openid.DefaultSession{Subject: subject}},
+			}
+			require.NoError(t, m.CreateAccessTokenSession(ctx, signature+"-at", req))
+			require.NoError(t, m.CreateRefreshTokenSession(ctx, signature+"-rt", req))
+		}
+		create("revoke-a", "revoke-alice", fosite.Arguments{"https://api.example.com", "other"}, time.Now())
+		create("revoke-b", "revoke-bob", fosite.Arguments{"api_example"}, time.Now())
+		create("revoke-c", "revoke-carol", fosite.Arguments{"apiXexample"}, time.Now().Add(-2*time.Hour))
+
+		for _, tc := range []struct {
+			filter     x.TokenFilter
+			signatures []string
+		}{
+			{filter: x.TokenFilter{ClientID: cl.GetID(), Audience: "api_example"}, signatures: []string{"revoke-b"}},
+			{filter: x.TokenFilter{ClientID: cl.GetID(), IssuedBefore: time.Now().Add(-time.Hour)}, signatures: []string{"revoke-c"}},
+			{filter: x.TokenFilter{Subject: "revoke-alice", Audience: "other"}, signatures: []string{"revoke-a"}},
+			{filter: x.TokenFilter{ClientID: cl.GetID()}},
+		} {
+			accessTokens, refreshTokens, err := m.RevokeTokens(ctx, tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, len(tc.signatures), accessTokens, "%+v", tc.filter)
+			assert.Equal(t, len(tc.signatures), refreshTokens, "%+v", tc.filter)
+
+			for _, signature := range tc.signatures {
+				_, err = m.GetAccessTokenSession(ctx, signature+"-at", &Session{})
+				assert.EqualError(t, err, fosite.ErrNotFound.Error())
+				_, err = m.GetRefreshTokenSession(ctx, signature+"-rt", &Session{})
+				assert.EqualError(t, err, fosite.ErrInactiveToken.Error())
+			}
+		}
+	}
+}
+
 func testHelperRevokeAccessToken(x InternalRegistry) func(t *testing.T) {
 	return func(t *testing.T) {
 		m := x.OAuth2Storage()
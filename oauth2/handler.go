@@ -4,11 +4,15 @@
 package oauth2
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"net/http"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -52,6 +56,7 @@ const (
 	IntrospectPath   = "/oauth2/introspect"
 	RevocationPath   = "/oauth2/revoke"
 	DeleteTokensPath = "/oauth2/tokens" // #nosec G101
+	RevokeTokensPath = "/oauth2/tokens/revoke"
 )
 
 type Handler struct {
@@ -93,6 +98,7 @@ func (h *Handler) SetRoutes(admin *httprouterx.RouterAdmin, public *httprouterx.
 
 	admin.POST(IntrospectPath, h.introspectOAuth2Token)
 	admin.DELETE(DeleteTokensPath, h.deleteOAuth2Token)
+	admin.POST(RevokeTokensPath, h.revokeOAuth2Tokens)
 }
 
 // swagger:route GET /oauth2/sessions/logout oidc revokeOidcSession
@@ -732,6 +738,7 @@ func (h *Handler) introspectOAuth2Token(w http.ResponseWriter, r *http.Request,
 	scope := r.PostForm.Get("scope")
 
 	tt, ar, err := h.r.OAuth2Provider().IntrospectToken(ctx, token, fosite.TokenType(tokenType), session, strings.Split(scope, " ")...)
+	h.r.PrometheusMetrics().CountIntrospection(introspectionCaller(r), err == nil)
 	if err != nil {
 		x.LogAudit(r, err, h.r.Logger())
 		err := errorsx.WithStack(fosite.ErrInactiveToken.WithHint("An introspection strategy indicated that the token is inactive.").WithDebug(err.Error()))
@@ -870,7 +877,24 @@ func (h *Handler) oauth2TokenExchange(w http.ResponseWriter, r *http.Request) {
 	var session = NewSessionWithCustomClaims("", h.c.AllowedTopLevelClaims(r.Context()))
 	var ctx = r.Context()
 
-	accessRequest, err := h.r.OAuth2Provider().NewAccessRequest(ctx, r, session)
+	var timings *x.Timings
+	if h.c.TokenProfiling(ctx) {
+		ctx, timings = x.WithTimings(ctx)
+		r = r.WithContext(ctx)
+	}
+
+	var err error
+	var accessRequest fosite.AccessRequester
+	start := time.Now()
+	defer func() {
+		h.r.Metrics(ctx).RecordTokenIssuance(ctx, tokenGrantType(accessRequest), start, err)
+		h.r.PrometheusMetrics().ObserveEndpoint(ctx, "token", start)
+		h.r.PrometheusMetrics().CountTokenRequest(tokenGrantType(accessRequest), requestClientID(accessRequest), err)
+		h.emitTokenSecurityEvent(r, accessRequest, err)
+		h.countAnomalies(r, "token", accessRequest, err)
+	}()
+
+	accessRequest, err = h.r.OAuth2Provider().NewAccessRequest(ctx, r, session)
 	if err != nil {
 		h.logOrAudit(err, r)
 		h.r.OAuth2Provider().WriteAccessError(ctx, w, accessRequest, err)
@@ -920,7 +944,7 @@ func (h *Handler) oauth2TokenExchange(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for _, hook := range h.r.AccessRequestHooks() {
-		if err := hook(ctx, accessRequest); err != nil {
+		if err = hook(ctx, accessRequest); err != nil {
 			h.logOrAudit(err, r)
 			h.r.OAuth2Provider().WriteAccessError(ctx, w, accessRequest, err)
 			return
@@ -934,9 +958,24 @@ func (h *Handler) oauth2TokenExchange(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if timings != nil {
+		h.logTokenMinting(r, accessRequest, timings, start)
+	}
 	h.r.OAuth2Provider().WriteAccessResponse(ctx, w, accessRequest, accessResponse)
 }
 
+// logTokenMinting logs the time spent in each phase of minting the tokens. The timings are only
+// logged and never returned to the client, as they disclose internals of the deployment.
+func (h *Handler) logTokenMinting(r *http.Request, ar fosite.AccessRequester, timings *x.Timings, start time.Time) {
+	h.r.Logger().
+		WithRequest(r).
+		WithFields(timings.Fields()).
+		WithField("grant_type", tokenGrantType(ar)).
+		WithField("client_id", requestClientID(ar)).
+		WithField("took", time.Since(start).String()).
+		Info("Minted the tokens.")
+}
+
 // swagger:route GET /oauth2/auth oAuth2 oAuth2Authorize
 //
 // # OAuth 2.0 Authorize Endpoint
@@ -956,8 +995,10 @@ func (h *Handler) oauth2TokenExchange(w http.ResponseWriter, r *http.Request) {
 //	  default: errorOAuth2
 func (h *Handler) oAuth2Authorize(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	ctx := r.Context()
+	defer h.r.PrometheusMetrics().ObserveEndpoint(ctx, "authorize", time.Now())
 
 	authorizeRequest, err := h.r.OAuth2Provider().NewAuthorizeRequest(ctx, r)
+	h.r.PrometheusMetrics().CountAuthorizeRequest(authorizeResponseType(authorizeRequest), requestClientID(authorizeRequest))
 	if err != nil {
 		x.LogError(r, err, h.r.Logger())
 		h.writeAuthorizeError(w, r, authorizeRequest, err)
@@ -1101,6 +1142,97 @@ func (h *Handler) deleteOAuth2Token(w http.ResponseWriter, r *http.Request, _ ht
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Revoke OAuth 2.0 Tokens Request Body
+//
+// swagger:model revokeOAuth2TokensBody
+type revokeOAuth2TokensBody struct {
+	// Only revoke tokens issued to this OAuth 2.0 Client ID.
+	ClientID string `json:"client_id"`
+
+	// Only revoke tokens issued to this subject.
+	Subject string `json:"subject"`
+
+	// Only revoke tokens which were granted this audience.
+	Audience string `json:"audience"`
+
+	// Only revoke tokens issued before this time.
+	IssuedBefore *time.Time `json:"issued_before"`
+}
+
+// Revoke OAuth 2.0 Tokens Request
+//
+// swagger:parameters revokeOAuth2Tokens
+type revokeOAuth2Tokens struct {
+	// in: body
+	// required: true
+	Body revokeOAuth2TokensBody
+}
+
+// Revoked OAuth 2.0 Tokens
+//
+// swagger:model revokedOAuth2Tokens
+type revokedOAuth2Tokens struct {
+	// The number of access tokens which were revoked.
+	AccessTokens int `json:"access_tokens"`
+
+	// The number of refresh tokens which were revoked.
+	RefreshTokens int `json:"refresh_tokens"`
+}
+
+// swagger:route POST /admin/oauth2/tokens/revoke oAuth2 revokeOAuth2Tokens
+//
+// # Revoke OAuth 2.0 Tokens in Bulk
+//
+// This endpoint revokes all access and refresh tokens matching the given filters, for example
+// all tokens of a compromised OAuth 2.0 Client or subject. At least one filter is required.
+// Revoked access tokens are deleted while revoked refresh tokens are kept as inactive.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: revokedOAuth2Tokens
+//	  default: errorOAuth2
+func (h *Handler) revokeOAuth2Tokens(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var body revokeOAuth2TokensBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithHintf("Unable to decode the request body: %s", err)))
+		return
+	}
+
+	filter := x.TokenFilter{ClientID: body.ClientID, Subject: body.Subject, Audience: body.Audience}
+	if body.IssuedBefore != nil {
+		filter.IssuedBefore = *body.IssuedBefore
+	}
+	if filter.IsEmpty() {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithHint("At least one of 'client_id', 'subject', 'audience' and 'issued_before' must be set.")))
+		return
+	}
+
+	accessTokens, refreshTokens, err := h.r.OAuth2Storage().RevokeTokens(r.Context(), filter)
+	if err != nil {
+		h.r.Writer().WriteError(w, r, err)
+		return
+	}
+
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("client_id", filter.ClientID).
+		WithField("subject", filter.Subject).
+		WithField("audience", filter.Audience).
+		WithField("issued_before", filter.IssuedBefore).
+		WithField("access_tokens", accessTokens).
+		WithField("refresh_tokens", refreshTokens).
+		Info("Revoked OAuth 2.0 tokens in bulk.")
+
+	h.r.Writer().Write(w, r, &revokedOAuth2Tokens{AccessTokens: accessTokens, RefreshTokens: refreshTokens})
+}
+
 // This function will not be called, OPTIONS request will be handled by cors
 // this is just a placeholder.
 func (h *Handler) handleOptions(w http.ResponseWriter, r *http.Request) {}
@@ -1112,6 +1244,8 @@ func (h *Handler) forwardError(w http.ResponseWriter, r *http.Request, err error
 }
 
 func (h *Handler) writeAuthorizeError(w http.ResponseWriter, r *http.Request, ar fosite.AuthorizeRequester, err error) {
+	h.countAnomalies(r, "authorize", ar, err)
+
 	if !ar.IsRedirectURIValid() {
 		h.forwardError(w, r, err)
 		return
@@ -1120,6 +1254,197 @@ func (h *Handler) writeAuthorizeError(w http.ResponseWriter, r *http.Request, ar
 	h.r.OAuth2Provider().WriteAuthorizeError(r.Context(), w, ar, err)
 }
 
+// tokenGrantType returns the grant type recorded in the token endpoint metrics. Grant types which
+// Ory Hydra does not support are reported as "unknown" to keep the number of time series bounded.
+func tokenGrantType(ar fosite.AccessRequester) string {
+	if ar == nil || len(ar.GetGrantTypes()) != 1 {
+		return "unknown"
+	}
+
+	switch gt := ar.GetGrantTypes()[0]; gt {
+	case "authorization_code", "refresh_token", "client_credentials", "urn:ietf:params:oauth:grant-type:jwt-bearer":
+		return gt
+	default:
+		return "unknown"
+	}
+}
+
+// authorizeResponseType returns the response type recorded in the authorization endpoint metrics.
+// Response types containing values other than code, token and id_token are reported as "unknown".
+func authorizeResponseType(ar fosite.AuthorizeRequester) string {
+	if ar == nil || len(ar.GetResponseTypes()) == 0 {
+		return "unknown"
+	}
+
+	types := make([]string, 0, len(ar.GetResponseTypes()))
+	for _, rt := range ar.GetResponseTypes() {
+		switch rt {
+		case "code", "token", "id_token":
+			types = append(types, rt)
+		default:
+			return "unknown"
+		}
+	}
+	sort.Strings(types)
+	return strings.Join(types, " ")
+}
+
+// introspectionCaller identifies the caller of the introspection endpoint by the username of the
+// HTTP Basic Authorization header, which resource servers usually set to their client ID. The
+// credentials are not verified because the endpoint is part of the administrative API.
+func introspectionCaller(r *http.Request) string {
+	if username, _, ok := r.BasicAuth(); ok && username != "" {
+		return username
+	}
+	return "anonymous"
+}
+
+func requestClientID(ar fosite.Requester) string {
+	if ar == nil || ar.GetClient() == nil {
+		return ""
+	}
+	return ar.GetClient().GetID()
+}
+
+// emitTokenSecurityEvent emits a security event if a token request failed because of client
+// authentication, token reuse or a reused JWT ID.
+func (h *Handler) emitTokenSecurityEvent(r *http.Request, ar fosite.AccessRequester, err error) {
+	if err == nil {
+		return
+	}
+
+	grantType := tokenGrantType(ar)
+	e := x.SecurityEvent{ClientID: requestClientID(ar), Details: map[string]string{"grant_type": grantType}}
+	switch {
+	case errors.Is(err, fosite.ErrJTIKnown):
+		e.Type = x.SecurityEventDenyListHit
+		e.Severity = x.SecuritySeverityHigh
+		e.Reason = "A JWT ID which was already used was presented again."
+	case errors.Is(err, fosite.ErrInvalidClient):
+		e.Type = x.SecurityEventClientAuthenticationFailed
+		e.Severity = x.SecuritySeverityMedium
+		e.Reason = "The OAuth 2.0 Client could not be authenticated."
+		if e.ClientID == "" {
+			// The client is unknown or could not be authenticated, so report the claimed client ID.
+			if username, _, ok := r.BasicAuth(); ok {
+				e.ClientID = username
+			} else {
+				e.ClientID = r.PostForm.Get("client_id")
+			}
+		}
+	case grantType == "refresh_token" && errors.Is(err, fosite.ErrInactiveToken),
+		grantType == "authorization_code" && strings.HasPrefix(fosite.ErrorToRFC6749Error(err).HintField, "The authorization code has already been used."):
+		e.Type = x.SecurityEventTokenReuseDetected
+		e.Severity = x.SecuritySeverityHigh
+		e.Reason = "A token was used more than once, all tokens of the grant were revoked."
+	default:
+		return
+	}
+
+	h.r.SecurityEvents().Emit(r, e)
+}
+
+// countAnomalies counts failed requests which indicate probing or a misconfigured integration:
+// unknown or unauthenticated clients, redirect URI mismatches and failed PKCE checks.
+func (h *Handler) countAnomalies(r *http.Request, endpoint string, ar fosite.Requester, err error) {
+	if err == nil {
+		return
+	}
+
+	ctx := r.Context()
+	switch {
+	case errors.Is(err, fosite.ErrInvalidClient):
+		h.r.PrometheusMetrics().CountInvalidClient(x.TrustedClientIP(r, h.c.TLS(ctx, config.PublicInterface).AllowTerminationFrom()))
+	case endpoint == "authorize" && errors.Is(err, fosite.ErrInvalidRequest):
+		if h.isRedirectURIMismatch(ar) {
+			h.r.PrometheusMetrics().CountRedirectURIMismatch(endpoint, requestClientID(ar))
+		} else if h.isPKCEChallengeRejected(ctx, ar) {
+			h.r.PrometheusMetrics().CountPKCEFailure(endpoint, requestClientID(ar))
+		}
+	case endpoint == "token" && errors.Is(err, fosite.ErrInvalidGrant):
+		h.countAuthorizeCodeGrantAnomalies(ctx, ar)
+	}
+}
+
+// isRedirectURIMismatch returns true if the authorization request names a redirect URI which is not
+// registered for the client.
+func (h *Handler) isRedirectURIMismatch(ar fosite.Requester) bool {
+	if ar == nil || ar.GetClient() == nil {
+		return false
+	}
+
+	redirectURI := ar.GetRequestForm().Get("redirect_uri")
+	if redirectURI == "" {
+		return false
+	}
+
+	_, err := fosite.MatchRedirectURIWithClientRedirectURIs(redirectURI, ar.GetClient())
+	return err != nil
+}
+
+// isPKCEChallengeRejected returns true if the code challenge of the authorization request is refused
+// by the PKCE settings.
+func (h *Handler) isPKCEChallengeRejected(ctx context.Context, ar fosite.Requester) bool {
+	if ar == nil || ar.GetClient() == nil {
+		return false
+	}
+
+	pkceConfig := h.r.OAuth2ProviderConfig()
+	form := ar.GetRequestForm()
+	if form.Get("code_challenge") == "" {
+		return pkceConfig.GetEnforcePKCE(ctx) || (pkceConfig.GetEnforcePKCEForPublicClients(ctx) && ar.GetClient().IsPublic())
+	}
+
+	switch form.Get("code_challenge_method") {
+	case "S256":
+		return false
+	case "plain", "":
+		return !pkceConfig.GetEnablePKCEPlainChallengeMethod(ctx)
+	default:
+		return true
+	}
+}
+
+// countAuthorizeCodeGrantAnomalies replays the checks of the authorization code grant against the stored
+// authorization request to find out whether it was refused because of the redirect URI or the code verifier.
+func (h *Handler) countAuthorizeCodeGrantAnomalies(ctx context.Context, ar fosite.Requester) {
+	if ar == nil || ar.GetClient() == nil {
+		return
+	}
+
+	form := ar.GetRequestForm()
+	if form.Get("grant_type") != "authorization_code" {
+		return
+	}
+
+	signature := h.r.OAuth2HMACStrategy().AuthorizeCodeSignature(ctx, form.Get("code"))
+	authorizeRequest, err := h.r.OAuth2Storage().GetAuthorizeCodeSession(ctx, signature, NewSession(""))
+	if err != nil || authorizeRequest.GetClient().GetID() != ar.GetClient().GetID() {
+		return
+	}
+
+	authorizeForm := authorizeRequest.GetRequestForm()
+	if redirectURI := authorizeForm.Get("redirect_uri"); redirectURI != "" && redirectURI != form.Get("redirect_uri") {
+		h.r.PrometheusMetrics().CountRedirectURIMismatch("token", requestClientID(ar))
+		return
+	}
+
+	challenge, verifier := authorizeForm.Get("code_challenge"), form.Get("code_verifier")
+	if challenge == "" && verifier == "" {
+		return
+	}
+
+	expected := verifier
+	if authorizeForm.Get("code_challenge_method") == "S256" {
+		hash := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+
+	if len(verifier) < 43 || len(verifier) > 128 || expected != challenge {
+		h.r.PrometheusMetrics().CountPKCEFailure("token", requestClientID(ar))
+	}
+}
+
 func (h *Handler) logOrAudit(err error, r *http.Request) {
 	if errors.Is(err, fosite.ErrServerError) || errors.Is(err, fosite.ErrTemporarilyUnavailable) || errors.Is(err, fosite.ErrMisconfiguration) {
 		x.LogError(r, err, h.r.Logger())
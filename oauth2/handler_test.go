@@ -28,6 +28,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -86,6 +87,67 @@ func TestHandlerDeleteHandler(t *testing.T) {
 	require.Error(t, err, "not_found")
 }
 
+func TestHandlerRevokeTokens(t *testing.T) {
+	ctx := context.Background()
+	conf := internal.NewConfigurationWithDefaults()
+	conf.MustSet(ctx, config.KeyIssuerURL, "http://hydra.localhost")
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+
+	store := reg.OAuth2Storage()
+	h := oauth2.NewHandler(reg, conf)
+
+	cl := &client.Client{LegacyClientID: "compromised"}
+	require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+	for _, subject := range []string{"alice", "bob"} {
+		req := &fosite.Request{
+			ID:          "revoke-" + subject,
+			RequestedAt: time.Now().UTC().Round(time.Second),
+			Client:      cl,
+			Session:     &oauth2.Session{DefaultSession: &openid.DefaultSession{Subject: subject}},
+		}
+		require.NoError(t, store.CreateAccessTokenSession(ctx, req.ID, req))
+		require.NoError(t, store.CreateRefreshTokenSession(ctx, req.ID, req))
+	}
+
+	r := x.NewRouterAdmin(conf.AdminURL)
+	h.SetRoutes(r, &httprouterx.RouterPublic{Router: r.Router}, func(h http.Handler) http.Handler {
+		return h
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	revoke := func(t *testing.T, body string) (int, []byte) {
+		res, err := ts.Client().Post(ts.URL+"/admin"+oauth2.RevokeTokensPath, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res.StatusCode, raw
+	}
+
+	t.Run("case=requires a filter", func(t *testing.T) {
+		code, body := revoke(t, `{}`)
+		assert.Equal(t, http.StatusBadRequest, code, "%s", body)
+	})
+
+	t.Run("case=revokes the tokens matching the filter", func(t *testing.T) {
+		code, body := revoke(t, `{"client_id":"compromised","subject":"alice"}`)
+		require.Equal(t, http.StatusOK, code, "%s", body)
+		assert.JSONEq(t, `{"access_tokens":1,"refresh_tokens":1}`, string(body))
+
+		_, err := store.GetAccessTokenSession(ctx, "revoke-alice", new(oauth2.Session))
+		assert.Error(t, err)
+		_, err = store.GetAccessTokenSession(ctx, "revoke-bob", new(oauth2.Session))
+		assert.NoError(t, err)
+	})
+
+	t.Run("case=revokes the tokens issued before a time", func(t *testing.T) {
+		code, body := revoke(t, fmt.Sprintf(`{"issued_before":%q}`, time.Now().Add(time.Minute).UTC().Format(time.RFC3339)))
+		require.Equal(t, http.StatusOK, code, "%s", body)
+		assert.JSONEq(t, `{"access_tokens":1,"refresh_tokens":1}`, string(body))
+	})
+}
+
 func TestUserinfo(t *testing.T) {
 	ctx := context.Background()
 	conf := internal.NewConfigurationWithDefaults()
@@ -369,3 +431,84 @@ func TestHandlerWellKnown(t *testing.T) {
 		snapshotx.SnapshotT(t, wellKnownResp)
 	})
 }
+
+func TestHandlerAnomalyMetrics(t *testing.T) {
+	ctx := context.Background()
+	conf := internal.NewConfigurationWithDefaults()
+	reg := internal.NewRegistryMemory(t, conf, &contextx.Default{})
+
+	h := oauth2.NewHandler(reg, conf)
+	r := x.NewRouterAdmin(conf.AdminURL)
+	h.SetRoutes(r, &httprouterx.RouterPublic{Router: r.Router}, func(h http.Handler) http.Handler {
+		return h
+	})
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	counter := func(t *testing.T, name string, labels map[string]string) float64 {
+		families, err := prometheus.DefaultGatherer.Gather()
+		require.NoError(t, err)
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				matches := true
+				for _, label := range metric.GetLabel() {
+					if labels[label.GetName()] != label.GetValue() {
+						matches = false
+					}
+				}
+				if matches {
+					return metric.GetCounter().GetValue()
+				}
+			}
+		}
+		return 0
+	}
+
+	t.Run("case=invalid client is counted by the trusted client ip", func(t *testing.T) {
+		requestToken := func(t *testing.T) {
+			req, err := http.NewRequest("POST", ts.URL+"/oauth2/token", strings.NewReader(url.Values{"grant_type": {"client_credentials"}}.Encode()))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			req.SetBasicAuth("i-do-not-exist", "secret")
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		}
+
+		remote := counter(t, "hydra_oauth2_invalid_client_total", map[string]string{"ip_address": "127.0.0.1"})
+		requestToken(t)
+		assert.Equal(t, remote+1, counter(t, "hydra_oauth2_invalid_client_total", map[string]string{"ip_address": "127.0.0.1"}))
+		assert.Zero(t, counter(t, "hydra_oauth2_invalid_client_total", map[string]string{"ip_address": "198.51.100.7"}), "forwarded headers of untrusted peers must be ignored")
+
+		conf.MustSet(ctx, config.KeyTLSAllowTerminationFrom, []string{"127.0.0.1/32"})
+		t.Cleanup(func() { conf.MustSet(ctx, config.KeyTLSAllowTerminationFrom, []string{}) })
+		requestToken(t)
+		assert.Equal(t, 1.0, counter(t, "hydra_oauth2_invalid_client_total", map[string]string{"ip_address": "198.51.100.7"}))
+	})
+
+	t.Run("case=redirect uri mismatch is counted", func(t *testing.T) {
+		cl := &client.Client{LegacyClientID: "anomaly-metrics", RedirectURIs: []string{"https://client.example.com/callback"}, ResponseTypes: []string{"code"}, GrantTypes: []string{"authorization_code"}}
+		require.NoError(t, reg.ClientManager().CreateClient(ctx, cl))
+
+		labels := map[string]string{"endpoint": "authorize", "client_id": cl.GetID()}
+		before := counter(t, "hydra_oauth2_redirect_uri_mismatches_total", labels)
+
+		hc := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		res, err := hc.Get(ts.URL + "/oauth2/auth?" + url.Values{
+			"client_id":     {cl.GetID()},
+			"response_type": {"code"},
+			"redirect_uri":  {"https://attacker.example.com/callback"},
+			"state":         {"some-long-enough-state"},
+		}.Encode())
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		assert.Equal(t, before+1, counter(t, "hydra_oauth2_redirect_uri_mismatches_total", labels))
+		assert.Zero(t, counter(t, "hydra_oauth2_pkce_failures_total", labels))
+	})
+}
@@ -53,6 +53,10 @@ func (c *consentMock) HandleHeadlessLogout(ctx context.Context, w http.ResponseW
 	panic("not implemented")
 }
 
+func (c *consentMock) HandleHeadlessSubjectLogout(ctx context.Context, w http.ResponseWriter, r *http.Request, subject string) error {
+	panic("not implemented")
+}
+
 func (c *consentMock) ObfuscateSubjectIdentifier(ctx context.Context, cl fosite.Client, subject, forcedIdentifier string) (string, error) {
 	if c, ok := cl.(*client.Client); ok && c.SubjectType == "pairwise" {
 		panic("not implemented")
@@ -50,6 +50,7 @@ type RefreshTokenHookRequest struct {
 func RefreshTokenHook(reg interface {
 	config.Provider
 	x.HTTPClientProvider
+	x.MetricsProvider
 }) AccessRequestHook {
 	return func(ctx context.Context, requester fosite.AccessRequester) error {
 		hookURL := reg.Config().TokenRefreshHookURL(ctx)
@@ -92,7 +93,7 @@ func RefreshTokenHook(reg interface {
 			)
 		}
 
-		err = executeHookAndUpdateSession(ctx, reg, hookURL, reqBodyBytes, session)
+		err = executeHookAndUpdateSession(ctx, reg, "refresh_token_hook", hookURL, reqBodyBytes, session)
 		if err != nil {
 			return err
 		}
@@ -19,6 +19,9 @@ type InternalRegistry interface {
 	trust.Registry
 	x.RegistryWriter
 	x.RegistryLogger
+	x.MetricsProvider
+	x.PrometheusMetricsProvider
+	x.SecurityEventsProvider
 	consent.Registry
 	Registry
 }
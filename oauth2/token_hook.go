@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/hashicorp/go-retryablehttp"
 
@@ -57,7 +58,14 @@ type TokenHookResponse struct {
 	Session consent.AcceptOAuth2ConsentRequestSession `json:"session"`
 }
 
-func executeHookAndUpdateSession(ctx context.Context, reg x.HTTPClientProvider, hookURL *url.URL, reqBodyBytes []byte, session *Session) error {
+func executeHookAndUpdateSession(ctx context.Context, reg interface {
+	x.HTTPClientProvider
+	x.MetricsProvider
+}, hook string, hookURL *url.URL, reqBodyBytes []byte, session *Session) error {
+	ctx, span := x.StartWebhookSpan(ctx, hook, hookURL.String())
+	defer span.End()
+	defer x.RecordTiming(ctx, x.TimingHooks, time.Now())
+
 	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, hookURL.String(), bytes.NewReader(reqBodyBytes))
 	if err != nil {
 		return errorsx.WithStack(
@@ -69,7 +77,9 @@ func executeHookAndUpdateSession(ctx context.Context, reg x.HTTPClientProvider,
 	}
 	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
 
+	start := time.Now()
 	resp, err := reg.HTTPClient(ctx).Do(req)
+	reg.Metrics(ctx).RecordWebhook(ctx, hook, start, err)
 	if err != nil {
 		return errorsx.WithStack(
 			fosite.ErrServerError.
@@ -121,6 +131,7 @@ func executeHookAndUpdateSession(ctx context.Context, reg x.HTTPClientProvider,
 func TokenHook(reg interface {
 	config.Provider
 	x.HTTPClientProvider
+	x.MetricsProvider
 }) AccessRequestHook {
 	return func(ctx context.Context, requester fosite.AccessRequester) error {
 		hookURL := reg.Config().TokenHookURL(ctx)
@@ -156,7 +167,7 @@ func TokenHook(reg interface {
 			)
 		}
 
-		err = executeHookAndUpdateSession(ctx, reg, hookURL, reqBodyBytes, session)
+		err = executeHookAndUpdateSession(ctx, reg, "token_hook", hookURL, reqBodyBytes, session)
 		if err != nil {
 			return err
 		}
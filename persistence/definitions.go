@@ -25,6 +25,7 @@ type (
 		trust.GrantManager
 
 		MigrationStatus(ctx context.Context) (popx.MigrationStatuses, error)
+		MigrationChecksums(ctx context.Context) (map[string]string, error)
 		MigrateDown(context.Context, int) error
 		MigrateUp(context.Context) error
 		PrepareMigration(context.Context) error
@@ -0,0 +1,4 @@
+ALTER TABLE hydra_oauth2_flow DROP COLUMN login_device_id;
+ALTER TABLE hydra_oauth2_authentication_session DROP COLUMN device_id;
+ALTER TABLE hydra_oauth2_authentication_session DROP COLUMN user_agent_hash;
+ALTER TABLE hydra_oauth2_authentication_session DROP COLUMN ip_address;
@@ -0,0 +1,4 @@
+ALTER TABLE hydra_oauth2_authentication_session ADD COLUMN ip_address VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE hydra_oauth2_authentication_session ADD COLUMN user_agent_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE hydra_oauth2_authentication_session ADD COLUMN device_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE hydra_oauth2_flow ADD COLUMN login_device_id VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS hydra_oauth2_consent_decision
+(
+    id                   UUID                    PRIMARY KEY,
+    nid                  UUID                    NOT NULL REFERENCES networks(id) ON DELETE CASCADE,
+    consent_challenge_id VARCHAR(40)             NOT NULL,
+    decision             VARCHAR(10)             NOT NULL,
+    subject              VARCHAR(255)            NOT NULL,
+    client_id            VARCHAR(255)            NOT NULL,
+    login_session_id     VARCHAR(40)             NULL,
+    granted_scope        jsonb                   NOT NULL DEFAULT '[]',
+    denied_scope         jsonb                   NOT NULL DEFAULT '[]',
+    granted_audience     jsonb                   NOT NULL DEFAULT '[]',
+    remember             BOOLEAN                 NOT NULL DEFAULT FALSE,
+    remember_for         INTEGER                 NOT NULL DEFAULT 0,
+    error                VARCHAR(255)            NOT NULL DEFAULT '',
+    created_at           TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX hydra_oauth2_consent_decision_subject_idx ON hydra_oauth2_consent_decision (nid, subject, created_at);
+CREATE INDEX hydra_oauth2_consent_decision_client_id_idx ON hydra_oauth2_consent_decision (nid, client_id, created_at);
@@ -0,0 +1 @@
+ALTER TABLE hydra_client DROP COLUMN pre_logout_hook_url;
@@ -0,0 +1 @@
+ALTER TABLE hydra_client ADD COLUMN pre_logout_hook_url VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE hydra_client DROP COLUMN backchannel_logout_subject_wide;
@@ -0,0 +1 @@
+ALTER TABLE hydra_client ADD COLUMN backchannel_logout_subject_wide BOOL NOT NULL DEFAULT FALSE;
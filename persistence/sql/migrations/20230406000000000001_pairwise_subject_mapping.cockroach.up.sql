@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS hydra_oauth2_pairwise_subject_mapping
+(
+    nid              UUID                    NOT NULL REFERENCES networks(id) ON DELETE CASCADE,
+    client_id        VARCHAR(255)            NOT NULL,
+    subject          VARCHAR(255)            NOT NULL,
+    previous_subject VARCHAR(255)            NOT NULL,
+    current_subject  VARCHAR(255)            NOT NULL,
+    expires_at       TIMESTAMP               NOT NULL,
+    created_at       TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (nid, client_id, subject)
+);
+
+CREATE INDEX hydra_oauth2_pairwise_subject_mapping_expires_at_idx ON hydra_oauth2_pairwise_subject_mapping (nid, client_id, expires_at);
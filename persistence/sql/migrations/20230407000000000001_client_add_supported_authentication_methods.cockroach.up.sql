@@ -0,0 +1 @@
+ALTER TABLE hydra_client ADD COLUMN supported_authentication_methods jsonb DEFAULT '[]' NOT NULL;
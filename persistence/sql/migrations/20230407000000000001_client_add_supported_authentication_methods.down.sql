@@ -0,0 +1 @@
+ALTER TABLE hydra_client DROP COLUMN supported_authentication_methods;
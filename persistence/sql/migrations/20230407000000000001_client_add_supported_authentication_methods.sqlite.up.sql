@@ -0,0 +1 @@
+ALTER TABLE hydra_client ADD COLUMN supported_authentication_methods TEXT NOT NULL DEFAULT '[]';
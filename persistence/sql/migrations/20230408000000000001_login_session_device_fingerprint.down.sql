@@ -0,0 +1,2 @@
+ALTER TABLE hydra_oauth2_flow DROP COLUMN login_device_fingerprint_hash;
+ALTER TABLE hydra_oauth2_authentication_session DROP COLUMN device_fingerprint_hash;
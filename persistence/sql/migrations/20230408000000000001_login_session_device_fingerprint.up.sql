@@ -0,0 +1,2 @@
+ALTER TABLE hydra_oauth2_authentication_session ADD COLUMN device_fingerprint_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE hydra_oauth2_flow ADD COLUMN login_device_fingerprint_hash VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1 @@
+ALTER TABLE hydra_jwk DROP COLUMN retire_at;
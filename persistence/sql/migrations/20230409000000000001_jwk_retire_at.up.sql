@@ -0,0 +1 @@
+ALTER TABLE hydra_jwk ADD COLUMN retire_at TIMESTAMP NULL;
@@ -0,0 +1 @@
+ALTER TABLE hydra_client ADD COLUMN required_acr TEXT NOT NULL DEFAULT '[]';
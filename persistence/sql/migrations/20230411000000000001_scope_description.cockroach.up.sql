@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS hydra_oauth2_scope_description
+(
+    nid         UUID                    NOT NULL REFERENCES networks(id) ON DELETE CASCADE,
+    scope       VARCHAR(255)            NOT NULL,
+    locale      VARCHAR(35)             NOT NULL,
+    name        VARCHAR(255)            NOT NULL DEFAULT '',
+    description TEXT                    NOT NULL,
+    updated_at  TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (nid, scope, locale)
+);
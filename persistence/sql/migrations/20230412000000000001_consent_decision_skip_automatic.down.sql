@@ -0,0 +1,2 @@
+ALTER TABLE hydra_oauth2_consent_decision DROP COLUMN automatic;
+ALTER TABLE hydra_oauth2_consent_decision DROP COLUMN skip;
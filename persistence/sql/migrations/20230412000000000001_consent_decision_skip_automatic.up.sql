@@ -0,0 +1,2 @@
+ALTER TABLE hydra_oauth2_consent_decision ADD COLUMN skip BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE hydra_oauth2_consent_decision ADD COLUMN automatic BOOLEAN NOT NULL DEFAULT FALSE;
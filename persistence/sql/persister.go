@@ -52,6 +52,7 @@ type (
 		contextx.Provider
 		x.RegistryLogger
 		x.TracingProvider
+		x.MetricsProvider
 	}
 )
 
@@ -5,6 +5,7 @@ package sql
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid"
 
@@ -14,12 +15,14 @@ import (
 
 	"github.com/ory/fosite"
 	"github.com/ory/hydra/v2/client"
+	"github.com/ory/hydra/v2/x"
 	"github.com/ory/x/sqlcon"
 )
 
 func (p *Persister) GetConcreteClient(ctx context.Context, id string) (*client.Client, error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetConcreteClient")
 	defer span.End()
+	defer x.RecordTiming(ctx, x.TimingClientLookup, time.Now())
 
 	var cl client.Client
 	if err := p.QueryWithNetwork(ctx).Where("id = ?", id).First(&cl); err != nil {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/gobuffalo/pop/v6"
+	"github.com/gofrs/uuid"
 
 	"github.com/ory/x/sqlxx"
 
@@ -155,6 +156,206 @@ func (p *Persister) GetForcedObfuscatedLoginSession(ctx context.Context, client,
 	return &s, nil
 }
 
+func (p *Persister) SetScopeDescriptions(ctx context.Context, scope string, descriptions []consent.ScopeDescription) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.SetScopeDescriptions")
+	defer span.End()
+
+	nid := p.NetworkID(ctx)
+	now := time.Now().UTC().Round(time.Second)
+	return p.transaction(ctx, func(ctx context.Context, c *pop.Connection) error {
+		if err := c.RawQuery(
+			"DELETE FROM hydra_oauth2_scope_description WHERE nid = ? AND scope = ?",
+			nid,
+			scope,
+		).Exec(); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		for i := range descriptions {
+			d := &descriptions[i]
+			d.NID = nid
+			d.Scope = scope
+			d.UpdatedAt = now
+			if err := c.RawQuery(
+				"INSERT INTO hydra_oauth2_scope_description (nid, scope, locale, name, description, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+				d.NID,
+				d.Scope,
+				d.Locale,
+				d.Name,
+				d.Description,
+				d.UpdatedAt,
+			).Exec(); err != nil {
+				return sqlcon.HandleError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+func (p *Persister) GetScopeDescriptions(ctx context.Context, scopes []string) ([]consent.ScopeDescription, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetScopeDescriptions")
+	defer span.End()
+
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+
+	args := make([]interface{}, len(scopes))
+	for k, scope := range scopes {
+		args[k] = scope
+	}
+
+	var ds []consent.ScopeDescription
+	if err := p.QueryWithNetwork(ctx).
+		Where("scope IN (?)", args...).
+		Order("scope ASC, locale ASC").
+		All(&ds); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ds, nil
+}
+
+func (p *Persister) scopeDescriptionQuery(ctx context.Context, scope string) *pop.Query {
+	q := p.QueryWithNetwork(ctx)
+	if scope != "" {
+		q = q.Where("scope = ?", scope)
+	}
+	return q
+}
+
+func (p *Persister) FindScopeDescriptions(ctx context.Context, scope string, limit, offset int) ([]consent.ScopeDescription, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindScopeDescriptions")
+	defer span.End()
+
+	var ds []consent.ScopeDescription
+	if err := p.scopeDescriptionQuery(ctx, scope).
+		Order("scope ASC, locale ASC").
+		Paginate(offset/limit+1, limit).
+		All(&ds); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ds, nil
+}
+
+func (p *Persister) CountScopeDescriptions(ctx context.Context, scope string) (int, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CountScopeDescriptions")
+	defer span.End()
+
+	n, err := p.scopeDescriptionQuery(ctx, scope).Count(&consent.ScopeDescription{})
+	return n, sqlcon.HandleError(err)
+}
+
+func (p *Persister) DeleteScopeDescriptions(ctx context.Context, scope string) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.DeleteScopeDescriptions")
+	defer span.End()
+
+	return sqlcon.HandleError(p.Connection(ctx).RawQuery(
+		"DELETE FROM hydra_oauth2_scope_description WHERE nid = ? AND scope = ?",
+		p.NetworkID(ctx),
+		scope,
+	).Exec())
+}
+
+func (p *Persister) ListPairwiseSubjects(ctx context.Context, limit, offset int) ([]consent.PairwiseSubject, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListPairwiseSubjects")
+	defer span.End()
+
+	var ss []consent.PairwiseSubject
+	if err := p.Connection(ctx).RawQuery(
+		/* #nosec G201 - flow states are constants */
+		fmt.Sprintf(`
+SELECT DISTINCT f.client_id, f.subject FROM hydra_oauth2_flow AS f
+JOIN hydra_client AS c ON (c.id = f.client_id AND c.nid = f.nid)
+WHERE
+	(f.state = %d OR f.state = %d) AND
+	f.consent_error='{}' AND
+	c.subject_type = 'pairwise' AND
+	f.nid = ?
+ORDER BY f.client_id, f.subject
+LIMIT %d OFFSET %d`, flow.FlowStateConsentUsed, flow.FlowStateConsentUnused, limit, offset),
+		p.NetworkID(ctx),
+	).All(&ss); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ss, nil
+}
+
+func (p *Persister) CreatePairwiseSubjectMapping(ctx context.Context, mapping *consent.PairwiseSubjectMapping) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreatePairwiseSubjectMapping")
+	defer span.End()
+
+	mapping.NID = p.NetworkID(ctx)
+	mapping.CreatedAt = time.Now().UTC().Round(time.Second)
+	mapping.ExpiresAt = mapping.ExpiresAt.UTC().Round(time.Second)
+	return p.transaction(ctx, func(ctx context.Context, c *pop.Connection) error {
+		if err := c.RawQuery(
+			"DELETE FROM hydra_oauth2_pairwise_subject_mapping WHERE nid = ? AND client_id = ? AND subject = ?",
+			mapping.NID,
+			mapping.ClientID,
+			mapping.Subject,
+		).Exec(); err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		return sqlcon.HandleError(c.RawQuery(
+			"INSERT INTO hydra_oauth2_pairwise_subject_mapping (nid, client_id, subject, previous_subject, current_subject, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			mapping.NID,
+			mapping.ClientID,
+			mapping.Subject,
+			mapping.PreviousSubject,
+			mapping.CurrentSubject,
+			mapping.ExpiresAt,
+			mapping.CreatedAt,
+		).Exec())
+	})
+}
+
+func (p *Persister) GetPairwiseSubjectMapping(ctx context.Context, client, subject string) (*consent.PairwiseSubjectMapping, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.GetPairwiseSubjectMapping")
+	defer span.End()
+
+	var m consent.PairwiseSubjectMapping
+	if err := p.QueryWithNetwork(ctx).
+		Where("client_id = ? AND subject = ? AND expires_at > ?", client, subject, time.Now().UTC()).
+		First(&m); errors.Is(err, sql.ErrNoRows) {
+		return nil, errorsx.WithStack(x.ErrNotFound)
+	} else if err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return &m, nil
+}
+
+func (p *Persister) FindPairwiseSubjectMappings(ctx context.Context, client string, limit, offset int) ([]consent.PairwiseSubjectMapping, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindPairwiseSubjectMappings")
+	defer span.End()
+
+	var ms []consent.PairwiseSubjectMapping
+	if err := p.QueryWithNetwork(ctx).
+		Where("client_id = ? AND expires_at > ?", client, time.Now().UTC()).
+		Order("subject ASC").
+		Paginate(offset/limit+1, limit).
+		All(&ms); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ms, nil
+}
+
+func (p *Persister) CountPairwiseSubjectMappings(ctx context.Context, client string) (int, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CountPairwiseSubjectMappings")
+	defer span.End()
+
+	n, err := p.QueryWithNetwork(ctx).
+		Where("client_id = ? AND expires_at > ?", client, time.Now().UTC()).
+		Count(&consent.PairwiseSubjectMapping{})
+	return n, sqlcon.HandleError(err)
+}
+
 // CreateConsentRequest configures fields that are introduced or changed in the
 // consent request. It doesn't touch fields that would be copied from the login
 // request.
@@ -364,18 +565,46 @@ func (p *Persister) GetRememberedLoginSession(ctx context.Context, id string) (*
 	return &s, nil
 }
 
-func (p *Persister) ConfirmLoginSession(ctx context.Context, id string, authenticatedAt time.Time, subject string, remember bool) error {
+func (p *Persister) ConfirmLoginSession(ctx context.Context, session *consent.LoginSession) error {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ConfirmLoginSession")
 	defer span.End()
 
-	_, err := p.Connection(ctx).Where("id = ? AND nid = ?", id, p.NetworkID(ctx)).UpdateQuery(&consent.LoginSession{
-		AuthenticatedAt: sqlxx.NullTime(authenticatedAt),
-		Subject:         subject,
-		Remember:        remember,
-	}, "authenticated_at", "subject", "remember")
+	_, err := p.Connection(ctx).Where("id = ? AND nid = ?", session.ID, p.NetworkID(ctx)).UpdateQuery(&consent.LoginSession{
+		AuthenticatedAt:       session.AuthenticatedAt,
+		Subject:               session.Subject,
+		Remember:              session.Remember,
+		IPAddress:             session.IPAddress,
+		UserAgentHash:         session.UserAgentHash,
+		DeviceID:              session.DeviceID,
+		DeviceFingerprintHash: session.DeviceFingerprintHash,
+	}, "authenticated_at", "subject", "remember", "ip_address", "user_agent_hash", "device_id", "device_fingerprint_hash")
 	return sqlcon.HandleError(err)
 }
 
+func (p *Persister) FindSubjectsLoginSessions(ctx context.Context, subject string, limit, offset int) ([]consent.LoginSession, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindSubjectsLoginSessions")
+	defer span.End()
+
+	var ss []consent.LoginSession
+	if err := p.QueryWithNetwork(ctx).
+		Where("subject = ?", subject).
+		Order("authenticated_at DESC").
+		Paginate(offset/limit+1, limit).
+		All(&ss); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ss, nil
+}
+
+func (p *Persister) CountSubjectsLoginSessions(ctx context.Context, subject string) (int, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CountSubjectsLoginSessions")
+	defer span.End()
+
+	n, err := p.QueryWithNetwork(ctx).Where("subject = ?", subject).Count(&consent.LoginSession{})
+	return n, sqlcon.HandleError(err)
+}
+
 func (p *Persister) CreateLoginSession(ctx context.Context, session *consent.LoginSession) error {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateLoginSession")
 	defer span.End()
@@ -548,6 +777,74 @@ func (p *Persister) ListUserAuthenticatedClientsWithBackChannelLogout(ctx contex
 	return p.listUserAuthenticatedClients(ctx, subject, sid, "back")
 }
 
+// ListUserAuthenticatedClientsWithBackChannelLogoutForSessions returns the clients with back-channel
+// logout the subject authenticated with in each of the given login sessions, keyed by session ID.
+func (p *Persister) ListUserAuthenticatedClientsWithBackChannelLogoutForSessions(ctx context.Context, subject string, sids []string) (map[string][]client.Client, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.ListUserAuthenticatedClientsWithBackChannelLogoutForSessions")
+	defer span.End()
+
+	result := map[string][]client.Client{}
+	if len(sids) == 0 {
+		return result, nil
+	}
+
+	args := []interface{}{subject, p.NetworkID(ctx), p.NetworkID(ctx)}
+	for _, sid := range sids {
+		args = append(args, sid)
+	}
+
+	var pairs []struct {
+		ClientID  string `db:"client_id"`
+		SessionID string `db:"login_session_id"`
+	}
+	if err := p.Connection(ctx).RawQuery(
+		/* #nosec G201 - only the number of placeholders is formatted into the query */
+		fmt.Sprintf(`
+SELECT DISTINCT f.client_id, f.login_session_id FROM hydra_oauth2_flow AS f
+JOIN hydra_client AS c ON (c.id = f.client_id)
+WHERE
+	f.subject = ? AND
+	c.backchannel_logout_uri != '' AND
+	c.backchannel_logout_uri IS NOT NULL AND
+	f.nid = ? AND
+	c.nid = ? AND
+	f.login_session_id IN (%s)`, strings.TrimSuffix(strings.Repeat("?, ", len(sids)), ", ")),
+		args...,
+	).All(&pairs); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	if len(pairs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]interface{}, 0, len(pairs))
+	seen := map[string]bool{}
+	for _, pair := range pairs {
+		if !seen[pair.ClientID] {
+			seen[pair.ClientID] = true
+			ids = append(ids, pair.ClientID)
+		}
+	}
+
+	var cs []client.Client
+	if err := p.QueryWithNetwork(ctx).Where("id IN (?)", ids...).All(&cs); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	byID := make(map[string]client.Client, len(cs))
+	for _, c := range cs {
+		byID[c.GetID()] = c
+	}
+
+	for _, pair := range pairs {
+		if c, ok := byID[pair.ClientID]; ok {
+			result[pair.SessionID] = append(result[pair.SessionID], c)
+		}
+	}
+	return result, nil
+}
+
 func (p *Persister) listUserAuthenticatedClients(ctx context.Context, subject, sid, channel string) ([]client.Client, error) {
 	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.listUserAuthenticatedClients")
 	defer span.End()
@@ -707,3 +1004,58 @@ func (p *Persister) FlushInactiveLoginConsentRequests(ctx context.Context, notAf
 
 	return nil
 }
+
+func (p *Persister) CreateConsentDecision(ctx context.Context, decision *consent.ConsentDecision) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CreateConsentDecision")
+	defer span.End()
+
+	if decision.ID == uuid.Nil {
+		decision.ID = uuid.Must(uuid.NewV4())
+	}
+	if decision.CreatedAt.IsZero() {
+		decision.CreatedAt = time.Now().UTC()
+	}
+	decision.CreatedAt = decision.CreatedAt.Round(time.Second)
+
+	return sqlcon.HandleError(p.CreateWithNetwork(ctx, decision))
+}
+
+func (p *Persister) consentDecisionQuery(ctx context.Context, filter consent.ConsentDecisionFilter) *pop.Query {
+	q := p.QueryWithNetwork(ctx)
+	if filter.Subject != "" {
+		q = q.Where("subject = ?", filter.Subject)
+	}
+	if filter.ClientID != "" {
+		q = q.Where("client_id = ?", filter.ClientID)
+	}
+	if !filter.Since.IsZero() {
+		q = q.Where("created_at >= ?", filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		q = q.Where("created_at < ?", filter.Until.UTC())
+	}
+	return q
+}
+
+func (p *Persister) FindConsentDecisions(ctx context.Context, filter consent.ConsentDecisionFilter, limit, offset int) ([]consent.ConsentDecision, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.FindConsentDecisions")
+	defer span.End()
+
+	var ds []consent.ConsentDecision
+	if err := p.consentDecisionQuery(ctx, filter).
+		Order("created_at ASC, id ASC").
+		Paginate(offset/limit+1, limit).
+		All(&ds); err != nil {
+		return nil, sqlcon.HandleError(err)
+	}
+
+	return ds, nil
+}
+
+func (p *Persister) CountConsentDecisions(ctx context.Context, filter consent.ConsentDecisionFilter) (int, error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.CountConsentDecisions")
+	defer span.End()
+
+	n, err := p.consentDecisionQuery(ctx, filter).Count(&consent.ConsentDecision{})
+	return n, sqlcon.HandleError(err)
+}
@@ -6,6 +6,7 @@ package sql
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/gobuffalo/pop/v6"
 	"gopkg.in/square/go-jose.v2"
@@ -17,6 +18,7 @@ import (
 	"github.com/ory/hydra/v2/jwk"
 	"github.com/ory/hydra/v2/x"
 	"github.com/ory/x/sqlcon"
+	"github.com/ory/x/sqlxx"
 )
 
 var _ jwk.Manager = &Persister{}
@@ -128,6 +130,7 @@ func (p *Persister) GetKey(ctx context.Context, set, kid string) (*jose.JSONWebK
 	var j jwk.SQLData
 	if err := p.QueryWithNetwork(ctx).
 		Where("sid = ? AND kid = ?", set, kid).
+		Where("(retire_at IS NULL OR retire_at > ?)", time.Now().UTC()).
 		Order("created_at DESC").
 		First(&j); err != nil {
 		return nil, sqlcon.HandleError(err)
@@ -155,6 +158,7 @@ func (p *Persister) GetKeySet(ctx context.Context, set string) (*jose.JSONWebKey
 	var js []jwk.SQLData
 	if err := p.QueryWithNetwork(ctx).
 		Where("sid = ?", set).
+		Where("(retire_at IS NULL OR retire_at > ?)", time.Now().UTC()).
 		Order("created_at DESC").
 		All(&js); err != nil {
 		return nil, sqlcon.HandleError(err)
@@ -200,3 +204,24 @@ func (p *Persister) DeleteKeySet(ctx context.Context, set string) error {
 	err := p.QueryWithNetwork(ctx).Where("sid=?", set).Delete(&jwk.SQLData{})
 	return sqlcon.HandleError(err)
 }
+
+// RetireKeys schedules every key of the set except the ones in keep to be retired at the given time.
+// Keys which are already scheduled to be retired earlier are not changed.
+func (p *Persister) RetireKeys(ctx context.Context, set string, keep []string, at time.Time) error {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RetireKeys")
+	defer span.End()
+
+	q := p.QueryWithNetwork(ctx).
+		Where("sid = ?", set).
+		Where("(retire_at IS NULL OR retire_at > ?)", at.UTC())
+	if len(keep) > 0 {
+		kids := make([]interface{}, len(keep))
+		for k, kid := range keep {
+			kids[k] = kid
+		}
+		q = q.Where("kid NOT IN (?)", kids...)
+	}
+
+	_, err := q.UpdateQuery(&jwk.SQLData{RetireAt: sqlxx.NullTime(at.UTC())}, "retire_at")
+	return sqlcon.HandleError(err)
+}
@@ -5,8 +5,11 @@ package sql
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
+	"io/fs"
 	"strconv"
 	"strings"
 	"time"
@@ -40,6 +43,21 @@ func (p *Persister) MigrationStatus(ctx context.Context) (popx.MigrationStatuses
 	return status, nil
 }
 
+// MigrationChecksums returns the SHA-256 checksum of every up migration applicable to the
+// connection's dialect, keyed by migration version.
+func (p *Persister) MigrationChecksums(_ context.Context) (map[string]string, error) {
+	checksums := map[string]string{}
+	for _, m := range p.mb.Migrations["up"].SortAndFilter(p.conn.Dialect.Name()) {
+		content, err := fs.ReadFile(migrations, m.Path)
+		if err != nil {
+			return nil, errorsx.WithStack(err)
+		}
+		sum := sha256.Sum256(content)
+		checksums[m.Version] = hex.EncodeToString(sum[:])
+	}
+	return checksums, nil
+}
+
 func (p *Persister) MigrateDown(ctx context.Context, steps int) error {
 	return errorsx.WithStack(p.mb.Down(ctx, steps))
 }
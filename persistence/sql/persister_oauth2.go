@@ -22,12 +22,15 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/ory/fosite"
 	"github.com/ory/x/sqlcon"
 	"github.com/ory/x/stringsx"
 
 	"github.com/ory/hydra/v2/oauth2"
+	"github.com/ory/hydra/v2/x"
 )
 
 var _ oauth2.AssertionJWTReader = &Persister{}
@@ -227,9 +230,16 @@ func (p *Persister) SetClientAssertionJWTRaw(ctx context.Context, jti *oauth2.Bl
 	return sqlcon.HandleError(p.CreateWithNetwork(ctx, jti))
 }
 
+// sessionTableAttribute records the table of an OAuth 2.0 session operation in its span.
+func sessionTableAttribute(table tableName) trace.SpanStartOption {
+	return trace.WithAttributes(semconv.DBSQLTableKey.String(OAuth2RequestSQL{Table: table}.TableName()))
+}
+
 func (p *Persister) createSession(ctx context.Context, signature string, requester fosite.Requester, table tableName) error {
-	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.createSession")
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.createSession", sessionTableAttribute(table))
 	defer span.End()
+	defer p.r.Metrics(ctx).RecordPersisterCall(ctx, "create", string(table), time.Now())
+	defer x.RecordTiming(ctx, x.TimingPersistence, time.Now())
 
 	req, err := p.sqlSchemaFromRequest(ctx, signature, requester, table)
 	if err != nil {
@@ -245,8 +255,10 @@ func (p *Persister) createSession(ctx context.Context, signature string, request
 }
 
 func (p *Persister) findSessionBySignature(ctx context.Context, rawSignature string, session fosite.Session, table tableName) (fosite.Requester, error) {
-	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.findSessionBySignature")
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.findSessionBySignature", sessionTableAttribute(table))
 	defer span.End()
+	defer p.r.Metrics(ctx).RecordPersisterCall(ctx, "find", string(table), time.Now())
+	defer x.RecordTiming(ctx, x.TimingSessionLoad, time.Now())
 
 	rawSignature = p.hashSignature(ctx, rawSignature, table)
 
@@ -282,8 +294,10 @@ func (p *Persister) findSessionBySignature(ctx context.Context, rawSignature str
 }
 
 func (p *Persister) deleteSessionBySignature(ctx context.Context, signature string, table tableName) error {
-	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deleteSessionBySignature")
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deleteSessionBySignature", sessionTableAttribute(table))
 	defer span.End()
+	defer p.r.Metrics(ctx).RecordPersisterCall(ctx, "delete", string(table), time.Now())
+	defer x.RecordTiming(ctx, x.TimingPersistence, time.Now())
 
 	signature = p.hashSignature(ctx, signature, table)
 
@@ -309,8 +323,10 @@ func (p *Persister) deleteSessionBySignature(ctx context.Context, signature stri
 }
 
 func (p *Persister) deleteSessionByRequestID(ctx context.Context, id string, table tableName) error {
-	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deleteSessionByRequestID")
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deleteSessionByRequestID", sessionTableAttribute(table))
 	defer span.End()
+	defer p.r.Metrics(ctx).RecordPersisterCall(ctx, "delete", string(table), time.Now())
+	defer x.RecordTiming(ctx, x.TimingPersistence, time.Now())
 
 	/* #nosec G201 table is static */
 	if err := p.QueryWithNetwork(ctx).
@@ -329,8 +345,10 @@ func (p *Persister) deleteSessionByRequestID(ctx context.Context, id string, tab
 }
 
 func (p *Persister) deactivateSessionByRequestID(ctx context.Context, id string, table tableName) error {
-	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deactivateSessionByRequestID")
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.deactivateSessionByRequestID", sessionTableAttribute(table))
 	defer span.End()
+	defer p.r.Metrics(ctx).RecordPersisterCall(ctx, "deactivate", string(table), time.Now())
+	defer x.RecordTiming(ctx, x.TimingPersistence, time.Now())
 
 	/* #nosec G201 table is static */
 	return sqlcon.HandleError(
@@ -484,3 +502,57 @@ func (p *Persister) DeleteAccessTokens(ctx context.Context, clientID string) err
 		p.QueryWithNetwork(ctx).Where("client_id=?", clientID).Delete(&OAuth2RequestSQL{Table: sqlTableAccess}),
 	)
 }
+
+// tokenFilterQuery translates the filter to a where clause for the token tables. Audiences are stored
+// joined by "|", which is why the audience is matched as a whole element of that list.
+func (p *Persister) tokenFilterQuery(ctx context.Context, filter x.TokenFilter) (string, []interface{}) {
+	conditions := []string{"nid = ?"}
+	args := []interface{}{p.NetworkID(ctx)}
+
+	if filter.ClientID != "" {
+		conditions = append(conditions, "client_id = ?")
+		args = append(args, filter.ClientID)
+	}
+	if filter.Subject != "" {
+		conditions = append(conditions, "subject = ?")
+		args = append(args, filter.Subject)
+	}
+	if filter.Audience != "" {
+		escaped := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(filter.Audience)
+		conditions = append(conditions, `(granted_audience = ? OR granted_audience LIKE ? ESCAPE '!' OR granted_audience LIKE ? ESCAPE '!' OR granted_audience LIKE ? ESCAPE '!')`)
+		args = append(args, filter.Audience, escaped+"|%", "%|"+escaped, "%|"+escaped+"|%")
+	}
+	if !filter.IssuedBefore.IsZero() {
+		conditions = append(conditions, "requested_at < ?")
+		args = append(args, filter.IssuedBefore.UTC())
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (p *Persister) RevokeTokens(ctx context.Context, filter x.TokenFilter) (accessTokens int, refreshTokens int, err error) {
+	ctx, span := p.r.Tracer(ctx).Tracer().Start(ctx, "persistence.sql.RevokeTokens")
+	defer span.End()
+
+	where, args := p.tokenFilterQuery(ctx, filter)
+	err = p.transaction(ctx, func(ctx context.Context, c *pop.Connection) error {
+		var err error
+
+		/* #nosec G201 table is static */
+		accessTokens, err = c.RawQuery(
+			fmt.Sprintf("DELETE FROM %s WHERE %s", OAuth2RequestSQL{Table: sqlTableAccess}.TableName(), where),
+			args...,
+		).ExecWithCount()
+		if err != nil {
+			return sqlcon.HandleError(err)
+		}
+
+		/* #nosec G201 table is static */
+		refreshTokens, err = c.RawQuery(
+			fmt.Sprintf("UPDATE %s SET active=false WHERE active=true AND %s", OAuth2RequestSQL{Table: sqlTableRefresh}.TableName(), where),
+			args...,
+		).ExecWithCount()
+		return sqlcon.HandleError(err)
+	})
+	return accessTokens, refreshTokens, err
+}
@@ -94,6 +94,7 @@ func testRegistry(t *testing.T, ctx context.Context, k string, t1 driver.Registr
 						t.Run("TestManagerGenerateAndPersistKeySet", jwk.TestHelperManagerGenerateAndPersistKeySet(t1.KeyManager(), tc.alg, parallel))
 						t.Run("TestManagerGenerateAndPersistKeySet", jwk.TestHelperManagerGenerateAndPersistKeySet(t2.KeyManager(), tc.alg, parallel))
 					})
+					t.Run("TestManagerRetireKeys", jwk.TestHelperManagerRetireKeys(t1.KeyManager(), tc.alg, kid.String()))
 				}
 			})
 		}
@@ -14,6 +14,19 @@ import (
 	"github.com/ory/fosite/handler/rfc7523"
 )
 
+// TokenFilter selects the tokens revoked by FositeStorer.RevokeTokens. Empty fields match every token.
+type TokenFilter struct {
+	ClientID     string
+	Subject      string
+	Audience     string
+	IssuedBefore time.Time
+}
+
+// IsEmpty returns true if the filter matches every token.
+func (f TokenFilter) IsEmpty() bool {
+	return f.ClientID == "" && f.Subject == "" && f.Audience == "" && f.IssuedBefore.IsZero()
+}
+
 type FositeStorer interface {
 	fosite.Storage
 	oauth2.CoreStorage
@@ -36,6 +49,10 @@ type FositeStorer interface {
 
 	DeleteAccessTokens(ctx context.Context, clientID string) error
 
+	// RevokeTokens deletes the access tokens and deactivates the refresh tokens matching the filter
+	// and returns how many of each were revoked.
+	RevokeTokens(ctx context.Context, filter TokenFilter) (accessTokens int, refreshTokens int, err error)
+
 	FlushInactiveRefreshTokens(ctx context.Context, notAfter time.Time, limit int, batchSize int) error
 
 	// DeleteOpenIDConnectSession deletes an OpenID Connect session.
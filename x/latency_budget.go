@@ -0,0 +1,126 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ory/x/logrusx"
+)
+
+const (
+	TimingHandler      = "handler"
+	TimingSQL          = "sql"
+	TimingSigning      = "signing"
+	TimingClientLookup = "client_lookup"
+	TimingSessionLoad  = "session_load"
+	TimingHooks        = "hooks"
+	TimingPersistence  = "persistence"
+)
+
+// timingPhases is the order in which the timings are reported.
+var timingPhases = []string{TimingClientLookup, TimingSessionLoad, TimingHooks, TimingSigning, TimingPersistence, TimingSQL, TimingHandler}
+
+type timingsContextKey struct{}
+
+// Timings collects how long a request spent in the phases of handling it, such as SQL statements
+// and signing tokens.
+type Timings struct {
+	sync.Mutex
+	durations map[string]time.Duration
+	counts    map[string]int
+}
+
+// WithTimings returns a context which collects timings. If the context already collects timings,
+// it is returned unchanged.
+func WithTimings(ctx context.Context) (context.Context, *Timings) {
+	if t, ok := ctx.Value(timingsContextKey{}).(*Timings); ok {
+		return ctx, t
+	}
+
+	t := &Timings{durations: map[string]time.Duration{}, counts: map[string]int{}}
+	return context.WithValue(ctx, timingsContextKey{}, t), t
+}
+
+// RecordTiming adds the time since start to the given phase of the timings collected by the
+// context, if any. It is meant to be deferred:
+//
+//	defer x.RecordTiming(ctx, x.TimingSigning, time.Now())
+func RecordTiming(ctx context.Context, phase string, start time.Time) {
+	t, ok := ctx.Value(timingsContextKey{}).(*Timings)
+	if !ok {
+		return
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	t.durations[phase] += time.Since(start)
+	t.counts[phase]++
+}
+
+func (t *Timings) get(phase string) (time.Duration, int) {
+	t.Lock()
+	defer t.Unlock()
+	return t.durations[phase], t.counts[phase]
+}
+
+// Fields returns the recorded phases as log fields.
+func (t *Timings) Fields() map[string]interface{} {
+	fields := map[string]interface{}{}
+	for _, phase := range timingPhases {
+		if d, n := t.get(phase); n > 0 {
+			fields["timing_"+phase] = d.String()
+			fields["timing_"+phase+"_count"] = n
+		}
+	}
+	return fields
+}
+
+// TimeHandler records the time spent in the handler, which is the time spent after all
+// middlewares ran.
+func TimeHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer RecordTiming(r.Context(), TimingHandler, time.Now())
+		h.ServeHTTP(w, r)
+	})
+}
+
+// LatencyBudgetMiddleware logs requests which take longer than the latency budget of their route
+// at level warning, together with a breakdown of where the time was spent. It must be the first
+// middleware so that the time spent in the other middlewares is accounted for.
+func LatencyBudgetMiddleware(l *logrusx.Logger, budget func(ctx context.Context, path string) time.Duration) func(http.ResponseWriter, *http.Request, http.HandlerFunc) {
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		limit := budget(r.Context(), r.URL.Path)
+		if limit <= 0 {
+			next(rw, r)
+			return
+		}
+
+		start := time.Now()
+		ctx, timings := WithTimings(r.Context())
+		next(rw, r.WithContext(ctx))
+
+		took := time.Since(start)
+		if took <= limit {
+			return
+		}
+
+		handler, _ := timings.get(TimingHandler)
+		sql, queries := timings.get(TimingSQL)
+		signing, signatures := timings.get(TimingSigning)
+		l.WithRequest(r).
+			WithField("latency_budget", limit.String()).
+			WithField("took", took.String()).
+			WithField("timing_middleware", (took-handler).String()).
+			WithField("timing_handler", handler.String()).
+			WithField("timing_sql", sql.String()).
+			WithField("sql_statements", queries).
+			WithField("timing_signing", signing.String()).
+			WithField("signatures", signatures).
+			Warn("The request exceeded its latency budget.")
+	}
+}
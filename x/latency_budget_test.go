@@ -0,0 +1,89 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/negroni"
+
+	"github.com/ory/x/logrusx"
+)
+
+func TestLatencyBudgetMiddleware(t *testing.T) {
+	hook := test.Hook{}
+	l := logrusx.New("", "", logrusx.WithHook(&hook))
+	l.Logrus().SetOutput(io.Discard)
+
+	budgets := map[string]time.Duration{"/slow": time.Millisecond, "/fast": time.Hour}
+	n := negroni.New()
+	n.UseFunc(LatencyBudgetMiddleware(l, func(_ context.Context, path string) time.Duration {
+		return budgets[path]
+	}))
+	n.UseHandler(TimeHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		func() {
+			defer RecordTiming(r.Context(), TimingSQL, time.Now())
+			time.Sleep(5 * time.Millisecond)
+		}()
+		RecordTiming(r.Context(), TimingSigning, time.Now())
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	for _, path := range []string{"/fast", "/unbudgeted"} {
+		n.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Empty(t, hook.AllEntries(), path)
+	}
+
+	n.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	require.Len(t, hook.AllEntries(), 1)
+
+	e := hook.LastEntry()
+	assert.Equal(t, logrus.WarnLevel, e.Level)
+	assert.Equal(t, "1ms", e.Data["latency_budget"])
+	assert.Equal(t, 1, e.Data["sql_statements"])
+	assert.Equal(t, 1, e.Data["signatures"])
+
+	sql, err := time.ParseDuration(e.Data["timing_sql"].(string))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, sql, 5*time.Millisecond)
+
+	handler, err := time.ParseDuration(e.Data["timing_handler"].(string))
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, handler, sql)
+}
+
+func TestRecordTimingWithoutTimings(t *testing.T) {
+	assert.NotPanics(t, func() {
+		RecordTiming(context.Background(), TimingSQL, time.Now())
+	})
+}
+
+func TestTimingsFields(t *testing.T) {
+	ctx, timings := WithTimings(context.Background())
+	reused, same := WithTimings(ctx)
+	assert.Equal(t, ctx, reused)
+	assert.Same(t, timings, same)
+
+	assert.Empty(t, timings.Fields())
+
+	timings.durations[TimingSigning] = 1500 * time.Microsecond
+	timings.counts[TimingSigning] = 2
+	timings.durations[TimingClientLookup] = 250 * time.Microsecond
+	timings.counts[TimingClientLookup] = 1
+	assert.Equal(t, map[string]interface{}{
+		"timing_client_lookup":       "250µs",
+		"timing_client_lookup_count": 1,
+		"timing_signing":             "1.5ms",
+		"timing_signing_count":       2,
+	}, timings.Fields())
+}
@@ -0,0 +1,202 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/ory/fosite"
+	"github.com/ory/x/errorsx"
+	"github.com/ory/x/httprouterx"
+)
+
+const LogLevelPath = "/log-level"
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// logSettingsMu serializes changes of the log settings made by the admin API and by signals.
+var logSettingsMu sync.Mutex
+
+// Log Settings
+//
+// swagger:model logSettings
+type LogSettings struct {
+	// The log level, one of `trace`, `debug`, `info`, `warning`, `error`, `fatal` and `panic`.
+	Level string `json:"level,omitempty"`
+
+	// The log format, either `text` or `json`.
+	Format string `json:"format,omitempty"`
+}
+
+// Set Log Settings Request
+//
+// swagger:parameters setLogSettings
+type setLogSettings struct {
+	// in: body
+	// required: true
+	Body LogSettings
+}
+
+type LogHandler struct {
+	r interface {
+		RegistryLogger
+		RegistryWriter
+	}
+}
+
+// NewLogHandler returns the handler which allows changing the log level and format at runtime.
+func NewLogHandler(r interface {
+	RegistryLogger
+	RegistryWriter
+}) *LogHandler {
+	return &LogHandler{r: r}
+}
+
+func (h *LogHandler) SetRoutes(admin *httprouterx.RouterAdmin) {
+	admin.GET(LogLevelPath, h.getLogSettings)
+	admin.PUT(LogLevelPath, h.setLogSettings)
+}
+
+// swagger:route GET /admin/log-level metadata getLogSettings
+//
+// # Get the Log Level and Format
+//
+// Returns the log level and format which are currently in use.
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: logSettings
+func (h *LogHandler) getLogSettings(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	h.r.Writer().Write(w, r, CurrentLogSettings(h.r.Logger().Logrus()))
+}
+
+// swagger:route PUT /admin/log-level metadata setLogSettings
+//
+// # Change the Log Level and Format
+//
+// Changes the log level and format until the next restart, for example to collect debug logs during an
+// incident. Fields which are omitted are left unchanged. The change is not persisted in the configuration.
+//
+//	Consumes:
+//	- application/json
+//
+//	Produces:
+//	- application/json
+//
+//	Schemes: http, https
+//
+//	Responses:
+//	  200: logSettings
+//	  default: errorOAuth2
+func (h *LogHandler) setLogSettings(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var body LogSettings
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithHintf("Unable to decode the request body: %s", err)))
+		return
+	}
+
+	previous := CurrentLogSettings(h.r.Logger().Logrus())
+	if err := ApplyLogSettings(body, h.r.Logger().Logrus(), h.r.AuditLogger().Logrus()); err != nil {
+		h.r.Writer().WriteError(w, r, errorsx.WithStack(fosite.ErrInvalidRequest.WithWrap(err).WithHint(err.Error())))
+		return
+	}
+
+	current := CurrentLogSettings(h.r.Logger().Logrus())
+	h.r.AuditLogger().
+		WithRequest(r).
+		WithField("previous_level", previous.Level).
+		WithField("previous_format", previous.Format).
+		WithField("level", current.Level).
+		WithField("format", current.Format).
+		Info("The log settings were changed.")
+
+	h.r.Writer().Write(w, r, current)
+}
+
+// CurrentLogSettings returns the level and format of the logger. Formats other than text and JSON are
+// reported as empty.
+func CurrentLogSettings(l *logrus.Logger) LogSettings {
+	s := LogSettings{Level: l.GetLevel().String()}
+	switch l.Formatter.(type) {
+	case *logrus.JSONFormatter:
+		s.Format = LogFormatJSON
+	case *logrus.TextFormatter:
+		s.Format = LogFormatText
+	}
+	return s
+}
+
+// ApplyLogSettings changes the level of the first logger and the format of all loggers. Empty fields are
+// left unchanged. The format is applied to all loggers so that they keep writing the same format.
+func ApplyLogSettings(s LogSettings, l *logrus.Logger, others ...*logrus.Logger) error {
+	var level logrus.Level
+	if s.Level != "" {
+		var err error
+		if level, err = logrus.ParseLevel(s.Level); err != nil {
+			return errors.Errorf("unknown log level %q", s.Level)
+		}
+	}
+
+	var formatter func() logrus.Formatter
+	switch s.Format {
+	case "":
+	case LogFormatJSON:
+		formatter = func() logrus.Formatter {
+			return &logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano, DisableHTMLEscape: true}
+		}
+	case LogFormatText:
+		formatter = func() logrus.Formatter {
+			return &logrus.TextFormatter{DisableQuote: true, FullTimestamp: true}
+		}
+	default:
+		return errors.Errorf("unknown log format %q, expected %q or %q", s.Format, LogFormatText, LogFormatJSON)
+	}
+
+	logSettingsMu.Lock()
+	defer logSettingsMu.Unlock()
+
+	if s.Level != "" {
+		l.SetLevel(level)
+	}
+	if formatter != nil {
+		for _, logger := range append([]*logrus.Logger{l}, others...) {
+			logger.SetFormatter(formatter())
+		}
+	}
+	return nil
+}
+
+// ToggleLogLevel switches the logger from debug or trace to info, and from any other level to debug.
+func ToggleLogLevel(l *logrus.Logger) LogSettings {
+	s := LogSettings{Level: logrus.DebugLevel.String()}
+	if l.IsLevelEnabled(logrus.DebugLevel) {
+		s.Level = logrus.InfoLevel.String()
+	}
+	_ = ApplyLogSettings(s, l)
+	return CurrentLogSettings(l)
+}
+
+// ToggleLogFormat switches the loggers from JSON to text and from any other format to JSON.
+func ToggleLogFormat(l *logrus.Logger, others ...*logrus.Logger) LogSettings {
+	s := LogSettings{Format: LogFormatJSON}
+	if CurrentLogSettings(l).Format == LogFormatJSON {
+		s.Format = LogFormatText
+	}
+	_ = ApplyLogSettings(s, l, others...)
+	return CurrentLogSettings(l)
+}
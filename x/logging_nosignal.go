@@ -0,0 +1,13 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows || plan9
+// +build windows plan9
+
+package x
+
+import "context"
+
+// WatchLogSignals does nothing because SIGUSR1 and SIGUSR2 do not exist on this platform. Use the
+// admin API to change the log settings instead.
+func WatchLogSignals(context.Context, RegistryLogger) {}
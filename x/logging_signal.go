@@ -0,0 +1,43 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package x
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchLogSignals toggles the log level between info and debug on SIGUSR1 and the log format between
+// text and JSON on SIGUSR2 until the context is done.
+func WatchLogSignals(ctx context.Context, r RegistryLogger) {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		defer signal.Stop(sig)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case s := <-sig:
+				var current LogSettings
+				if s == syscall.SIGUSR1 {
+					current = ToggleLogLevel(r.Logger().Logrus())
+				} else {
+					current = ToggleLogFormat(r.Logger().Logrus(), r.AuditLogger().Logrus())
+				}
+				r.AuditLogger().
+					WithField("signal", s.String()).
+					WithField("level", current.Level).
+					WithField("format", current.Format).
+					Info("The log settings were changed.")
+			}
+		}
+	}()
+}
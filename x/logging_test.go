@@ -0,0 +1,47 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/logrusx"
+)
+
+func TestLogSettings(t *testing.T) {
+	l := logrusx.New("", "", logrusx.ForceLevel(logrus.InfoLevel), logrusx.ForceFormat("text"))
+	audit := logrusx.NewAudit("", "", logrusx.ForceFormat("text"))
+
+	assert.Equal(t, LogSettings{Level: "info", Format: "text"}, CurrentLogSettings(l.Logrus()))
+
+	t.Run("case=applies level and format", func(t *testing.T) {
+		require.NoError(t, ApplyLogSettings(LogSettings{Level: "debug", Format: "json"}, l.Logrus(), audit.Logrus()))
+		assert.Equal(t, LogSettings{Level: "debug", Format: "json"}, CurrentLogSettings(l.Logrus()))
+		assert.Equal(t, "json", CurrentLogSettings(audit.Logrus()).Format)
+	})
+
+	t.Run("case=keeps omitted fields", func(t *testing.T) {
+		require.NoError(t, ApplyLogSettings(LogSettings{Level: "warning"}, l.Logrus()))
+		assert.Equal(t, LogSettings{Level: "warning", Format: "json"}, CurrentLogSettings(l.Logrus()))
+	})
+
+	t.Run("case=rejects unknown values", func(t *testing.T) {
+		assert.Error(t, ApplyLogSettings(LogSettings{Level: "verbose"}, l.Logrus()))
+		assert.Error(t, ApplyLogSettings(LogSettings{Format: "gelf"}, l.Logrus()))
+		assert.Equal(t, LogSettings{Level: "warning", Format: "json"}, CurrentLogSettings(l.Logrus()))
+	})
+
+	t.Run("case=toggles", func(t *testing.T) {
+		assert.Equal(t, "debug", ToggleLogLevel(l.Logrus()).Level)
+		assert.Equal(t, "info", ToggleLogLevel(l.Logrus()).Level)
+
+		assert.Equal(t, "text", ToggleLogFormat(l.Logrus(), audit.Logrus()).Format)
+		assert.Equal(t, "text", CurrentLogSettings(audit.Logrus()).Format)
+		assert.Equal(t, "json", ToggleLogFormat(l.Logrus()).Format)
+	})
+}
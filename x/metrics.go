@@ -0,0 +1,116 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/instrument"
+	"go.opentelemetry.io/otel/metric/instrument/syncfloat64"
+	"go.opentelemetry.io/otel/metric/instrument/syncint64"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+const metricsComponent = "github.com/ory/hydra/v2"
+
+// Metrics holds the OpenTelemetry instruments recorded by Ory Hydra.
+type Metrics struct {
+	tokenIssuance syncfloat64.Histogram
+	grantTypes    syncint64.Counter
+	persister     syncfloat64.Histogram
+	webhooks      syncfloat64.Histogram
+}
+
+// NewMetrics creates the instruments using the given meter provider.
+func NewMetrics(mp metric.MeterProvider) (*Metrics, error) {
+	meter := mp.Meter(metricsComponent)
+
+	tokenIssuance, err := meter.SyncFloat64().Histogram(
+		"hydra.oauth2.token.duration",
+		instrument.WithUnit(unit.Milliseconds),
+		instrument.WithDescription("The time it took to handle a request to the OAuth 2.0 token endpoint."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	grantTypes, err := meter.SyncInt64().Counter(
+		"hydra.oauth2.token.grants",
+		instrument.WithUnit(unit.Dimensionless),
+		instrument.WithDescription("The number of requests to the OAuth 2.0 token endpoint by grant type and outcome."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	persister, err := meter.SyncFloat64().Histogram(
+		"hydra.persistence.duration",
+		instrument.WithUnit(unit.Milliseconds),
+		instrument.WithDescription("The time it took to read or write OAuth 2.0 token sessions."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := meter.SyncFloat64().Histogram(
+		"hydra.webhook.duration",
+		instrument.WithUnit(unit.Milliseconds),
+		instrument.WithDescription("The time it took for a webhook to respond."),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Metrics{
+		tokenIssuance: tokenIssuance,
+		grantTypes:    grantTypes,
+		persister:     persister,
+		webhooks:      webhooks,
+	}, nil
+}
+
+// NewNoopMetrics returns instruments which discard all measurements.
+func NewNoopMetrics() *Metrics {
+	m, err := NewMetrics(metric.NewNoopMeterProvider())
+	if err != nil {
+		// The noop meter never returns an error.
+		panic(err)
+	}
+	return m
+}
+
+func outcome(err error) attribute.KeyValue {
+	if err != nil {
+		return attribute.String("outcome", "failure")
+	}
+	return attribute.String("outcome", "success")
+}
+
+func sinceMilliseconds(start time.Time) float64 {
+	return float64(time.Since(start)) / float64(time.Millisecond)
+}
+
+// RecordTokenIssuance records the duration and grant type of a request to the token endpoint
+// which started at start.
+func (m *Metrics) RecordTokenIssuance(ctx context.Context, grantType string, start time.Time, err error) {
+	attrs := []attribute.KeyValue{attribute.String("grant_type", grantType), outcome(err)}
+	m.tokenIssuance.Record(ctx, sinceMilliseconds(start), attrs...)
+	m.grantTypes.Add(ctx, 1, attrs...)
+}
+
+// RecordPersisterCall records the duration of a persistence operation on the given table.
+func (m *Metrics) RecordPersisterCall(ctx context.Context, operation, table string, start time.Time) {
+	m.persister.Record(ctx, sinceMilliseconds(start),
+		attribute.String("operation", operation),
+		attribute.String("table", table),
+	)
+}
+
+// RecordWebhook records the duration of a webhook call.
+func (m *Metrics) RecordWebhook(ctx context.Context, hook string, start time.Time, err error) {
+	m.webhooks.Record(ctx, sinceMilliseconds(start), attribute.String("hook", hook), outcome(err))
+}
@@ -0,0 +1,284 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite"
+)
+
+// otherLabelValue replaces label values once the cardinality limit of a label is reached.
+const otherLabelValue = "other"
+
+var registerRuntimeCollector sync.Once
+
+// PrometheusMetrics holds the Prometheus metrics which Ory Hydra records in addition to the generic
+// HTTP metrics of the Prometheus manager.
+type PrometheusMetrics struct {
+	endpointDuration  *prometheus.HistogramVec
+	tokenRequests     *prometheus.CounterVec
+	authorizeRequests *prometheus.CounterVec
+	introspections    *prometheus.CounterVec
+
+	redirectURIMismatches *prometheus.CounterVec
+	invalidClients        *prometheus.CounterVec
+	pkceFailures          *prometheus.CounterVec
+	challengeReplays      *prometheus.CounterVec
+
+	configReloads *prometheus.CounterVec
+	configChanges *prometheus.CounterVec
+
+	acceptedConnections *prometheus.CounterVec
+	openConnections     *prometheus.GaugeVec
+
+	clients *labelLimiter
+	callers *labelLimiter
+	ips     *labelLimiter
+}
+
+// NewPrometheusMetrics creates the metrics and registers them with the default Prometheus registry.
+// If they are already registered, the registered metrics are returned.
+//
+// At most clientIDLabelLimit distinct OAuth 2.0 Client IDs and introspection callers, and at most
+// ipAddressLabelLimit distinct IP addresses are used as label values, all others are reported as "other".
+func NewPrometheusMetrics(clientIDLabelLimit, ipAddressLabelLimit int) *PrometheusMetrics {
+	pm := &PrometheusMetrics{
+		endpointDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "hydra_oauth2_endpoint_duration_seconds",
+			Help: "Duration of requests to the OAuth 2.0 token and authorization endpoints in seconds.",
+		}, []string{"endpoint"}),
+		tokenRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_token_requests_total",
+			Help: "Number of requests to the OAuth 2.0 token endpoint by grant type, result and client.",
+		}, []string{"grant_type", "result", "client_id"}),
+		authorizeRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_authorize_requests_total",
+			Help: "Number of requests to the OAuth 2.0 authorization endpoint by response type and client.",
+		}, []string{"response_type", "client_id"}),
+		introspections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_introspections_total",
+			Help: "Number of token introspections by caller and whether the token was active.",
+		}, []string{"caller", "active"}),
+		redirectURIMismatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_redirect_uri_mismatches_total",
+			Help: "Number of requests whose redirect_uri did not match the registered or the originally requested one, by endpoint and client.",
+		}, []string{"endpoint", "client_id"}),
+		invalidClients: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_invalid_client_total",
+			Help: "Number of token requests which failed client authentication by IP address of the caller.",
+		}, []string{"ip_address"}),
+		pkceFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_pkce_failures_total",
+			Help: "Number of requests rejected because the PKCE code challenge or code verifier was missing or invalid, by endpoint and client.",
+		}, []string{"endpoint", "client_id"}),
+		challengeReplays: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_oauth2_challenge_replays_total",
+			Help: "Number of login and consent challenges or verifiers which were used again after they were handled.",
+		}, []string{"flow", "parameter"}),
+		configReloads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_config_reloads_total",
+			Help: "Number of configuration reloads by result.",
+		}, []string{"result"}),
+		configChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_config_changes_total",
+			Help: "Number of times a configuration key changed its value on reload.",
+		}, []string{"key"}),
+		acceptedConnections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "hydra_listener_accepted_connections_total",
+			Help: "Number of connections accepted by the public and admin listeners.",
+		}, []string{"listener"}),
+		openConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "hydra_listener_open_connections",
+			Help: "Number of open connections of the public and admin listeners.",
+		}, []string{"listener"}),
+		clients: newLabelLimiter(clientIDLabelLimit),
+		callers: newLabelLimiter(clientIDLabelLimit),
+		ips:     newLabelLimiter(ipAddressLabelLimit),
+	}
+
+	registerRuntimeCollector.Do(func() {
+		// The default Go collector only exports runtime.MemStats. Replace it with one which also
+		// exports the heap by memory class and the GC and scheduler metrics of runtime/metrics.
+		prometheus.Unregister(collectors.NewGoCollector())
+		prometheus.MustRegister(collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(
+			collectors.MetricsMemory, collectors.MetricsGC, collectors.MetricsScheduler,
+		)))
+	})
+
+	err := prometheus.Register(pm)
+	if e := new(prometheus.AlreadyRegisteredError); errors.As(err, e) {
+		if existing, ok := e.ExistingCollector.(*PrometheusMetrics); ok {
+			return existing
+		}
+		panic(err)
+	} else if err != nil {
+		panic(err)
+	}
+
+	return pm
+}
+
+// Describe implements prometheus.Collector.
+func (pm *PrometheusMetrics) Describe(in chan<- *prometheus.Desc) {
+	pm.endpointDuration.Describe(in)
+	pm.tokenRequests.Describe(in)
+	pm.authorizeRequests.Describe(in)
+	pm.introspections.Describe(in)
+	pm.redirectURIMismatches.Describe(in)
+	pm.invalidClients.Describe(in)
+	pm.pkceFailures.Describe(in)
+	pm.challengeReplays.Describe(in)
+	pm.configReloads.Describe(in)
+	pm.configChanges.Describe(in)
+	pm.acceptedConnections.Describe(in)
+	pm.openConnections.Describe(in)
+}
+
+// Collect implements prometheus.Collector.
+func (pm *PrometheusMetrics) Collect(in chan<- prometheus.Metric) {
+	pm.endpointDuration.Collect(in)
+	pm.tokenRequests.Collect(in)
+	pm.authorizeRequests.Collect(in)
+	pm.introspections.Collect(in)
+	pm.redirectURIMismatches.Collect(in)
+	pm.invalidClients.Collect(in)
+	pm.pkceFailures.Collect(in)
+	pm.challengeReplays.Collect(in)
+	pm.configReloads.Collect(in)
+	pm.configChanges.Collect(in)
+	pm.acceptedConnections.Collect(in)
+	pm.openConnections.Collect(in)
+}
+
+// ObserveEndpoint records the duration of a request to the given endpoint which started at start.
+// If the request is traced, the trace ID is attached to the observation as an exemplar.
+func (pm *PrometheusMetrics) ObserveEndpoint(ctx context.Context, endpoint string, start time.Time) {
+	observe(ctx, pm.endpointDuration.WithLabelValues(endpoint), time.Since(start).Seconds())
+}
+
+// CountTokenRequest counts a request to the token endpoint. The result is either "success" or the
+// OAuth 2.0 error code.
+func (pm *PrometheusMetrics) CountTokenRequest(grantType, clientID string, err error) {
+	result := "success"
+	if err != nil {
+		result = fosite.ErrorToRFC6749Error(err).ErrorField
+	}
+	pm.tokenRequests.WithLabelValues(grantType, result, pm.clients.value(clientID)).Inc()
+}
+
+// CountAuthorizeRequest counts a request to the authorization endpoint.
+func (pm *PrometheusMetrics) CountAuthorizeRequest(responseType, clientID string) {
+	pm.authorizeRequests.WithLabelValues(responseType, pm.clients.value(clientID)).Inc()
+}
+
+// CountIntrospection counts a token introspection.
+func (pm *PrometheusMetrics) CountIntrospection(caller string, active bool) {
+	activeLabel := "false"
+	if active {
+		activeLabel = "true"
+	}
+	pm.introspections.WithLabelValues(pm.callers.value(caller), activeLabel).Inc()
+}
+
+// CountRedirectURIMismatch counts a request whose redirect_uri did not match.
+func (pm *PrometheusMetrics) CountRedirectURIMismatch(endpoint, clientID string) {
+	pm.redirectURIMismatches.WithLabelValues(endpoint, pm.clients.value(clientID)).Inc()
+}
+
+// CountInvalidClient counts a failed client authentication by the IP address of the caller. A
+// quickly increasing count for one address indicates that client credentials are being guessed.
+func (pm *PrometheusMetrics) CountInvalidClient(ipAddress string) {
+	pm.invalidClients.WithLabelValues(pm.ips.value(ipAddress)).Inc()
+}
+
+// CountPKCEFailure counts a request which was rejected by the PKCE checks.
+func (pm *PrometheusMetrics) CountPKCEFailure(endpoint, clientID string) {
+	pm.pkceFailures.WithLabelValues(endpoint, pm.clients.value(clientID)).Inc()
+}
+
+// CountChallengeReplay counts a login or consent challenge or verifier which was used again. The
+// parameter is either "challenge" or "verifier".
+func (pm *PrometheusMetrics) CountChallengeReplay(flow, parameter string) {
+	pm.challengeReplays.WithLabelValues(flow, parameter).Inc()
+}
+
+// CountConfigReload counts a configuration reload and the keys which changed. The keys are bounded
+// by the configuration schema.
+func (pm *PrometheusMetrics) CountConfigReload(changedKeys []string, err error) {
+	if err != nil {
+		pm.configReloads.WithLabelValues("failure").Inc()
+		return
+	}
+
+	pm.configReloads.WithLabelValues("success").Inc()
+	for _, key := range changedKeys {
+		pm.configChanges.WithLabelValues(key).Inc()
+	}
+}
+
+// ConnState returns a connection state hook for an http.Server which counts the accepted and open
+// connections of the listener.
+func (pm *PrometheusMetrics) ConnState(listener string) func(net.Conn, http.ConnState) {
+	accepted := pm.acceptedConnections.WithLabelValues(listener)
+	open := pm.openConnections.WithLabelValues(listener)
+	return func(_ net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			accepted.Inc()
+			open.Inc()
+		case http.StateClosed, http.StateHijacked:
+			open.Dec()
+		}
+	}
+}
+
+func observe(ctx context.Context, o prometheus.Observer, v float64) {
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		if eo, ok := o.(prometheus.ExemplarObserver); ok {
+			eo.ObserveWithExemplar(v, prometheus.Labels{"trace_id": sc.TraceID().String()})
+			return
+		}
+	}
+	o.Observe(v)
+}
+
+// labelLimiter bounds the number of distinct values of a label.
+type labelLimiter struct {
+	sync.Mutex
+	limit int
+	seen  map[string]struct{}
+}
+
+func newLabelLimiter(limit int) *labelLimiter {
+	return &labelLimiter{limit: limit, seen: map[string]struct{}{}}
+}
+
+// value returns v if it was seen before or the limit is not yet reached, and "other" otherwise.
+func (l *labelLimiter) value(v string) string {
+	if v == "" {
+		return v
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if len(l.seen) >= l.limit {
+		return otherLabelValue
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
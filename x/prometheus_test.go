@@ -0,0 +1,119 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/fosite"
+)
+
+func tracedContext(t *testing.T) context.Context {
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	require.NoError(t, err)
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	require.NoError(t, err)
+
+	return trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	pm := NewPrometheusMetrics(1, 1)
+	assert.Same(t, pm, NewPrometheusMetrics(1, 1), "the registered metrics must be reused")
+
+	pm.ObserveEndpoint(tracedContext(t), "token", time.Now().Add(-time.Second))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	var found bool
+	for _, f := range families {
+		if f.GetName() != "hydra_oauth2_endpoint_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, b := range m.GetHistogram().GetBucket() {
+				if e := b.GetExemplar(); e != nil {
+					found = true
+					assert.Equal(t, "trace_id", e.GetLabel()[0].GetName())
+					assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", e.GetLabel()[0].GetValue())
+				}
+			}
+		}
+	}
+	assert.True(t, found, "expected an exemplar with the trace ID")
+}
+
+func TestTraceIDMiddleware(t *testing.T) {
+	next := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusBadRequest) }
+
+	rec := httptest.NewRecorder()
+	TraceIDMiddleware(rec, httptest.NewRequest("GET", "/", nil), next)
+	assert.Empty(t, rec.Header().Get(TraceIDHeader))
+
+	rec = httptest.NewRecorder()
+	TraceIDMiddleware(rec, httptest.NewRequest("GET", "/", nil).WithContext(tracedContext(t)), next)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get(TraceIDHeader))
+}
+
+func TestLabelLimiter(t *testing.T) {
+	l := newLabelLimiter(2)
+	assert.Equal(t, "a", l.value("a"))
+	assert.Equal(t, "b", l.value("b"))
+	assert.Equal(t, "other", l.value("c"))
+	assert.Equal(t, "a", l.value("a"), "values seen before the limit was reached are kept")
+	assert.Equal(t, "", l.value(""))
+
+	assert.Equal(t, "other", newLabelLimiter(0).value("a"))
+}
+
+func TestPrometheusMetricsCounters(t *testing.T) {
+	pm := NewPrometheusMetrics(1, 1)
+
+	pm.CountTokenRequest("client_credentials", "metrics-client", nil)
+	pm.CountTokenRequest("client_credentials", "metrics-client", fosite.ErrInvalidGrant)
+	pm.CountAuthorizeRequest("code", "metrics-client")
+	pm.CountIntrospection("metrics-caller", true)
+	pm.CountRedirectURIMismatch("authorize", "metrics-client")
+	pm.CountInvalidClient("192.0.2.1")
+	pm.CountInvalidClient("192.0.2.2")
+	pm.CountPKCEFailure("token", "metrics-client")
+	pm.CountChallengeReplay("consent", "verifier")
+	pm.CountConfigReload([]string{"ttl.access_token"}, nil)
+	pm.CountConfigReload(nil, errors.New("invalid configuration"))
+	connState := pm.ConnState("public")
+	connState(nil, http.StateNew)
+	connState(nil, http.StateNew)
+	connState(nil, http.StateClosed)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.tokenRequests.WithLabelValues("client_credentials", "success", "metrics-client")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.tokenRequests.WithLabelValues("client_credentials", "invalid_grant", "metrics-client")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.authorizeRequests.WithLabelValues("code", "metrics-client")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.introspections.WithLabelValues("metrics-caller", "true")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.redirectURIMismatches.WithLabelValues("authorize", "metrics-client")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.invalidClients.WithLabelValues("192.0.2.1")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.invalidClients.WithLabelValues("other")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.pkceFailures.WithLabelValues("token", "metrics-client")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.challengeReplays.WithLabelValues("consent", "verifier")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.configReloads.WithLabelValues("success")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.configReloads.WithLabelValues("failure")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.configChanges.WithLabelValues("ttl.access_token")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(pm.acceptedConnections.WithLabelValues("public")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(pm.openConnections.WithLabelValues("public")))
+}
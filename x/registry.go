@@ -34,6 +34,18 @@ type TracingProvider interface {
 	Tracer(ctx context.Context) *otelx.Tracer
 }
 
+type MetricsProvider interface {
+	Metrics(ctx context.Context) *Metrics
+}
+
+type PrometheusMetricsProvider interface {
+	PrometheusMetrics() *PrometheusMetrics
+}
+
+type SecurityEventsProvider interface {
+	SecurityEvents() *SecurityEvents
+}
+
 type HTTPClientProvider interface {
 	HTTPClient(ctx context.Context, opts ...httpx.ResilientOptions) *retryablehttp.Client
 }
@@ -0,0 +1,720 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/urfave/negroni"
+	jose "gopkg.in/square/go-jose.v2"
+
+	"github.com/ory/herodot"
+	"github.com/ory/x/errorsx"
+)
+
+// RequestSignatureLabel is the label of the HTTP message signature created by SignRequest.
+const RequestSignatureLabel = "hydra"
+
+// requestSignatureComponents are the components which every signature of an administrative mutation
+// has to cover. Requests with a body additionally have to cover the content-digest header.
+var requestSignatureComponents = []string{"@method", "@path", "@query"}
+
+type requestSigningRegistry interface {
+	RegistryLogger
+	RegistryWriter
+}
+
+type requestSigningConfig interface {
+	AdminRequestSigningEnabled(ctx context.Context) bool
+	AdminRequestSigningKeys(ctx context.Context) *jose.JSONWebKeySet
+	AdminRequestSigningMaxAge(ctx context.Context) time.Duration
+}
+
+// RequireSignedRequests rejects administrative mutations (all requests except GET, HEAD and OPTIONS)
+// which do not carry a valid HTTP message signature (RFC 9421) made with one of the configured
+// operator keys. Every signature must be fresh and its nonce may only be used once.
+func RequireSignedRequests(reg requestSigningRegistry, c requestSigningConfig) negroni.HandlerFunc {
+	nonces := &nonceCache{seen: map[string]time.Time{}}
+	return func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		ctx := r.Context()
+		if !c.AdminRequestSigningEnabled(ctx) || r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next(rw, r)
+			return
+		}
+
+		if err := verifyRequestSignature(r, c.AdminRequestSigningKeys(ctx), c.AdminRequestSigningMaxAge(ctx), nonces, time.Now()); err != nil {
+			reg.Logger().WithRequest(r).WithError(err).Warn("Rejected administrative request without a valid request signature.")
+			reg.Writer().WriteError(rw, r, errorsx.WithStack(herodot.ErrUnauthorized.WithReasonf("The request signature is missing or invalid: %s", err)))
+			return
+		}
+
+		next(rw, r)
+	}
+}
+
+// SignRequest signs the request with the operator key as described in RFC 9421. The signature covers
+// the method, path, query and, if the request has a body, its content digest.
+func SignRequest(r *http.Request, key *jose.JSONWebKey, created time.Time, nonce string) error {
+	body, err := readRequestBody(r)
+	if err != nil {
+		return err
+	}
+
+	components := append([]string{}, requestSignatureComponents...)
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		r.Header.Set("Content-Digest", "sha-256=:"+base64.StdEncoding.EncodeToString(sum[:])+":")
+		components = append(components, "content-digest")
+	}
+
+	alg, err := requestSignatureAlgorithm(key)
+	if err != nil {
+		return err
+	}
+
+	input := &signatureInput{
+		components: components,
+		params: []sfParam{
+			{name: "created", value: created.Unix()},
+			{name: "keyid", value: key.KeyID},
+			{name: "alg", value: alg},
+			{name: "nonce", value: nonce},
+		},
+	}
+
+	base, err := signatureBase(r, input)
+	if err != nil {
+		return err
+	}
+
+	signature, err := signRequestSignatureBase(key, alg, base)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Signature-Input", RequestSignatureLabel+"="+input.String())
+	r.Header.Set("Signature", RequestSignatureLabel+"=:"+base64.StdEncoding.EncodeToString(signature)+":")
+	return nil
+}
+
+func verifyRequestSignature(r *http.Request, keys *jose.JSONWebKeySet, maxAge time.Duration, nonces *nonceCache, now time.Time) error {
+	if keys == nil || len(keys.Keys) == 0 {
+		return errors.New("no operator keys are configured")
+	}
+
+	inputs, err := parseSignatureInputs(strings.Join(r.Header.Values("Signature-Input"), ", "))
+	if err != nil {
+		return errors.WithMessage(err, "unable to parse the Signature-Input header")
+	}
+	signatures, err := parseSignatures(strings.Join(r.Header.Values("Signature"), ", "))
+	if err != nil {
+		return errors.WithMessage(err, "unable to parse the Signature header")
+	}
+	if len(inputs) == 0 {
+		return errors.New("the request is not signed")
+	}
+
+	body, err := readRequestBody(r)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for _, input := range inputs {
+		signature, ok := signatures[input.label]
+		if !ok {
+			lastErr = errors.Errorf("the signature %s is missing", input.label)
+			continue
+		}
+
+		if lastErr = verifySignatureInput(r, input, signature, body, keys, maxAge, now); lastErr != nil {
+			continue
+		}
+
+		if !nonces.use(input.param("keyid").(string)+" "+input.param("nonce").(string), input.created().Add(maxAge), now) {
+			return errors.New("the nonce of the signature has already been used")
+		}
+		return nil
+	}
+	return lastErr
+}
+
+func verifySignatureInput(r *http.Request, input *signatureInput, signature []byte, body []byte, keys *jose.JSONWebKeySet, maxAge time.Duration, now time.Time) error {
+	required := append([]string{}, requestSignatureComponents...)
+	if len(body) > 0 {
+		required = append(required, "content-digest")
+	}
+	for _, component := range required {
+		if !input.covers(component) {
+			return errors.Errorf("the signature does not cover %s", component)
+		}
+	}
+
+	keyID, _ := input.param("keyid").(string)
+	nonce, _ := input.param("nonce").(string)
+	if keyID == "" || nonce == "" || input.param("created") == nil {
+		return errors.New("the signature parameters created, keyid and nonce are required")
+	}
+
+	created := input.created()
+	if created.Before(now.Add(-maxAge)) || created.After(now.Add(maxAge)) {
+		return errors.Errorf("the signature was created at %s which is not within %s of now", created.UTC().Format(time.RFC3339), maxAge)
+	}
+	if expires, ok := input.param("expires").(int64); ok && time.Unix(expires, 0).Before(now) {
+		return errors.New("the signature has expired")
+	}
+
+	matches := keys.Key(keyID)
+	if len(matches) == 0 {
+		return errors.Errorf("the key %s is not a registered operator key", keyID)
+	}
+	key := matches[0]
+
+	alg, err := requestSignatureAlgorithm(&key)
+	if err != nil {
+		return err
+	}
+	if requested, ok := input.param("alg").(string); ok && requested != alg {
+		return errors.Errorf("the algorithm %s can not be used with the key %s", requested, keyID)
+	}
+
+	if len(body) > 0 {
+		if err := verifyContentDigest(r.Header.Get("Content-Digest"), body); err != nil {
+			return err
+		}
+	}
+
+	base, err := signatureBase(r, input)
+	if err != nil {
+		return err
+	}
+	return verifyRequestSignatureBase(&key, alg, base, signature)
+}
+
+func verifyContentDigest(header string, body []byte) error {
+	digests, err := parseSFDictionary(header)
+	if err != nil {
+		return errors.WithMessage(err, "unable to parse the Content-Digest header")
+	}
+
+	for _, digest := range digests {
+		expected, ok := digest.item.([]byte)
+		if !ok {
+			continue
+		}
+
+		var h hash.Hash
+		switch digest.key {
+		case "sha-256":
+			h = sha256.New()
+		case "sha-512":
+			h = sha512.New()
+		default:
+			continue
+		}
+
+		_, _ = h.Write(body)
+		if subtle.ConstantTimeCompare(h.Sum(nil), expected) != 1 {
+			return errors.New("the content digest does not match the body")
+		}
+		return nil
+	}
+	return errors.New("the Content-Digest header does not contain a sha-256 or sha-512 digest")
+}
+
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errorsx.WithStack(err)
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// signatureBase creates the signature base of RFC 9421 section 2.5.
+func signatureBase(r *http.Request, input *signatureInput) ([]byte, error) {
+	var b strings.Builder
+	for _, component := range input.components {
+		value, err := componentValue(r, component)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "%s: %s\n", sfQuote(component), value)
+	}
+	fmt.Fprintf(&b, "%s: %s", sfQuote("@signature-params"), input.String())
+	return []byte(b.String()), nil
+}
+
+func componentValue(r *http.Request, component string) (string, error) {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	switch component {
+	case "@method":
+		return r.Method, nil
+	case "@scheme":
+		return scheme, nil
+	case "@authority":
+		return strings.ToLower(r.Host), nil
+	case "@target-uri":
+		return scheme + "://" + strings.ToLower(r.Host) + r.URL.RequestURI(), nil
+	case "@request-target":
+		return r.URL.RequestURI(), nil
+	case "@path":
+		return r.URL.EscapedPath(), nil
+	case "@query":
+		return "?" + r.URL.RawQuery, nil
+	}
+
+	if strings.HasPrefix(component, "@") {
+		return "", errors.Errorf("the derived component %s is not supported", component)
+	}
+
+	values := r.Header.Values(component)
+	if len(values) == 0 {
+		return "", errors.Errorf("the covered header %s is missing", component)
+	}
+	for k := range values {
+		values[k] = strings.TrimSpace(values[k])
+	}
+	return strings.Join(values, ", "), nil
+}
+
+// requestSignatureAlgorithm returns the RFC 9421 algorithm used with the key.
+func requestSignatureAlgorithm(key *jose.JSONWebKey) (string, error) {
+	switch k := key.Key.(type) {
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return "ed25519", nil
+	case *ecdsa.PublicKey:
+		return ecdsaRequestSignatureAlgorithm(k.Curve)
+	case *ecdsa.PrivateKey:
+		return ecdsaRequestSignatureAlgorithm(k.Curve)
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return "rsa-pss-sha512", nil
+	}
+	return "", errors.Errorf("the key %s has the unsupported type %T", key.KeyID, key.Key)
+}
+
+func ecdsaRequestSignatureAlgorithm(curve elliptic.Curve) (string, error) {
+	switch curve {
+	case elliptic.P256():
+		return "ecdsa-p256-sha256", nil
+	case elliptic.P384():
+		return "ecdsa-p384-sha384", nil
+	}
+	return "", errors.Errorf("the curve %s is not supported", curve.Params().Name)
+}
+
+func signRequestSignatureBase(key *jose.JSONWebKey, alg string, base []byte) ([]byte, error) {
+	switch k := key.Key.(type) {
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, base), nil
+	case *ecdsa.PrivateKey:
+		digest, size := ecdsaDigest(alg, base)
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest)
+		if err != nil {
+			return nil, errorsx.WithStack(err)
+		}
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	case *rsa.PrivateKey:
+		digest := sha512.Sum512(base)
+		signature, err := rsa.SignPSS(rand.Reader, k, crypto.SHA512, digest[:], &rsa.PSSOptions{SaltLength: 64})
+		return signature, errorsx.WithStack(err)
+	}
+	return nil, errors.Errorf("the key %s is not a private key", key.KeyID)
+}
+
+func verifyRequestSignatureBase(key *jose.JSONWebKey, alg string, base, signature []byte) error {
+	public := key.Public()
+	var valid bool
+	switch k := public.Key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, base, signature)
+	case *ecdsa.PublicKey:
+		digest, size := ecdsaDigest(alg, base)
+		if len(signature) == 2*size {
+			valid = ecdsa.Verify(k, digest, new(big.Int).SetBytes(signature[:size]), new(big.Int).SetBytes(signature[size:]))
+		}
+	case *rsa.PublicKey:
+		digest := sha512.Sum512(base)
+		valid = rsa.VerifyPSS(k, crypto.SHA512, digest[:], signature, &rsa.PSSOptions{SaltLength: 64}) == nil
+	}
+
+	if !valid {
+		return errors.New("the signature is invalid")
+	}
+	return nil
+}
+
+func ecdsaDigest(alg string, base []byte) ([]byte, int) {
+	if alg == "ecdsa-p384-sha384" {
+		digest := sha512.Sum384(base)
+		return digest[:], 48
+	}
+	digest := sha256.Sum256(base)
+	return digest[:], 32
+}
+
+// nonceCache remembers the nonces of signatures until the signatures expire.
+type nonceCache struct {
+	sync.Mutex
+	seen map[string]time.Time
+}
+
+// use marks the nonce as used and returns false if it was used before.
+func (c *nonceCache) use(nonce string, expiresAt, now time.Time) bool {
+	c.Lock()
+	defer c.Unlock()
+
+	for n, exp := range c.seen {
+		if exp.Before(now) {
+			delete(c.seen, n)
+		}
+	}
+
+	if _, ok := c.seen[nonce]; ok {
+		return false
+	}
+	c.seen[nonce] = expiresAt
+	return true
+}
+
+type sfParam struct {
+	name  string
+	value interface{}
+}
+
+type signatureInput struct {
+	label      string
+	components []string
+	params     []sfParam
+}
+
+func (s *signatureInput) param(name string) interface{} {
+	for _, p := range s.params {
+		if p.name == name {
+			return p.value
+		}
+	}
+	return nil
+}
+
+func (s *signatureInput) created() time.Time {
+	created, _ := s.param("created").(int64)
+	return time.Unix(created, 0)
+}
+
+func (s *signatureInput) covers(component string) bool {
+	for _, c := range s.components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// String serializes the signature parameters as a structured field inner list (RFC 8941).
+func (s *signatureInput) String() string {
+	quoted := make([]string, len(s.components))
+	for k, c := range s.components {
+		quoted[k] = sfQuote(c)
+	}
+
+	var b strings.Builder
+	b.WriteString("(" + strings.Join(quoted, " ") + ")")
+	for _, p := range s.params {
+		b.WriteString(";" + p.name)
+		switch v := p.value.(type) {
+		case string:
+			b.WriteString("=" + sfQuote(v))
+		case int64:
+			b.WriteString("=" + strconv.FormatInt(v, 10))
+		case sfToken:
+			b.WriteString("=" + string(v))
+		case bool:
+			if !v {
+				b.WriteString("=?0")
+			}
+		}
+	}
+	return b.String()
+}
+
+func sfQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func parseSignatureInputs(header string) ([]*signatureInput, error) {
+	members, err := parseSFDictionary(header)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]*signatureInput, 0, len(members))
+	for _, m := range members {
+		if m.list == nil {
+			return nil, errors.Errorf("the signature input %s is not an inner list", m.key)
+		}
+		inputs = append(inputs, &signatureInput{label: m.key, components: m.list, params: m.params})
+	}
+	return inputs, nil
+}
+
+func parseSignatures(header string) (map[string][]byte, error) {
+	members, err := parseSFDictionary(header)
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make(map[string][]byte, len(members))
+	for _, m := range members {
+		signature, ok := m.item.([]byte)
+		if !ok {
+			return nil, errors.Errorf("the signature %s is not a byte sequence", m.key)
+		}
+		signatures[m.key] = signature
+	}
+	return signatures, nil
+}
+
+type sfToken string
+
+type sfMember struct {
+	key    string
+	item   interface{}
+	list   []string
+	params []sfParam
+}
+
+// parseSFDictionary parses the subset of structured field dictionaries (RFC 8941) used by HTTP
+// message signatures: inner lists of strings and bare items, both with parameters.
+func parseSFDictionary(s string) ([]sfMember, error) {
+	p := &sfParser{s: s}
+	var members []sfMember
+
+	p.skip(" ")
+	for !p.done() {
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		m := sfMember{key: key, item: true}
+		if p.consume('=') {
+			if p.peek() == '(' {
+				if m.list, err = p.innerList(); err != nil {
+					return nil, err
+				}
+				m.item = nil
+			} else if m.item, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		if m.params, err = p.params(); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+
+		p.skip(" \t")
+		if p.done() {
+			break
+		}
+		if !p.consume(',') {
+			return nil, errors.Errorf("expected a comma at position %d", p.i)
+		}
+		p.skip(" \t")
+		if p.done() {
+			return nil, errors.New("unexpected trailing comma")
+		}
+	}
+	return members, nil
+}
+
+type sfParser struct {
+	s string
+	i int
+}
+
+func (p *sfParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *sfParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.s[p.i]
+}
+
+func (p *sfParser) consume(c byte) bool {
+	if p.peek() == c && !p.done() {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *sfParser) skip(chars string) {
+	for !p.done() && strings.IndexByte(chars, p.s[p.i]) >= 0 {
+		p.i++
+	}
+}
+
+func (p *sfParser) key() (string, error) {
+	start := p.i
+	for !p.done() {
+		c := p.s[p.i]
+		if (c >= 'a' && c <= 'z') || c == '*' || (p.i > start && ((c >= '0' && c <= '9') || c == '_' || c == '-' || c == '.')) {
+			p.i++
+			continue
+		}
+		break
+	}
+	if p.i == start {
+		return "", errors.Errorf("expected a key at position %d", p.i)
+	}
+	return p.s[start:p.i], nil
+}
+
+func (p *sfParser) innerList() ([]string, error) {
+	p.consume('(')
+	list := []string{}
+	for {
+		p.skip(" ")
+		if p.consume(')') {
+			return list, nil
+		}
+
+		item, err := p.bareItem()
+		if err != nil {
+			return nil, err
+		}
+		component, ok := item.(string)
+		if !ok {
+			return nil, errors.Errorf("expected a component identifier at position %d", p.i)
+		}
+		if p.peek() == ';' {
+			return nil, errors.Errorf("component parameters are not supported at position %d", p.i)
+		}
+		list = append(list, component)
+
+		if p.peek() != ' ' && p.peek() != ')' {
+			return nil, errors.Errorf("expected a space or the end of the inner list at position %d", p.i)
+		}
+	}
+}
+
+func (p *sfParser) params() ([]sfParam, error) {
+	var params []sfParam
+	for p.consume(';') {
+		p.skip(" ")
+		name, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{} = true
+		if p.consume('=') {
+			if value, err = p.bareItem(); err != nil {
+				return nil, err
+			}
+		}
+		params = append(params, sfParam{name: name, value: value})
+	}
+	return params, nil
+}
+
+func (p *sfParser) bareItem() (interface{}, error) {
+	switch c := p.peek(); {
+	case c == '"':
+		p.i++
+		var b strings.Builder
+		for !p.done() {
+			c := p.s[p.i]
+			p.i++
+			switch c {
+			case '\\':
+				if p.done() || (p.s[p.i] != '"' && p.s[p.i] != '\\') {
+					return nil, errors.Errorf("invalid escape at position %d", p.i)
+				}
+				b.WriteByte(p.s[p.i])
+				p.i++
+			case '"':
+				return b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return nil, errors.New("unterminated string")
+	case c == ':':
+		p.i++
+		end := strings.IndexByte(p.s[p.i:], ':')
+		if end < 0 {
+			return nil, errors.New("unterminated byte sequence")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(p.s[p.i : p.i+end])
+		if err != nil {
+			return nil, errors.Errorf("invalid byte sequence at position %d", p.i)
+		}
+		p.i += end + 1
+		return decoded, nil
+	case c == '?':
+		p.i++
+		if p.consume('1') {
+			return true, nil
+		} else if p.consume('0') {
+			return false, nil
+		}
+		return nil, errors.Errorf("invalid boolean at position %d", p.i)
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.i
+		p.i++
+		for !p.done() && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+			p.i++
+		}
+		n, err := strconv.ParseInt(p.s[start:p.i], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid integer at position %d", start)
+		}
+		return n, nil
+	case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '*':
+		start := p.i
+		for !p.done() && strings.IndexByte(" ,;()=\"", p.s[p.i]) < 0 {
+			p.i++
+		}
+		return sfToken(p.s[start:p.i]), nil
+	}
+	return nil, errors.Errorf("unexpected character at position %d", p.i)
+}
@@ -0,0 +1,202 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ory/x/httpx"
+	"github.com/ory/x/logrusx"
+)
+
+// SecurityEventSchemaVersion is the version of the SecurityEvent schema. It is increased whenever a
+// field is removed or its meaning changes.
+const SecurityEventSchemaVersion = "1"
+
+const (
+	// SecurityEventClientAuthenticationFailed is emitted when an OAuth 2.0 Client fails to
+	// authenticate at the token endpoint.
+	SecurityEventClientAuthenticationFailed = "client_authentication_failed"
+	// SecurityEventTokenReuseDetected is emitted when an authorization code or refresh token is used
+	// more than once. All tokens of the affected grant are revoked.
+	SecurityEventTokenReuseDetected = "token_reuse_detected"
+	// SecurityEventRegistrationAuthFailed is emitted when a request to manage a dynamically registered
+	// OAuth 2.0 Client presents an invalid registration access token.
+	SecurityEventRegistrationAuthFailed = "client_registration_auth_failed"
+	// SecurityEventKeyRotated is emitted when a JSON Web Key Set is rotated.
+	SecurityEventKeyRotated = "key_rotated"
+	// SecurityEventDenyListHit is emitted when a client assertion or JWT grant reuses a JWT ID which
+	// is on the JWT ID deny list.
+	SecurityEventDenyListHit = "deny_list_hit"
+	// SecurityEventLoginSessionConfirmed is emitted when a login is accepted and its login session
+	// is confirmed. It carries the device context of the login for session-anomaly detection.
+	SecurityEventLoginSessionConfirmed = "login_session_confirmed"
+
+	SecuritySeverityLow    = "low"
+	SecuritySeverityMedium = "medium"
+	SecuritySeverityHigh   = "high"
+)
+
+// SecurityEvent is a security relevant event in the format written to the security event sinks.
+// Each event is encoded as a single line of JSON.
+type SecurityEvent struct {
+	// SchemaVersion is the version of this schema, see SecurityEventSchemaVersion.
+	SchemaVersion string `json:"schema_version"`
+	// ID uniquely identifies the event.
+	ID string `json:"id"`
+	// Time is when the event occurred, in RFC 3339 format.
+	Time time.Time `json:"time"`
+	// Type is one of the SecurityEvent* constants, for example `token_reuse_detected`.
+	Type string `json:"type"`
+	// Severity is one of `low`, `medium` and `high`.
+	Severity string `json:"severity"`
+	// ClientID is the OAuth 2.0 Client involved in the event, if any.
+	ClientID string `json:"client_id,omitempty"`
+	// Subject is the user involved in the event, if any.
+	Subject string `json:"subject,omitempty"`
+	// IPAddress is the IP address of the caller.
+	IPAddress string `json:"ip_address,omitempty"`
+	// UserAgent is the user agent of the caller.
+	UserAgent string `json:"user_agent,omitempty"`
+	// RequestID is the value of the X-Request-Id header of the request.
+	RequestID string `json:"request_id,omitempty"`
+	// TraceID is the ID of the trace of the request, if it is traced.
+	TraceID string `json:"trace_id,omitempty"`
+	// Reason is a human readable description of the event.
+	Reason string `json:"reason,omitempty"`
+	// Details contains additional, event specific information.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// SecurityEventSink writes security events to a destination such as a file or a SIEM.
+type SecurityEventSink interface {
+	WriteSecurityEvent(ctx context.Context, event []byte) error
+}
+
+// securityEventBuffer is the number of events which may be queued before new events are dropped.
+const securityEventBuffer = 1024
+
+// SecurityEvents emits security events to the configured sinks. Events are written in the
+// background so that slow sinks do not delay requests.
+type SecurityEvents struct {
+	l      *logrusx.Logger
+	sinks  []SecurityEventSink
+	events chan []byte
+	once   sync.Once
+}
+
+// NewSecurityEvents returns an emitter which writes to the given sinks. Without sinks, events are
+// discarded.
+func NewSecurityEvents(l *logrusx.Logger, sinks ...SecurityEventSink) *SecurityEvents {
+	return &SecurityEvents{l: l, sinks: sinks, events: make(chan []byte, securityEventBuffer)}
+}
+
+// Emit completes the event with the details of the request, which may be nil, and queues it for
+// the sinks.
+func (s *SecurityEvents) Emit(r *http.Request, e SecurityEvent) {
+	if len(s.sinks) == 0 {
+		return
+	}
+	s.once.Do(func() { go s.run() })
+
+	e.SchemaVersion = SecurityEventSchemaVersion
+	e.ID = uuid.Must(uuid.NewV4()).String()
+	e.Time = time.Now().UTC()
+	if r != nil {
+		e.IPAddress = httpx.ClientIP(r)
+		e.UserAgent = r.UserAgent()
+		e.RequestID = r.Header.Get("X-Request-Id")
+		if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+			e.TraceID = sc.TraceID().String()
+		}
+	}
+
+	event, err := json.Marshal(e)
+	if err != nil {
+		s.l.WithError(err).Error("Unable to encode the security event.")
+		return
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.l.WithField("security_event", e.Type).Error("The security event queue is full, dropping the event.")
+	}
+}
+
+func (s *SecurityEvents) run() {
+	for event := range s.events {
+		for _, sink := range s.sinks {
+			if err := sink.WriteSecurityEvent(context.Background(), event); err != nil {
+				s.l.WithError(err).Errorf("Unable to write the security event to sink %T.", sink)
+			}
+		}
+	}
+}
+
+// FileSecurityEventSink appends events to a file, one per line.
+type FileSecurityEventSink struct {
+	sync.Mutex
+	f *os.File
+}
+
+func NewFileSecurityEventSink(path string) (*FileSecurityEventSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSecurityEventSink{f: f}, nil
+}
+
+func (s *FileSecurityEventSink) WriteSecurityEvent(_ context.Context, event []byte) error {
+	s.Lock()
+	defer s.Unlock()
+	_, err := s.f.Write(append(event, '\n'))
+	return err
+}
+
+// HTTPSecurityEventSink posts each event as JSON to an HTTP endpoint, such as the HTTP event
+// collector of a SIEM or a Kafka REST proxy.
+type HTTPSecurityEventSink struct {
+	c       *retryablehttp.Client
+	url     string
+	headers map[string]string
+}
+
+func NewHTTPSecurityEventSink(c *retryablehttp.Client, url string, headers map[string]string) *HTTPSecurityEventSink {
+	return &HTTPSecurityEventSink{c: c, url: url, headers: headers}
+}
+
+func (s *HTTPSecurityEventSink) WriteSecurityEvent(ctx context.Context, event []byte) error {
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(event))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	for k, v := range s.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("expected a 2xx status code but got: %s", resp.Status)
+	}
+	return nil
+}
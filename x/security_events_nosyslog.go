@@ -0,0 +1,24 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows || plan9
+// +build windows plan9
+
+package x
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// SyslogSecurityEventSink is not available on this platform.
+type SyslogSecurityEventSink struct{}
+
+func NewSyslogSecurityEventSink(string, string, string) (*SyslogSecurityEventSink, error) {
+	return nil, errors.New("the syslog security event sink is not supported on this platform")
+}
+
+func (s *SyslogSecurityEventSink) WriteSecurityEvent(context.Context, []byte) error {
+	return errors.New("the syslog security event sink is not supported on this platform")
+}
@@ -0,0 +1,31 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package x
+
+import (
+	"context"
+	"log/syslog"
+)
+
+// SyslogSecurityEventSink writes events to syslog with the facility auth and severity warning.
+type SyslogSecurityEventSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSecurityEventSink connects to the syslog daemon at address using network, for example
+// udp. If network is empty, the local syslog daemon is used.
+func NewSyslogSecurityEventSink(network, address, tag string) (*SyslogSecurityEventSink, error) {
+	w, err := syslog.Dial(network, address, syslog.LOG_AUTH|syslog.LOG_WARNING, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSecurityEventSink{w: w}, nil
+}
+
+func (s *SyslogSecurityEventSink) WriteSecurityEvent(_ context.Context, event []byte) error {
+	return s.w.Warning(string(event))
+}
@@ -0,0 +1,73 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/logrusx"
+)
+
+func TestSecurityEvents(t *testing.T) {
+	received := make(chan []byte, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		received <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(ts.Close)
+
+	path := filepath.Join(t.TempDir(), "security.log")
+	fileSink, err := NewFileSecurityEventSink(path)
+	require.NoError(t, err)
+	httpSink := NewHTTPSecurityEventSink(retryablehttp.NewClient(), ts.URL, map[string]string{"Authorization": "secret"})
+
+	r := httptest.NewRequest("POST", "/oauth2/token", nil)
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("X-Request-Id", "request-1")
+
+	NewSecurityEvents(logrusx.New("", ""), fileSink, httpSink).Emit(r, SecurityEvent{
+		Type:     SecurityEventClientAuthenticationFailed,
+		Severity: SecuritySeverityMedium,
+		ClientID: "client-1",
+	})
+
+	var event SecurityEvent
+	select {
+	case body := <-received:
+		require.NoError(t, json.Unmarshal(body, &event))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the HTTP sink did not receive the event")
+	}
+
+	assert.Equal(t, SecurityEventSchemaVersion, event.SchemaVersion)
+	assert.NotEmpty(t, event.ID)
+	assert.False(t, event.Time.IsZero())
+	assert.Equal(t, SecurityEventClientAuthenticationFailed, event.Type)
+	assert.Equal(t, "client-1", event.ClientID)
+	assert.Equal(t, "test-agent", event.UserAgent)
+	assert.Equal(t, "request-1", event.RequestID)
+	assert.NotEmpty(t, event.IPAddress)
+
+	// The file sink is written before the HTTP sink.
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"type":"client_authentication_failed"`)
+}
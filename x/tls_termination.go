@@ -45,6 +45,50 @@ func MatchesRange(r *http.Request, ranges []string) error {
 	return errors.Errorf("neither remote address nor any x-forwarded-for values match CIDR ranges %v: %v, ranges, check)", ranges, check)
 }
 
+// TrustedClientIP returns the IP address of the client which sent the request. The X-Forwarded-For header
+// is only taken into account if the request was sent by one of the trusted proxy ranges, in which case the
+// right-most address which is not a trusted proxy is returned.
+func TrustedClientIP(r *http.Request, trustedProxies []string) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	var cidrs []*net.IPNet
+	for _, rn := range trustedProxies {
+		if _, cidr, err := net.ParseCIDR(rn); err == nil {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+
+	trusted := func(ip string) bool {
+		addr := net.ParseIP(ip)
+		for _, cidr := range cidrs {
+			if cidr.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !trusted(remoteIP) {
+		return remoteIP
+	}
+
+	forwarded := stringsx.Splitx(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !trusted(ip) {
+			return ip
+		}
+		remoteIP = ip
+	}
+	return remoteIP
+}
+
 type tlsRegistry interface {
 	RegistryLogger
 	RegistryWriter
@@ -5,6 +5,7 @@ package x_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -233,3 +234,28 @@ func TestDoesRequestSatisfyTermination(t *testing.T) {
 		assert.EqualValues(t, http.StatusBadGateway, res.Code)
 	})
 }
+
+func TestTrustedClientIP(t *testing.T) {
+	trusted := []string{"10.0.0.0/8"}
+	for k, tc := range []struct {
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{remoteAddr: "192.0.2.1:123", expected: "192.0.2.1"},
+		{remoteAddr: "192.0.2.1:123", forwarded: "198.51.100.1", expected: "192.0.2.1"},
+		{remoteAddr: "10.0.0.1:123", expected: "10.0.0.1"},
+		{remoteAddr: "10.0.0.1:123", forwarded: "198.51.100.1", expected: "198.51.100.1"},
+		{remoteAddr: "10.0.0.1:123", forwarded: "203.0.113.1, 198.51.100.1, 10.0.0.2", expected: "198.51.100.1"},
+		{remoteAddr: "10.0.0.1:123", forwarded: "10.0.0.3, 10.0.0.2", expected: "10.0.0.3"},
+		{remoteAddr: "10.0.0.1:123", forwarded: "not-an-ip", expected: "10.0.0.1"},
+	} {
+		t.Run(fmt.Sprintf("case=%d", k), func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tc.remoteAddr, Header: http.Header{}}
+			if tc.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwarded)
+			}
+			assert.Equal(t, tc.expected, TrustedClientIP(r, trusted))
+		})
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader is the response header containing the ID of the trace of the request.
+const TraceIDHeader = "X-Trace-Id"
+
+// TraceIDMiddleware adds the ID of the trace of the request to the response, so that an error
+// response can be correlated with its trace. Requests which are not traced are left untouched.
+func TraceIDMiddleware(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if sc := trace.SpanContextFromContext(r.Context()); sc.HasTraceID() {
+		rw.Header().Set(TraceIDHeader, sc.TraceID().String())
+	}
+	next(rw, r)
+}
@@ -0,0 +1,101 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/ory/x/logrusx"
+)
+
+// Watchdog logs a dump of all goroutines when the number of goroutines or the latency of a request
+// crosses its threshold. At most one dump is logged per cooldown to keep the log volume bounded.
+type Watchdog struct {
+	l                  *logrusx.Logger
+	goroutineThreshold int
+	latencyThreshold   time.Duration
+	cooldown           time.Duration
+
+	sync.Mutex
+	lastDump time.Time
+}
+
+// NewWatchdog returns a watchdog. A threshold of zero disables the respective check.
+func NewWatchdog(l *logrusx.Logger, goroutineThreshold int, latencyThreshold, cooldown time.Duration) *Watchdog {
+	return &Watchdog{
+		l:                  l,
+		goroutineThreshold: goroutineThreshold,
+		latencyThreshold:   latencyThreshold,
+		cooldown:           cooldown,
+	}
+}
+
+// Watch checks the number of goroutines every interval until the context is done.
+func (w *Watchdog) Watch(ctx context.Context, interval time.Duration) {
+	if w.goroutineThreshold <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := runtime.NumGoroutine(); n > w.goroutineThreshold {
+				w.dump("The number of goroutines exceeds the watchdog threshold.", logrus.Fields{
+					"goroutines":          n,
+					"goroutine_threshold": w.goroutineThreshold,
+				})
+			}
+		}
+	}
+}
+
+// Middleware dumps the goroutines while a request takes longer than the latency threshold, so that
+// the dump shows where the request is stuck.
+func (w *Watchdog) Middleware(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if w.latencyThreshold <= 0 {
+		next(rw, r)
+		return
+	}
+
+	timer := time.AfterFunc(w.latencyThreshold, func() {
+		w.dump("A request exceeds the watchdog latency threshold.", logrus.Fields{
+			"http_request_method": r.Method,
+			"http_request_path":   r.URL.Path,
+			"latency_threshold":   w.latencyThreshold.String(),
+		})
+	})
+	defer timer.Stop()
+
+	next(rw, r)
+}
+
+func (w *Watchdog) dump(message string, fields logrus.Fields) {
+	w.Lock()
+	if !w.lastDump.IsZero() && time.Since(w.lastDump) < w.cooldown {
+		w.Unlock()
+		return
+	}
+	w.lastDump = time.Now()
+	w.Unlock()
+
+	var b bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&b, 1); err != nil {
+		w.l.WithError(err).Error("Unable to dump the goroutines.")
+		return
+	}
+
+	w.l.WithFields(fields).WithField("goroutine_dump", b.String()).Warn(message)
+}
@@ -0,0 +1,61 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ory/x/logrusx"
+)
+
+func TestWatchdog(t *testing.T) {
+	newWatchdog := func() (*test.Hook, *Watchdog) {
+		hook := &test.Hook{}
+		l := logrusx.New("", "", logrusx.WithHook(hook))
+		l.Logrus().SetOutput(io.Discard)
+		return hook, NewWatchdog(l, 1, 10*time.Millisecond, time.Hour)
+	}
+
+	t.Run("case=dumps goroutines of slow requests once per cooldown", func(t *testing.T) {
+		hook, w := newWatchdog()
+		slow := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { time.Sleep(50 * time.Millisecond) })
+
+		for i := 0; i < 2; i++ {
+			w.Middleware(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/oauth2/token", nil), slow)
+		}
+
+		require.Len(t, hook.AllEntries(), 1)
+		e := hook.LastEntry()
+		assert.Equal(t, logrus.WarnLevel, e.Level)
+		assert.Equal(t, "/oauth2/token", e.Data["http_request_path"])
+		assert.Contains(t, e.Data["goroutine_dump"], "goroutine profile:")
+	})
+
+	t.Run("case=ignores fast requests", func(t *testing.T) {
+		hook, w := newWatchdog()
+		w.Middleware(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), func(http.ResponseWriter, *http.Request) {})
+		time.Sleep(20 * time.Millisecond)
+		assert.Empty(t, hook.AllEntries())
+	})
+
+	t.Run("case=dumps goroutines above the threshold", func(t *testing.T) {
+		hook, w := newWatchdog()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go w.Watch(ctx, time.Millisecond)
+		assert.Eventually(t, func() bool { return len(hook.AllEntries()) == 1 }, time.Second, time.Millisecond)
+		assert.Equal(t, 1, hook.LastEntry().Data["goroutine_threshold"])
+	})
+}
@@ -0,0 +1,28 @@
+// Copyright © 2022 Ory Corp
+// SPDX-License-Identifier: Apache-2.0
+
+package x
+
+import (
+	"context"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartWebhookSpan starts a span for a call to a webhook. Only the host of the target is recorded,
+// because the URL may contain credentials.
+func StartWebhookSpan(ctx context.Context, hook string, target string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{attribute.String("webhook.name", hook)}
+	if u, err := url.Parse(target); err == nil {
+		attrs = append(attrs, semconv.NetPeerNameKey.String(u.Hostname()))
+	}
+
+	return otel.GetTracerProvider().Tracer(tracingComponent).Start(ctx, "x.webhook."+hook,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}